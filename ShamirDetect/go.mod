@@ -1,3 +0,0 @@
-module cs.au.dk/voting
-
-go 1.17
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"encoding/gob"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/spdz"
+	"cs.au.dk/voting/tally"
+	"cs.au.dk/voting/tallyserver"
+	"cs.au.dk/voting/voteclient"
+)
+
+func main() {
+
+	// Inform gob of magic type :D
+	gob.Register(protocol.Request{})
+
+	// Our own IP (the default of the server IPs)
+	ip := protocol.GetSelfIP()
+
+	var mode, name, partnerPort, partnerIP, portlist, clientIPs, links, partition, offline, restarts, crashes, outage, scenarioFile, clientmode, blameFile, readmit, schemeName, strategyName, keyDir, voterIDs, authorityFile, authorityPort, election, receiptFile, receiptKeyDir string
+	var id, servercount, testcase, vote, voteperiod, phasetimeout, p, k, seed, badmode, badbehaviour, voters, clientmodearg, linger int
+	var waitForResults, mainServer, badvariant, verbose, macs, proofs, anonymous, detach bool
+
+	flag.StringVar(&mode, "mode", "server", "Specify mode to run with.")
+	flag.IntVar(&servercount, "servers", 0, "Specify the amount of servers of the additive and elgamal schemes (0 = their default, the other schemes have a fixed amount).")
+	flag.StringVar(&strategyName, "tally", "", fmt.Sprintf("Specify how the R-sums are tallied %v (default is the strategy of the scheme).", tally.Names))
+	flag.StringVar(&schemeName, "scheme", scheme.DEFAULT, fmt.Sprintf("Specify the secret sharing scheme of the election %v (every server and client must use the same).", scheme.Names))
+	flag.StringVar(&keyDir, "keys", "", "Specify the folder of the MAC keys from the dealer, checking the tally with SPDZ MACs (server, client and deal mode, additive scheme only).")
+	flag.StringVar(&voterIDs, "voterids", "", "Specify the IDs of the voters to deal masks for, or the eligible voters, seperated by commas (deal and authority mode).")
+	flag.StringVar(&authorityFile, "authority", "", "Specify the public key file of the registration authority, voters then join the servers with a credential from it instead of their ID (server, client and authority mode, where it is written).")
+	flag.StringVar(&authorityPort, "aport", "", "Specify the port of the registration authority, at the first server IP (client mode, requires -authority).")
+	flag.BoolVar(&proofs, "proof", false, "Specify if clients prove their ballot encrypts 0 or 1, and servers refuse ballots without a proof (elgamal scheme only).")
+	flag.BoolVar(&anonymous, "anonymous", false, "Specify if voters join with a credential from a registration authority, started in-process (sim mode).")
+	flag.BoolVar(&detach, "detach", false, "Specify if voters hang up once they have their receipts, and fetch the results from the servers later (sim mode).")
+	flag.BoolVar(&macs, "mac", false, "Specify if the tally is checked with SPDZ MACs, dealt in-process (sim mode, additive scheme only).")
+	flag.StringVar(&name, "name", "Turing", "Specify the ID of the instance.")
+	flag.StringVar(&election, "election", protocol.DEFAULT_ELECTION, "Specify the ID of the election, which the receipts of the voters are made for (server, client and result mode).")
+	flag.StringVar(&receiptFile, "receipts", "", "Specify a file to keep the receipts of the voter in (client mode), to check them against the tallied sets when fetching the results (result mode).")
+	flag.StringVar(&receiptKeyDir, "receiptkeys", "", "Specify the folder of the keys the servers sign receipts with, published before the election (server mode loads its own, client and result mode pin them, receiptkeys mode writes them).")
+	flag.IntVar(&linger, "linger", 0, "Specify how long the server keeps answering queries for the results after the tally in seconds (server mode).")
+	flag.StringVar(&partnerIP, "pip", ip, "Specify the IP address of the partner server IP address. Default is localhost.")
+	flag.StringVar(&portlist, "port", "11000", "Specify which port to connect to (or listen on if server). Clients need a port per server of the scheme, seperated by commas.")
+	flag.StringVar(&partnerPort, "pport", "11001", "Specify which port the connect and listen to as a server.")
+	flag.StringVar(&clientIPs, "ip", ip, "Specify which IP to use for servers (seperate with commas, only one address can be specified).")
+	flag.IntVar(&id, "id", -1, "Specify the ID of the instance.")
+	flag.IntVar(&testcase, "i", -1, "Specify specific test to run. Value <= 0 will run all tests")
+	flag.IntVar(&vote, "v", rand.Intn(1-0)+0, "Specify how the client will vote (0/1). Default is false/no (0).")
+	flag.IntVar(&voteperiod, "t", 15, "Specify how long the voting period is in seconds.")
+	flag.IntVar(&phasetimeout, "pt", int(tallyserver.PHASE_TIMEOUT/time.Second), "Specify how long partners get to join and answer in each phase in seconds, before going ahead without them.")
+	flag.IntVar(&p, "p", 1997, "Specify the prime number to generate secret.")
+	flag.IntVar(&k, "k", 1, "Specify the amount of dishonest servers we are preparing for.")
+	flag.IntVar(&seed, "s", time.Now().Nanosecond(), "Specify the pseudo-random generator seed (shares are always drawn from crypto/rand, except in test and sim mode).")
+	flag.IntVar(&badmode, "b", -1, "Specify if server Should behave badly (ignore protocol, crash, etc.).")
+	flag.IntVar(&badbehaviour, "bb", -1, "Specify how the bad server should behave (ignored if -b not set).")
+	flag.StringVar(&clientmode, "cb", voteclient.CLIENT_MODE_HONEST, fmt.Sprintf("Specify how a bad client should behave %v.", voteclient.ClientModeNames))
+	flag.IntVar(&clientmodearg, "cbb", 0, "Specify the argument of the bad client behaviour (0 = default, ignored if -cb not set).")
+	flag.BoolVar(&waitForResults, "w", true, "Specify if client should *NOT* wait for results before terminating server connection (it hangs up once it has its receipts, fetch the results later with -mode result).")
+	flag.BoolVar(&mainServer, "m", false, "Deprecated and ignored, the servers no longer need a main server.")
+	flag.StringVar(&links, "links", "", "Specify simulated link faults, e.g. \"*>*:delay=1ms-20ms;S1>S2:drop=0.1,dup=0.1,reorder=0.1\" (sim mode).")
+	flag.StringVar(&partition, "partition", "", "Specify a simulated network partition, e.g. \"S1,S2|S3,S4\" (sim mode).")
+	flag.StringVar(&offline, "offline", "", "Specify ServerIDs of servers that are never started, e.g. \"3\" (sim mode).")
+	flag.StringVar(&restarts, "restart", "", "Specify ServerIDs of servers that crash and restart before the votes are cast, e.g. \"1\" (sim mode).")
+	flag.StringVar(&crashes, "crash", "", "Specify ServerIDs of servers that crash once the votes are cast, e.g. \"1\" (sim mode).")
+	flag.StringVar(&outage, "outage", "", "Specify a simulated partition lasting a while once the votes are cast, e.g. \"S3|S1,S2\" (sim mode).")
+	flag.IntVar(&voters, "voters", 8, "Specify the amount of simulated voters (sim mode).")
+	flag.BoolVar(&verbose, "verbose", false, "Specify if every injected network fault should be logged (sim mode).")
+	flag.StringVar(&scenarioFile, "scenario", "", "Specify a scenario file with byzantine server behaviours (server and sim mode).")
+	flag.StringVar(&blameFile, "blames", "", "Specify a file to keep blame reports in, servers blamed in it are refused as partners (server mode).")
+	flag.StringVar(&readmit, "readmit", "", "Specify blamed servers to re-admit as partners, seperated by commas (server mode, requires -blames).")
+	flag.Parse()
+
+	// Init rand
+	rand.Seed(int64(seed))
+
+	// Get the sharing scheme, and how it is tallied
+	sch, err := scheme.ByName(schemeName)
+	if err == nil {
+		sch, err = scheme.Sized(sch, servercount)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	strategy, err := scheme.StrategyOf(sch, strategyName)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// MACs are on additive shares only
+	if (keyDir != "" || macs || mode == "deal") && !scheme.SupportsMACs(sch) {
+		fmt.Printf("The %s scheme cannot be MAC checked, use -scheme additive.\n", sch.Name())
+		return
+	}
+
+	// MAC masks are dealt by voter ID, which the servers never learn from a credential
+	if (authorityFile != "" || anonymous) && (keyDir != "" || macs) {
+		fmt.Println("Credentials cannot be used with MAC checked tallies, as the masks are dealt by voter ID.")
+		return
+	}
+
+	// Only encrypted ballots can be proven
+	if proofs && !scheme.Encrypts(sch) {
+		fmt.Printf("The ballots of the %s scheme are not proven (only encrypted ballots are), use -scheme elgamal.\n", sch.Name())
+		return
+	}
+
+	// Old scripts may still start a main server
+	if mainServer {
+		fmt.Println("The -m flag is deprecated and ignored, the servers close the voting without a main server.")
+	}
+
+	// Load scenario (if any)
+	var scenario *Scenario
+	if scenarioFile != "" {
+		var err error
+		if scenario, err = LoadScenario(scenarioFile); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	switch mode {
+	case "server":
+		// Update variability points if 0 <= badmode <= 1
+		behaviours := make([]tallyserver.Behaviour, 0)
+		if badmode == tallyserver.BEHAVIOUR_MODE_WRONG_R_VALUE {
+			behaviours = append(behaviours, &tallyserver.WrongRSumBehaviour{Mode: badbehaviour})
+		} else if badmode == tallyserver.BEHAVIOUR_MODE_CLIENT_INTERSET {
+			behaviours = append(behaviours, &tallyserver.BadIntersectionBehaviour{})
+		}
+		// Add behaviours from scenario
+		if scenario != nil {
+			scenarioBehaviours, err := scenario.BehavioursOf(id)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			behaviours = append(behaviours, scenarioBehaviours...)
+		}
+		// Load blame reports of earlier elections (if kept), and re-admit servers the operator trusts again
+		var blames *tallyserver.BlameStore
+		if blameFile != "" {
+			var err error
+			if blames, err = tallyserver.LoadBlameStore(blameFile); err != nil {
+				fmt.Println(err)
+				return
+			}
+			for _, readmitted := range strings.Split(readmit, ",") {
+				if readmitted == "" {
+					continue
+				}
+				cleared, err := blames.Readmit(readmitted)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Printf("[%s] Re-admitted %s (cleared %v blame report(s)).\n", name, readmitted, cleared)
+			}
+		}
+		// Load our MAC key from the dealer (if the tally is MAC checked)
+		var key *spdz.ServerKey
+		if keyDir != "" {
+			var err error
+			if key, err = spdz.LoadServerKey(keyDir, id); err != nil {
+				fmt.Println(err)
+				return
+			}
+			if key.P != p {
+				fmt.Printf("The MAC key was dealt for P = %v, not %v.\n", key.P, p)
+				return
+			}
+		}
+		// Load the key of the registration authority (if voters join with credentials)
+		var authority *credential.PublicKey
+		if authorityFile != "" {
+			var err error
+			if authority, err = credential.LoadPublicKey(authorityFile); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		// Load the key we sign receipts with (if published)
+		var receiptKey ed25519.PrivateKey
+		if receiptKeyDir != "" {
+			var err error
+			if receiptKey, err = protocol.LoadReceiptKey(receiptKeyDir, id); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		// Exit with the code of the failure (if any)
+		code := protocol.ERR_NONE
+		defer ExitOnFailure(&code)
+		server := CreateNewServer(id, name, election, ip, portlist, strings.Split(partnerPort, ","), strings.Split(partnerIP, ","), voteperiod, phasetimeout, p, k, sch, strategy, key, authority, receiptKey, blames, proofs, behaviours...)
+		code = server.WaitForResults().Code
+		// Keep answering voters that hung up after voting, and ask for the results now
+		if linger > 0 {
+			fmt.Printf("[%s] Answering queries for the results for %vs.\n", name, linger)
+			time.Sleep(time.Duration(linger) * time.Second)
+		}
+	case "client":
+		if vote < 0 || vote > 1 {
+			fmt.Println("Invalid vote. Must be an integer value of 0 or 1.")
+			return
+		}
+		if p <= 3 { // A protocol for secure addition, page 13
+			fmt.Println("Invalid P-value. Must be greater than 3 (and prime).")
+			return
+		}
+		if !voteclient.IsClientMode(clientmode) {
+			fmt.Printf("Invalid client behaviour '%s'. Must be one of %v.\n", clientmode, voteclient.ClientModeNames)
+			return
+		}
+		// Load our mask from the dealer (if the tally is MAC checked)
+		var mask *spdz.VoterKey
+		if keyDir != "" {
+			var err error
+			if mask, err = spdz.LoadVoterKey(keyDir, name); err != nil {
+				fmt.Println(err)
+				return
+			}
+			if mask.P != p {
+				fmt.Printf("The mask was dealt for P = %v, not %v.\n", mask.P, p)
+				return
+			}
+		}
+		// Load the key of the registration authority (if we join with a credential)
+		var authority *credential.PublicKey
+		if authorityFile != "" {
+			var err error
+			if authority, err = credential.LoadPublicKey(authorityFile); err != nil {
+				fmt.Println(err)
+				return
+			}
+			if authorityPort == "" {
+				fmt.Println("Getting a credential needs the port of the registration authority (-aport).")
+				return
+			}
+		}
+		// Pin the keys the servers sign receipts with (if published)
+		receiptKeys, err := loadReceiptKeys(receiptKeyDir, sch.Servers())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		// Exit with the code of the failure (if any)
+		code := protocol.ERR_NONE
+		defer ExitOnFailure(&code)
+		client, ok := CreateNewClient(name, election, clientIPs, portlist, p, k, sch, strategy, mask, authority, authorityPort, proofs, badvariant)
+		client.ReceiptKeys = receiptKeys
+		if ok {
+			client.Mode = clientmode
+			client.ModeArg = clientmodearg
+			client.SendVote(vote)
+			client.Shutdown(waitForResults)
+			// Keep our receipts (to check them when fetching the results)
+			if receiptFile != "" {
+				if err := client.SaveReceipts(receiptFile); err != nil {
+					fmt.Println(err)
+				}
+			}
+		}
+		code = client.Code
+	case "result":
+		// Fetch the results from every server (after voting with -w=false), checking the receipts we kept (if any)
+		client := voteclient.New(name, strings.Split(clientIPs, ","), strings.Split(portlist, ","), p, k)
+		client.Scheme = sch
+		client.Strategy = strategy
+		client.Election = election
+		if client.ReceiptKeys, err = loadReceiptKeys(receiptKeyDir, sch.Servers()); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if receiptFile != "" {
+			if client.Receipts, err = voteclient.LoadReceipts(receiptFile); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		code := protocol.ERR_NONE
+		defer ExitOnFailure(&code)
+		client.FetchResults()
+		code = client.Code
+	case "deal":
+		// Act as the trusted dealer of the MAC keys (before the election)
+		if keyDir == "" || voterIDs == "" {
+			fmt.Println("Dealing needs a folder to write the keys to (-keys) and the IDs of the voters (-voterids).")
+			return
+		}
+		keys, masks := spdz.Deal(sharing.Secure, sch.Servers(), p, strings.Split(voterIDs, ","))
+		if err := spdz.Save(keyDir, keys, masks); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Dealt MAC keys for %v server(s) and %v voter(s) to %s.\n", len(keys), len(masks), keyDir)
+	case "receiptkeys":
+		// Draw the keys the servers sign receipts with, to publish before the election
+		if receiptKeyDir == "" {
+			fmt.Println("Drawing the receipt keys needs a folder to write them to (-receiptkeys).")
+			return
+		}
+		keys, err := protocol.GenerateReceiptKeys(crand.Reader, sch.Servers())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := protocol.SaveReceiptKeys(receiptKeyDir, keys); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Drew receipt keys for %v server(s) to %s.\n", len(keys), receiptKeyDir)
+	case "authority":
+		// Act as the registration authority (until the voting period is over)
+		if authorityFile == "" || voterIDs == "" {
+			fmt.Println("The authority needs a file to write its public key to (-authority) and the IDs of the eligible voters (-voterids).")
+			return
+		}
+		key, err := credential.GenerateKey(nil, credential.KEY_BITS)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := key.PublicKey.Save(authorityFile); err != nil {
+			fmt.Println(err)
+			return
+		}
+		authority := credential.NewAuthority(name, key, strings.Split(voterIDs, ","))
+		if err := authority.Start(ip, portlist); err != nil {
+			fmt.Println(err)
+			return
+		}
+		time.Sleep(time.Duration(voteperiod) * time.Second)
+		authority.Stop()
+		fmt.Printf("[%s] Issued %v credential(s).\n", name, authority.Issued())
+	case "test":
+		DispatchTestCall(testcase)
+	case "bench":
+		RunBenchmarks(p)
+	case "sim":
+		election := SimElection{Seed: int64(seed), Voters: voters, VoteTime: voteperiod, P: p, K: k, Scheme: sch, Strategy: strategy, MAC: macs, Proofs: proofs, Anonymous: anonymous, Detach: detach, Links: links, Partition: partition, Outage: outage, Verbose: verbose}
+		if election.Offline, err = ParseServerIDs(offline, sch.Servers()); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if election.Restarts, err = ParseServerIDs(restarts, sch.Servers()); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if election.Crashes, err = ParseServerIDs(crashes, sch.Servers()); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if scenario != nil {
+			if election, err = scenario.Apply(election); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		RunSimulation(election)
+	}
+
+}
+
+// Load the public keys the servers sign receipts with from the folder (none if no folder is given)
+func loadReceiptKeys(dir string, servers int) ([]ed25519.PublicKey, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	return protocol.LoadReceiptPublicKeys(dir, servers)
+}
+
+func CreateNewClient(id, election, serverIP, serverPort string, P, K int, sch scheme.Scheme, strategy tally.Strategy, mask *spdz.VoterKey, authority *credential.PublicKey, authorityPort string, prove, bad bool) (*voteclient.Client, bool) {
+
+	// Create client (returned even if it failed, as it knows why)
+	client := new(voteclient.Client)
+	client.Scheme = sch
+	client.Strategy = strategy
+	client.Mask = mask
+	client.Prove = prove
+	client.Entry = rand.Intn(len(strings.Split(serverPort, ",")))
+	client.Election = election
+
+	// Get our credential first (if the voters are registered by an authority)
+	if authority != nil && !client.Register(id, strings.Split(serverIP, ",")[0], authorityPort, authority, bad) {
+		return client, false
+	}
+	err := client.Init(id, strings.Split(serverIP, ","), strings.Split(serverPort, ","), P, K, bad)
+	return client, err == nil
+
+}
+
+func CreateNewServer(id int, name, election, selfIP, listenPort string, parnterPort []string, partnerIP []string, waitTime, phaseTimeout, prime, k int, sch scheme.Scheme, strategy tally.Strategy, key *spdz.ServerKey, authority *credential.PublicKey, receiptKey ed25519.PrivateKey, blames *tallyserver.BlameStore, proofs bool, behaviours ...tallyserver.Behaviour) *tallyserver.Server {
+
+	// Create and start server (failing to listen is fatal)
+	server := tallyserver.New(
+		tallyserver.WithID(id, name),
+		tallyserver.WithElection(election),
+		tallyserver.WithListen(selfIP, listenPort),
+		tallyserver.WithPartners(partnerIP, parnterPort),
+		tallyserver.WithVoteTime(waitTime),
+		tallyserver.WithPhaseTimeout(time.Duration(phaseTimeout)*time.Second),
+		tallyserver.WithPrime(prime),
+		tallyserver.WithScheme(sch),
+		tallyserver.WithStrategy(strategy),
+		tallyserver.WithDegree(k),
+		tallyserver.WithMACKey(key),
+		tallyserver.WithAuthority(authority),
+		tallyserver.WithReceiptKey(receiptKey),
+		tallyserver.WithBlames(blames),
+		tallyserver.WithProofs(proofs),
+		tallyserver.WithBehaviours(behaviours...),
+	)
+	if err := server.Start(); err != nil {
+		panic(err)
+	}
+	return server
+
+}
+
+// Exit with the exit code of a failure (recovering a panic with a VoteError)
+func ExitOnFailure(code *protocol.ErrorCode) {
+	if e := recover(); e != nil {
+		if err, ok := e.(*protocol.VoteError); ok {
+			fmt.Printf("\033[31mFailed: %v\033[0m\n", err)
+			os.Exit(err.Code.ExitCode())
+		}
+		panic(e)
+	}
+	os.Exit(code.ExitCode())
+}
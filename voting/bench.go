@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"cs.au.dk/voting/sharing"
+)
+
+// Amounts of servers to benchmark the reconstruction with
+var BENCH_SERVERS = []int{3, 4, 16, 64}
+
+// Amount of secrets opened at once in the batched benchmark (e.g. the questions of a ballot)
+const BENCH_SECRETS = 1000
+
+// Benchmark the reconstruction of shared secrets in Z_p, interpolating the polynomium every time against
+// combining the shares with precomputed Lagrange weights
+func RunBenchmarks(p int) {
+
+	fmt.Printf("Benchmarking reconstruction in Z_%v (ns per operation)\n", p)
+	fmt.Printf("%-8s %-34s %14s %14s %9s\n", "Servers", "Operation", "Interpolate", "Weights", "Speedup")
+
+	for _, n := range BENCH_SERVERS {
+		if n >= p {
+			fmt.Printf("%-8v skipped, needs a prime above %v\n", n, n)
+			continue
+		}
+
+		// Shares of BENCH_SECRETS secrets among the servers 1..n (degree n-1, so every share is needed)
+		rng := sharing.Deterministic(int64(n))
+		xs := make([]int, n)
+		for i := range xs {
+			xs[i] = i + 1
+		}
+		shares := make([][]int, BENCH_SECRETS)
+		for s := range shares {
+			shares[s] = sharing.Secrify(rng, rng.Intn(p), p, n-1, n)
+		}
+		points := make([]sharing.Point, n)
+		for i := range points {
+			points[i] = sharing.Point{X: xs[i], Y: shares[0][i]}
+		}
+
+		// Opening one secret, computing the weights for it
+		interpolate := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				f, _ := sharing.Interpolate(p, points)
+				f.Eval(0)
+			}
+		})
+		weights := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				w, _ := sharing.NewWeights(p, 0, xs)
+				w.Combine(shares[0])
+			}
+		})
+		printBenchmark(n, "open one secret", interpolate, weights)
+
+		// Opening one secret with the weights of the servers computed already
+		cached := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sharing.Lagrange(0, p, points)
+			}
+		})
+		printBenchmark(n, "open one secret (cached weights)", interpolate, cached)
+
+		// Opening all the secrets against the same servers
+		interpolateAll := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, ys := range shares {
+					for j := range points {
+						points[j].Y = ys[j]
+					}
+					f, _ := sharing.Interpolate(p, points)
+					f.Eval(0)
+				}
+			}
+		})
+		weightsAll := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				w, _ := sharing.NewWeights(p, 0, xs)
+				w.CombineAll(shares)
+			}
+		})
+		printBenchmark(n, fmt.Sprintf("open %v secrets", BENCH_SECRETS), interpolateAll, weightsAll)
+
+		// Inverting the denominators one by one against all at once
+		dens := make([]int, n)
+		for i := range dens {
+			dens[i] = 1 + rng.Intn(p-1)
+		}
+		oneByOne := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, d := range dens {
+					sharing.Inverse(d, p)
+				}
+			}
+		})
+		batched := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sharing.BatchInverse(dens, p)
+			}
+		})
+		printBenchmark(n, fmt.Sprintf("invert %v values (batched)", n), oneByOne, batched)
+	}
+
+}
+
+// Print a line of the benchmark table
+func printBenchmark(n int, op string, before, after testing.BenchmarkResult) {
+	speedup := float64(before.NsPerOp()) / float64(after.NsPerOp())
+	fmt.Printf("%-8v %-34s %14v %14v %8.1fx\n", n, op, before.NsPerOp(), after.NsPerOp(), speedup)
+}
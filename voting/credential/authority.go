@@ -0,0 +1,170 @@
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// The registration authority, issuing one credential to every eligible voter (before the election)
+type Authority struct {
+	ID        string             // Name of the authority (in the logs)
+	Key       *PrivateKey        // The key the credentials are signed with
+	Transport protocol.Transport // How voters reach us (protocol.DefaultTransport if nil)
+
+	mutex    sync.Mutex
+	eligible map[string]bool // IDs of the voters that may get a credential
+	issued   map[string]bool // IDs of the voters that got one
+	listener protocol.Listener
+}
+
+// Create the authority of the eligible voters
+func NewAuthority(id string, key *PrivateKey, voters []string) *Authority {
+	authority := &Authority{ID: id, Key: key, eligible: map[string]bool{}, issued: map[string]bool{}}
+	for _, voter := range voters {
+		authority.eligible[voter] = true
+	}
+	return authority
+}
+
+// Sign the blinded hash of an eligible voter that was not issued a credential yet
+func (authority *Authority) Issue(voter string, blinded *big.Int) (*big.Int, error) {
+
+	authority.mutex.Lock()
+	defer authority.mutex.Unlock()
+
+	// Only once for every eligible voter
+	if !authority.eligible[voter] {
+		return nil, protocol.Errorf(protocol.ERR_NOT_ELIGIBLE, "%s is not on the list of eligible voters", voter)
+	}
+	if authority.issued[voter] {
+		return nil, protocol.Errorf(protocol.ERR_NOT_ELIGIBLE, "%s was already issued a credential", voter)
+	}
+	signed, err := authority.Key.Sign(blinded)
+	if err != nil {
+		return nil, protocol.Errorf(protocol.ERR_NOT_ELIGIBLE, "%v", err)
+	}
+	authority.issued[voter] = true
+	return signed, nil
+
+}
+
+// Amount of credentials issued
+func (authority *Authority) Issued() int {
+	authority.mutex.Lock()
+	defer authority.mutex.Unlock()
+	return len(authority.issued)
+}
+
+// Listen for voters on the address
+func (authority *Authority) Start(ip, port string) error {
+	if authority.Transport == nil {
+		authority.Transport = protocol.DefaultTransport
+	}
+	ln, err := authority.Transport.Listen(ip, port)
+	if err != nil {
+		return err
+	}
+	authority.listener = ln
+	fmt.Printf("[%s] Issuing credentials to %v voter(s) on %s.\n", authority.ID, len(authority.eligible), ln.Addr())
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go authority.handle(conn)
+		}
+	}()
+	return nil
+}
+
+// Stop listening for voters
+func (authority *Authority) Stop() {
+	if authority.listener != nil {
+		authority.listener.Close()
+	}
+}
+
+// Answer the requests of a voter until it hangs up
+func (authority *Authority) handle(conn protocol.Conn) {
+	defer conn.Close()
+	for {
+		req, err := conn.Receive()
+		if err != nil {
+			return
+		}
+		if req.RequestType != protocol.CREDENTIAL {
+			continue
+		}
+
+		// Sign (or tell the voter why not)
+		msg := req.ToCredentialMsg()
+		blinded, ok := new(big.Int).SetString(msg.Value, 16)
+		if !ok {
+			blinded = new(big.Int)
+		}
+		signed, err := authority.Issue(msg.Voter, blinded)
+		if err != nil {
+			fmt.Printf("[%s] \033[31mRefusing credential: %v\033[0m\n", authority.ID, err)
+			reject := protocol.RejectMessage{Code: protocol.ERR_NOT_ELIGIBLE, Reason: err.Error()}
+			var voteErr *protocol.VoteError
+			if errors.As(err, &voteErr) {
+				reject = protocol.RejectMessage{Code: voteErr.Code, Reason: voteErr.Detail}
+			}
+			conn.Send(reject.ToRequest())
+			continue
+		}
+		fmt.Printf("[%s] Issued a credential to %s.\n", authority.ID, msg.Voter)
+		conn.Send(protocol.CredentialMessage{Value: signed.Text(16)}.ToRequest())
+	}
+}
+
+// Get a credential from the authority at the address, as the voter with the given ID
+func Obtain(transport protocol.Transport, ip, port, voter string, pub *PublicKey, random io.Reader) (*Token, error) {
+
+	// Blind a new serial
+	serial, err := NewSerial(random)
+	if err != nil {
+		return nil, err
+	}
+	blinded, b, err := pub.Blind(random, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ask the authority to sign it
+	conn, err := transport.Dial(ip, port)
+	if err != nil {
+		return nil, protocol.Errorf(protocol.ERR_SERVER_UNREACHABLE, "the authority at %s:%s is offline: %v", ip, port, err)
+	}
+	defer conn.Close()
+	if err := conn.Send(protocol.CredentialMessage{Voter: voter, Value: blinded.Text(16)}.ToRequest()); err != nil {
+		return nil, protocol.Errorf(protocol.ERR_SERVER_UNREACHABLE, "could not reach the authority: %v", err)
+	}
+	req, err := conn.Receive()
+	if err != nil {
+		return nil, protocol.Errorf(protocol.ERR_SERVER_UNREACHABLE, "lost the authority: %v", err)
+	}
+	switch req.RequestType {
+	case protocol.REJECT:
+		reject := req.ToRejectMsg()
+		return nil, protocol.Errorf(reject.Code, "%s", reject.Reason)
+	case protocol.CREDENTIAL:
+		signed, ok := new(big.Int).SetString(req.ToCredentialMsg().Value, 16)
+		if !ok {
+			return nil, protocol.Errorf(protocol.ERR_NOT_ELIGIBLE, "the authority sent an invalid signature")
+		}
+		token, err := pub.Unblind(serial, signed, b)
+		if err != nil {
+			return nil, protocol.Errorf(protocol.ERR_NOT_ELIGIBLE, "%v", err)
+		}
+		return token, nil
+	}
+	return nil, protocol.Errorf(protocol.ERR_NOT_ELIGIBLE, "the authority sent an invalid response")
+
+}
@@ -0,0 +1,204 @@
+package credential
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+)
+
+// Anonymous voting credentials from RSA blind signatures. A voter draws a random serial s, and sends the registration
+// authority the blinded hash H(s) * b^e mod N (b random), so the authority learns nothing about s. The authority
+// checks the voter is eligible and has not been issued a credential yet, and signs the blinded hash, returning
+// (H(s) * b^e)^d = H(s)^d * b. The voter divides out b, and is left with the signature H(s)^d of its serial, which the
+// authority has never seen. The serial and its signature (the token) are shown to the tally servers instead of the
+// voter ID, and the hash of the token is the ID the servers know the voter by. A voter only gets one token, so it
+// can only register once, while no server (nor the authority) can link the token to the voter.
+
+// Size of the modulus of the authority key in bits
+const KEY_BITS = 2048
+
+// Public exponent of the authority key
+var E = big.NewInt(65537)
+
+// Size of the serial of a token in bytes
+const SERIAL_BYTES = 32
+
+// The public key of the registration authority (what the servers and voters are given)
+type PublicKey struct {
+	N *big.Int `json:"n"`
+	E *big.Int `json:"e"`
+}
+
+// The key of the registration authority
+type PrivateKey struct {
+	PublicKey
+	D *big.Int `json:"d"`
+}
+
+// A credential: a serial and the signature of the authority on its hash
+type Token struct {
+	Serial    []byte
+	Signature *big.Int
+}
+
+// Generate the key of the authority with the randomness (crypto/rand if nil)
+func GenerateKey(random io.Reader, bits int) (*PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	one := big.NewInt(1)
+	for {
+
+		// Pick two primes, such that e is invertible modulo phi(N)
+		p, err := rand.Prime(random, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(random, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(E, phi)
+		if d == nil {
+			continue
+		}
+		return &PrivateKey{PublicKey: PublicKey{N: new(big.Int).Mul(p, q), E: new(big.Int).Set(E)}, D: d}, nil
+
+	}
+}
+
+// Draw a new serial with the randomness (crypto/rand if nil)
+func NewSerial(random io.Reader) ([]byte, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	serial := make([]byte, SERIAL_BYTES)
+	if _, err := io.ReadFull(random, serial); err != nil {
+		return nil, err
+	}
+	return serial, nil
+}
+
+// Hash the serial onto Z_N (a full domain hash, counting through SHA-256 until there are 128 bits more than N has)
+func (pub *PublicKey) hash(serial []byte) *big.Int {
+	size := (pub.N.BitLen()+7)/8 + 16
+	digest := make([]byte, 0, size+sha256.Size)
+	for i := 0; len(digest) < size; i++ {
+		h := sha256.New()
+		h.Write([]byte{byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)})
+		h.Write(serial)
+		digest = h.Sum(digest)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(digest[:size]), pub.N)
+}
+
+// Blind the hash of the serial, returning what is sent to the authority and the blinding factor (to unblind with)
+func (pub *PublicKey) Blind(random io.Reader, serial []byte) (*big.Int, *big.Int, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+
+	// Pick a blinding factor invertible modulo N
+	var b *big.Int
+	for b == nil || b.Sign() == 0 || new(big.Int).GCD(nil, nil, b, pub.N).Cmp(big.NewInt(1)) != 0 {
+		var err error
+		if b, err = rand.Int(random, pub.N); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// H(s) * b^e
+	blinded := new(big.Int).Exp(b, pub.E, pub.N)
+	blinded.Mul(blinded, pub.hash(serial)).Mod(blinded, pub.N)
+	return blinded, b, nil
+}
+
+// Sign a blinded hash (the authority, after checking the voter is eligible)
+func (key *PrivateKey) Sign(blinded *big.Int) (*big.Int, error) {
+	if blinded.Sign() <= 0 || blinded.Cmp(key.N) >= 0 {
+		return nil, errors.New("the blinded hash is outside Z_N")
+	}
+	return new(big.Int).Exp(blinded, key.D, key.N), nil
+}
+
+// Divide the blinding factor out of the signature of the authority, leaving the token of the serial
+func (pub *PublicKey) Unblind(serial []byte, signed, b *big.Int) (*Token, error) {
+	inverse := new(big.Int).ModInverse(b, pub.N)
+	if inverse == nil {
+		return nil, errors.New("the blinding factor is not invertible")
+	}
+	token := &Token{Serial: serial, Signature: inverse.Mul(inverse, signed).Mod(inverse, pub.N)}
+	if !pub.Verify(token) {
+		return nil, errors.New("the authority did not sign the blinded hash")
+	}
+	return token, nil
+}
+
+// Check the token was signed by the authority
+func (pub *PublicKey) Verify(token *Token) bool {
+	if token == nil || len(token.Serial) != SERIAL_BYTES || token.Signature == nil || token.Signature.Sign() <= 0 || token.Signature.Cmp(pub.N) >= 0 {
+		return false
+	}
+	return new(big.Int).Exp(token.Signature, pub.E, pub.N).Cmp(pub.hash(token.Serial)) == 0
+}
+
+// The ID the servers know the voter by (the hash of the token, as the serial is only signed once)
+func (token *Token) Key() string {
+	h := sha256.New()
+	h.Write(token.Serial)
+	h.Write(token.Signature.Bytes())
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// Encode the token (to send it to the servers)
+func (token *Token) Encode() []string {
+	return []string{hex.EncodeToString(token.Serial), token.Signature.Text(16)}
+}
+
+// Decode a token (not checking it was signed)
+func DecodeToken(strs []string) (*Token, error) {
+	if len(strs) != 2 {
+		return nil, fmt.Errorf("a token has 2 values, got %v", len(strs))
+	}
+	serial, err := hex.DecodeString(strs[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid serial '%s'", strs[0])
+	}
+	signature, ok := new(big.Int).SetString(strs[1], 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid signature '%s'", strs[1])
+	}
+	return &Token{Serial: serial, Signature: signature}, nil
+}
+
+// Write the public key to a file (for the servers and voters)
+func (pub *PublicKey) Save(path string) error {
+	data, e := json.MarshalIndent(pub, "", "    ")
+	if e != nil {
+		return e
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load the public key of the authority from a file
+func LoadPublicKey(path string) (*PublicKey, error) {
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return nil, e
+	}
+	pub := new(PublicKey)
+	if e := json.Unmarshal(data, pub); e != nil || pub.N == nil || pub.E == nil {
+		return nil, fmt.Errorf("invalid authority key file '%s': %v", path, e)
+	}
+	return pub, nil
+}
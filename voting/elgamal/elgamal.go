@@ -0,0 +1,276 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// Threshold exponential ElGamal. The servers generate a key together (joint Feldman DKG): every server deals a random
+// polynomium f_i of degree t, sending f_i(j) to server j and publishing the commitments g^(coefficients) so the shares
+// can be checked. The secret key x = f_1(0) + ... + f_n(0) is never known to anyone, server j only knows its share
+// x_j = f_1(j) + ... + f_n(j), while the public key is h = g^x = prod_i g^(f_i(0)).
+// A vote m is encrypted as (g^r, g^m * h^r), so multiplying ciphertexts adds up the votes. Any t+1 servers decrypt
+// the sum together by publishing A^(x_j), which are combined with Lagrange weights in the exponent into A^x, leaving
+// g^(sum), whose (small) discrete logarithm is the sum of the votes.
+
+// Safe prime P = 2Q+1 (RFC 3526, 2048-bit MODP group 14). We work in the subgroup of quadratic residues (prime order Q).
+var P, _ = new(big.Int).SetString(
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1"+
+		"29024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245"+
+		"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D"+
+		"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F"+
+		"83655D23DCA3AD961C62F356208552BB9ED529077096966D"+
+		"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+		"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9"+
+		"DE2BCBF6955817183995497CEA956AE515D2261898FA0510"+
+		"15728E5A8AACAA68FFFFFFFFFFFFFFFF", 16)
+
+// Order of the subgroup, Q = (P-1)/2
+var Q = new(big.Int).Rsh(P, 1)
+
+// Generator of the subgroup (a square, so a quadratic residue)
+var G = big.NewInt(4)
+
+// Errors of the scheme
+var (
+	ErrNotInGroup     = errors.New("not an element of the group")
+	ErrTooFewPartials = errors.New("too few partial decryptions")
+	ErrNoDiscreteLog  = errors.New("the decryption is not a small enough sum")
+)
+
+// Encryption (A, B) = (g^r, g^m * h^r) of m under the public key h
+type Ciphertext struct {
+	A *big.Int
+	B *big.Int
+}
+
+// Draw a random exponent (in Z_Q) from the randomness
+func RandomExponent(random io.Reader) (*big.Int, error) {
+	return rand.Int(random, Q)
+}
+
+// Encrypt m under the public key h with the randomness
+func Encrypt(random io.Reader, h *big.Int, m int) (Ciphertext, error) {
+	r, err := RandomExponent(random)
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	return EncryptWith(h, m, r), nil
+}
+
+// Encrypt m under the public key h with the given exponent r (the voter needs r to prove what it encrypted)
+func EncryptWith(h *big.Int, m int, r *big.Int) Ciphertext {
+	gm := new(big.Int).Exp(G, big.NewInt(int64(m)), P)
+	hr := new(big.Int).Exp(h, r, P)
+	return Ciphertext{A: new(big.Int).Exp(G, r, P), B: gm.Mul(gm, hr).Mod(gm, P)}
+}
+
+// Encryption of 0 without randomness, the neutral element of Mul
+func Zero() Ciphertext {
+	return Ciphertext{A: big.NewInt(1), B: big.NewInt(1)}
+}
+
+// Multiply the ciphertexts, giving an encryption of the sum of their messages
+func (c Ciphertext) Mul(d Ciphertext) Ciphertext {
+	a := new(big.Int).Mul(c.A, d.A)
+	b := new(big.Int).Mul(c.B, d.B)
+	return Ciphertext{A: a.Mod(a, P), B: b.Mod(b, P)}
+}
+
+// Encode the ciphertext for sending
+func (c Ciphertext) Encode() []string {
+	return []string{Encode(c.A), Encode(c.B)}
+}
+
+// Decode a ciphertext, failing on anything outside the group
+func DecodeCiphertext(strs []string) (Ciphertext, error) {
+	if len(strs) != 2 {
+		return Ciphertext{}, fmt.Errorf("a ciphertext has 2 parts, not %v", len(strs))
+	}
+	a, err := Decode(strs[0])
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	b, err := Decode(strs[1])
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	return Ciphertext{A: a, B: b}, nil
+}
+
+// Encode a group element (or exponent) for sending
+func Encode(v *big.Int) string {
+	return v.Text(16)
+}
+
+// Decode a group element, failing on anything outside the subgroup of order Q
+func Decode(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok || !InGroup(v) {
+		return nil, fmt.Errorf("'%s': %w", s, ErrNotInGroup)
+	}
+	return v, nil
+}
+
+// Decode an exponent, failing on anything outside Z_Q
+func DecodeExponent(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok || v.Sign() < 0 || v.Cmp(Q) >= 0 {
+		return nil, fmt.Errorf("'%s' is not an exponent", s)
+	}
+	return v, nil
+}
+
+// Check the value is in the subgroup of order Q (0 < v < P and a quadratic residue, which for a safe prime is the
+// same as v^Q = 1 but much faster to check)
+func InGroup(v *big.Int) bool {
+	if v.Sign() <= 0 || v.Cmp(P) >= 0 {
+		return false
+	}
+	return big.Jacobi(v, P) == 1
+}
+
+// The polynomium a server deals in the key generation, and the commitments to its coefficients
+type Dealing struct {
+	coeffs      []*big.Int // f_i(x) = a_0 + a_1x + ... + a_tx^t (secret)
+	Commitments []*big.Int // g^(a_0), ..., g^(a_t) (published)
+}
+
+// Deal a random polynomium of degree t with the randomness
+func NewDealing(random io.Reader, t int) (*Dealing, error) {
+	d := &Dealing{coeffs: make([]*big.Int, t+1), Commitments: make([]*big.Int, t+1)}
+	for k := range d.coeffs {
+		a, err := RandomExponent(random)
+		if err != nil {
+			return nil, err
+		}
+		d.coeffs[k] = a
+		d.Commitments[k] = new(big.Int).Exp(G, a, P)
+	}
+	return d, nil
+}
+
+// The share f_i(j) of the server with the ServerID j
+func (d *Dealing) Share(serverID int) *big.Int {
+	x := big.NewInt(int64(serverID))
+	y := new(big.Int)
+	for k := len(d.coeffs) - 1; k >= 0; k-- {
+		y.Mul(y, x).Add(y, d.coeffs[k]).Mod(y, Q)
+	}
+	return y
+}
+
+// Check a share dealt to the server with the ServerID j against the commitments, g^share = prod_k C_k^(j^k)
+func VerifyShare(commitments []*big.Int, serverID int, share *big.Int) bool {
+	return new(big.Int).Exp(G, share, P).Cmp(commitmentAt(commitments, serverID)) == 0
+}
+
+// Evaluate the committed polynomium in the exponent at j, prod_k C_k^(j^k) = g^(f(j))
+func commitmentAt(commitments []*big.Int, serverID int) *big.Int {
+	x := big.NewInt(int64(serverID))
+	xk := big.NewInt(1)
+	y := big.NewInt(1)
+	for _, c := range commitments {
+		y.Mul(y, new(big.Int).Exp(c, xk, P)).Mod(y, P)
+		xk = new(big.Int).Mul(xk, x)
+	}
+	return y
+}
+
+// Our part of the key once every dealing was checked
+type KeyShare struct {
+	ServerID int
+	X        *big.Int // Our share of the secret key (the sum of the shares dealt to us)
+	H        *big.Int // The public key (the product of the committed constant terms)
+}
+
+// Combine the shares dealt to us and the commitments of every dealing (by ServerID of the dealer) into our key share
+func CombineShares(serverID int, shares map[int]*big.Int, commitments map[int][]*big.Int) (KeyShare, error) {
+	key := KeyShare{ServerID: serverID, X: new(big.Int), H: big.NewInt(1)}
+	for dealer, c := range commitments {
+		share, exists := shares[dealer]
+		if !exists {
+			return KeyShare{}, fmt.Errorf("no share from the dealing of server %v", dealer)
+		}
+		key.X.Add(key.X, share).Mod(key.X, Q)
+		key.H.Mul(key.H, c[0]).Mod(key.H, P)
+	}
+	return key, nil
+}
+
+// Our partial decryption of the ciphertext, A^(x_j)
+func (key KeyShare) PartialDecrypt(c Ciphertext) *big.Int {
+	return new(big.Int).Exp(c.A, key.X, P)
+}
+
+// Our partial decryption of the ciphertext, with a proof that it is A raised to the same x_j as our verification key
+func (key KeyShare) ProvePartial(random io.Reader, ctx Context, c Ciphertext) (*big.Int, EqualityProof, error) {
+	partial := key.PartialDecrypt(c)
+	proof, err := ProveEqualLogs(random, ctx, key.X, c.A)
+	return partial, proof, err
+}
+
+// The verification key g^(x_j) of the server with the ServerID j, from the commitments of every dealing (by
+// ServerID of the dealer). Anyone can compute it, as prod_i g^(f_i(j)).
+func VerificationKey(commitments map[int][]*big.Int, serverID int) *big.Int {
+	vk := big.NewInt(1)
+	for _, c := range commitments {
+		vk.Mul(vk, commitmentAt(c, serverID)).Mod(vk, P)
+	}
+	return vk
+}
+
+// Check the partial decryption of the ciphertext by the server with the verification key
+func VerifyPartial(ctx Context, vk *big.Int, c Ciphertext, partial *big.Int, proof EqualityProof) bool {
+	return VerifyEqualLogs(ctx, vk, c.A, partial, proof)
+}
+
+// Decrypt the ciphertext from the partial decryptions (by ServerID) of at least t+1 servers, giving g^m.
+// Every partial decryption given is used, combining A^x = prod_j (A^(x_j))^(w_j) with the Lagrange weights at 0.
+func Combine(c Ciphertext, partials map[int]*big.Int, t int) (*big.Int, error) {
+	if len(partials) < t+1 {
+		return nil, ErrTooFewPartials
+	}
+	ids := make([]int, 0, len(partials))
+	for id := range partials {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	// A^x = prod_j d_j^(w_j), with w_j = prod_{l != j} l / (l - j) mod Q
+	ax := big.NewInt(1)
+	for _, j := range ids {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for _, l := range ids {
+			if l == j {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(l))).Mod(num, Q)
+			den.Mul(den, big.NewInt(int64(l-j))).Mod(den, Q)
+		}
+		w := num.Mul(num, den.ModInverse(den, Q)).Mod(num, Q)
+		ax.Mul(ax, new(big.Int).Exp(partials[j], w, P)).Mod(ax, P)
+	}
+
+	// g^m = B / A^x
+	gm := new(big.Int).Mul(c.B, ax.ModInverse(ax, P))
+	return gm.Mod(gm, P), nil
+}
+
+// Find m in 0..max with g^m = gm (by trying them in turn, the sums of the votes are small)
+func DiscreteLog(gm *big.Int, max int) (int, error) {
+	y := big.NewInt(1)
+	for m := 0; m <= max; m++ {
+		if y.Cmp(gm) == 0 {
+			return m, nil
+		}
+		y.Mul(y, G).Mod(y, P)
+	}
+	return -1, ErrNoDiscreteLog
+}
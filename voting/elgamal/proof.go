@@ -0,0 +1,245 @@
+package elgamal
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// What a proof is made for, hashed into its challenge along with the kind of proof (so a proof only holds in the
+// election and under the public key it was made for, and never as another kind of proof)
+type Context struct {
+	Election string   // ID of the election
+	Key      *big.Int // The public key h of the election
+}
+
+// Labels of the kinds of proofs, hashed first into the challenge
+const (
+	EQUALITY_LABEL  = "elgamal-equality-proof"
+	SIGNATURE_LABEL = "elgamal-signature"
+	BIT_LABEL       = "elgamal-bit-proof"
+)
+
+// Proof that log_g(h) = log_a(d) without telling the logarithm (Chaum-Pedersen), made non-interactive by taking the
+// challenge from the hash of the statement and the commitments (Fiat-Shamir)
+type EqualityProof struct {
+	C *big.Int // Challenge
+	Z *big.Int // Response w + c*x
+}
+
+// Prove that g^x and a^x have the same logarithm x
+func ProveEqualLogs(random io.Reader, ctx Context, x, a *big.Int) (EqualityProof, error) {
+	w, err := RandomExponent(random)
+	if err != nil {
+		return EqualityProof{}, err
+	}
+	h := new(big.Int).Exp(G, x, P)
+	d := new(big.Int).Exp(a, x, P)
+	c := challenge(EQUALITY_LABEL, ctx, G, h, a, d, new(big.Int).Exp(G, w, P), new(big.Int).Exp(a, w, P))
+	z := new(big.Int).Mul(c, x)
+	return EqualityProof{C: c, Z: z.Add(z, w).Mod(z, Q)}, nil
+}
+
+// Check the proof that log_g(h) = log_a(d), recomputing the commitments g^z / h^c and a^z / d^c
+func VerifyEqualLogs(ctx Context, h, a, d *big.Int, proof EqualityProof) bool {
+	if !isExponent(proof.C) || !isExponent(proof.Z) {
+		return false
+	}
+	t1 := divExp(G, proof.Z, h, proof.C)
+	t2 := divExp(a, proof.Z, d, proof.C)
+	return challenge(EQUALITY_LABEL, ctx, G, h, a, d, t1, t2).Cmp(proof.C) == 0
+}
+
+// Encode the proof for sending
+func (proof EqualityProof) Encode() []string {
+	return []string{Encode(proof.C), Encode(proof.Z)}
+}
+
+// Decode a proof, failing on anything but two exponents
+func DecodeEqualityProof(strs []string) (EqualityProof, error) {
+	if len(strs) != 2 {
+		return EqualityProof{}, fmt.Errorf("a proof has 2 parts, not %v", len(strs))
+	}
+	c, err := DecodeExponent(strs[0])
+	if err != nil {
+		return EqualityProof{}, err
+	}
+	z, err := DecodeExponent(strs[1])
+	if err != nil {
+		return EqualityProof{}, err
+	}
+	return EqualityProof{C: c, Z: z}, nil
+}
+
+// Schnorr signature of values by the holder of a key share, which anyone can check against the verification key of
+// its server (so a server cannot deny what it signed). The challenge hashes the verification key and the values.
+type Signature struct {
+	C *big.Int // Challenge
+	Z *big.Int // Response w + c*x
+}
+
+// Sign the values with our key share
+func (key KeyShare) Sign(random io.Reader, ctx Context, values ...*big.Int) (Signature, error) {
+	w, err := RandomExponent(random)
+	if err != nil {
+		return Signature{}, err
+	}
+	vk := new(big.Int).Exp(G, key.X, P)
+	c := challenge(SIGNATURE_LABEL, ctx, append([]*big.Int{G, vk, new(big.Int).Exp(G, w, P)}, values...)...)
+	z := new(big.Int).Mul(c, key.X)
+	return Signature{C: c, Z: z.Add(z, w).Mod(z, Q)}, nil
+}
+
+// Check the signature of the values by the server with the verification key, recomputing the commitment g^z / vk^c
+func VerifySignature(ctx Context, vk *big.Int, sig Signature, values ...*big.Int) bool {
+	if !isExponent(sig.C) || !isExponent(sig.Z) {
+		return false
+	}
+	t := divExp(G, sig.Z, vk, sig.C)
+	return challenge(SIGNATURE_LABEL, ctx, append([]*big.Int{G, vk, t}, values...)...).Cmp(sig.C) == 0
+}
+
+// Encode the signature for sending
+func (sig Signature) Encode() []string {
+	return []string{Encode(sig.C), Encode(sig.Z)}
+}
+
+// Decode a signature, failing on anything but two exponents
+func DecodeSignature(strs []string) (Signature, error) {
+	proof, err := DecodeEqualityProof(strs)
+	if err != nil {
+		return Signature{}, err
+	}
+	return Signature{C: proof.C, Z: proof.Z}, nil
+}
+
+// b^e / v^c mod P
+func divExp(b, e, v, c *big.Int) *big.Int {
+	t := new(big.Int).Exp(v, c, P)
+	t.ModInverse(t, P)
+	return t.Mul(t, new(big.Int).Exp(b, e, P)).Mod(t, P)
+}
+
+// Hash the label of the kind of proof, its context and the values into a challenge in Z_Q (each prefixed by its
+// length, so no two inputs hash alike)
+func challenge(label string, ctx Context, values ...*big.Int) *big.Int {
+	hash := sha256.New()
+	write := func(b []byte) {
+		hash.Write([]byte{byte(len(b) >> 8), byte(len(b))})
+		hash.Write(b)
+	}
+	write([]byte(label))
+	write([]byte(ctx.Election))
+	if ctx.Key != nil {
+		write(ctx.Key.Bytes())
+	} else {
+		write(nil)
+	}
+	for _, v := range values {
+		write(v.Bytes())
+	}
+	c := new(big.Int).SetBytes(hash.Sum(nil))
+	return c.Mod(c, Q)
+}
+
+// Proof that a ciphertext (A, B) encrypts 0 or 1 without telling which (disjunctive Chaum-Pedersen): for both
+// j in {0, 1} it proves log_g(A) = log_h(B / g^j), where the branch of the other value is simulated with a made up
+// challenge, and the challenges of the branches must add up to the hash of the statement. The hash takes the ID of
+// the voter, so the proof cannot be copied along with the ballot to another voter.
+type BitProof struct {
+	C [2]*big.Int // Challenges of the branches
+	Z [2]*big.Int // Responses of the branches
+}
+
+// Prove that c = EncryptWith(h, v, r) encrypts the bit v under the public key h of the context (the voter knows v
+// and r)
+func ProveBit(random io.Reader, ctx Context, c Ciphertext, v int, r *big.Int, id string) (BitProof, error) {
+	h := ctx.Key
+	if v != 0 && v != 1 {
+		v = 1 // Nothing can be proven for another value, so the proof will not hold
+	}
+	proof := BitProof{}
+	var a, b [2]*big.Int
+
+	// Simulate the branch of the other value, with a made up challenge and response
+	o := 1 - v
+	var err error
+	if proof.C[o], err = RandomExponent(random); err != nil {
+		return BitProof{}, err
+	}
+	if proof.Z[o], err = RandomExponent(random); err != nil {
+		return BitProof{}, err
+	}
+	a[o] = divExp(G, proof.Z[o], c.A, proof.C[o])
+	b[o] = divExp(h, proof.Z[o], plainDiv(c.B, o), proof.C[o])
+
+	// Commit to the real branch
+	w, err := RandomExponent(random)
+	if err != nil {
+		return BitProof{}, err
+	}
+	a[v] = new(big.Int).Exp(G, w, P)
+	b[v] = new(big.Int).Exp(h, w, P)
+
+	// The challenge of the real branch is what is left of the hash
+	e := challenge(BIT_LABEL, ctx, bitStatement(h, c, id, a, b)...)
+	proof.C[v] = new(big.Int).Sub(e, proof.C[o])
+	proof.C[v].Mod(proof.C[v], Q)
+	proof.Z[v] = new(big.Int).Mul(proof.C[v], r)
+	proof.Z[v].Add(proof.Z[v], w).Mod(proof.Z[v], Q)
+	return proof, nil
+}
+
+// Check the proof that the ciphertext of the voter encrypts 0 or 1 under the public key h of the context
+func VerifyBit(ctx Context, c Ciphertext, proof BitProof, id string) bool {
+	h := ctx.Key
+	var a, b [2]*big.Int
+	for j := 0; j < 2; j++ {
+		if !isExponent(proof.C[j]) || !isExponent(proof.Z[j]) {
+			return false
+		}
+		a[j] = divExp(G, proof.Z[j], c.A, proof.C[j])
+		b[j] = divExp(h, proof.Z[j], plainDiv(c.B, j), proof.C[j])
+	}
+	sum := new(big.Int).Add(proof.C[0], proof.C[1])
+	return sum.Mod(sum, Q).Cmp(challenge(BIT_LABEL, ctx, bitStatement(h, c, id, a, b)...)) == 0
+}
+
+// Encode the proof for sending
+func (proof BitProof) Encode() []string {
+	return []string{Encode(proof.C[0]), Encode(proof.C[1]), Encode(proof.Z[0]), Encode(proof.Z[1])}
+}
+
+// Decode a proof, failing on anything but four exponents
+func DecodeBitProof(strs []string) (BitProof, error) {
+	if len(strs) != 4 {
+		return BitProof{}, fmt.Errorf("a proof of a bit has 4 parts, not %v", len(strs))
+	}
+	values := make([]*big.Int, 4)
+	for i, s := range strs {
+		v, err := DecodeExponent(s)
+		if err != nil {
+			return BitProof{}, err
+		}
+		values[i] = v
+	}
+	return BitProof{C: [2]*big.Int{values[0], values[1]}, Z: [2]*big.Int{values[2], values[3]}}, nil
+}
+
+// The statement of a proof of a bit, hashed into its challenge
+func bitStatement(h *big.Int, c Ciphertext, id string, a, b [2]*big.Int) []*big.Int {
+	return []*big.Int{G, h, c.A, c.B, new(big.Int).SetBytes([]byte(id)), a[0], b[0], a[1], b[1]}
+}
+
+// B / g^m, the rest of the ciphertext if it encrypts m
+func plainDiv(b *big.Int, m int) *big.Int {
+	gm := new(big.Int).Exp(G, big.NewInt(int64(m)), P)
+	gm.ModInverse(gm, P)
+	return gm.Mul(gm, b).Mod(gm, P)
+}
+
+// Check the value is an exponent (in Z_Q)
+func isExponent(v *big.Int) bool {
+	return v != nil && v.Sign() >= 0 && v.Cmp(Q) < 0
+}
@@ -0,0 +1,438 @@
+package protocol
+
+import "cs.au.dk/voting/sharing"
+
+// Enum values defining request types
+const (
+	NOTAFUCKINGREQUEST = iota
+	JOINNETWORK
+	SERVERJOIN
+	CLIENTJOIN
+	RNUMBER
+	ID
+	TALLY
+	CLIENTLIST
+	INTERSECTION
+	SERVERRESPONCE
+	ABORT
+	BLAME
+	HEARTBEAT
+	VOTEPERIOD
+	MACCOMMIT
+	MACOPEN
+	DKGDEAL
+	PUBLICKEY
+	BALLOT
+	AGGREGATE
+	PARTIALDECRYPT
+	REJECT
+	CREDENTIAL
+	RECEIPT
+	RESULT
+	PSIPROGRESS
+	AGGREGATEECHO
+)
+
+// Define actual request type
+type Request struct {
+	RequestType int
+	Val1        int
+	Val2        int
+	Val3        int
+	Strs        []string
+	Flag        bool
+	Points      []sharing.Point
+}
+
+func (r Request) ToRMsg() RMessage {
+	m := RMessage{Vote: r.Val1, Seq: r.Val2}
+	if len(r.Strs) > 0 {
+		m.Salt = r.Strs[0]
+	}
+	return m
+}
+
+func (r Request) ToIdMsg() IDMessage {
+	return IDMessage{ID: r.Val1}
+}
+
+func (r Request) ToTallyMsg() Results {
+	return Results{Yes: r.Val1, No: r.Val2, Error: r.Flag, Code: ErrorCode(r.Val3), Points: r.Points, Receipts: r.Strs}
+}
+
+func (r Request) ToStrinceSlice() StringSlice {
+	return StringSlice{Slice: r.Strs}
+}
+
+func (r Request) ToServerJoinMsg() ServerJoinIDMessage {
+	return ServerJoinIDMessage{ID: r.Strs[0], ServerID: uint8(r.Val1), VoteLeft: r.Val2}
+}
+
+func (r Request) ToABMsg() ABORTmessage {
+	return ABORTmessage{Message: r.Strs[0], ServerID: uint8(r.Val1), Code: ErrorCode(r.Val2)}
+}
+
+func (r Request) ToPSIMsg() PSIMessage {
+	return PSIMessage{Origin: uint8(r.Val1), Hops: r.Val2, Done: r.Flag, Servers: r.Val3, Values: r.Strs}
+}
+
+// R-Vote Message (Client -> Server)
+type RMessage struct {
+	Vote int
+	Seq  int    // Sequence number of the ballot (a recast ballot replaces the ones with lower numbers)
+	Salt string // Random salt of the commitment to the share in the receipt (so the receipt does not show the share)
+}
+
+// Converts the RMessage into a request
+func (m RMessage) ToRequest() Request {
+	return Request{RequestType: RNUMBER, Val1: m.Vote, Val2: m.Seq, Strs: []string{m.Salt}}
+}
+
+// Client join, with the key a recast over a new connection must show, and the credential of the voter (if any)
+type ClientJoinMessage struct {
+	ID         string   // ID of the voter (the hash of its credential, if it has one)
+	Recast     string   // Key the voter shows to rejoin the server (empty if it cannot rejoin)
+	Credential []string // The credential from the registration authority (nil if none)
+}
+
+// Converts the ClientJoinMessage into a request
+func (m ClientJoinMessage) ToRequest() Request {
+	return Request{RequestType: CLIENTJOIN, Strs: append([]string{m.ID, m.Recast}, m.Credential...)}
+}
+
+func (r Request) ToClientJoinMsg() ClientJoinMessage {
+	m := ClientJoinMessage{}
+	if len(r.Strs) > 0 {
+		m.ID = r.Strs[0]
+	}
+	if len(r.Strs) > 1 {
+		m.Recast = r.Strs[1]
+	}
+	if len(r.Strs) > 2 {
+		m.Credential = r.Strs[2:]
+	}
+	return m
+}
+
+// ID Message
+type IDMessage struct {
+	ID int
+}
+
+// Converts the RMessage into a request
+func (m IDMessage) ToRequest() Request {
+	return Request{RequestType: ID, Val1: m.ID}
+}
+
+// Server Join Message
+type ServerJoinIDMessage struct {
+	ID       string
+	ServerID uint8
+	VoteLeft int // Milliseconds left of the voting period of the sender (-1 if not started)
+}
+
+//Converts the ServerJoinIDMessage into a request
+func (sID ServerJoinIDMessage) ToRequest() Request {
+	return Request{RequestType: SERVERJOIN, Strs: []string{sID.ID}, Val1: int(sID.ServerID), Val2: sID.VoteLeft}
+}
+
+//Converts the ServerJoinIDMessage into a request
+func (sID ServerJoinIDMessage) ToResponse() Request {
+	return Request{RequestType: SERVERRESPONCE, Strs: []string{sID.ID}, Val1: int(sID.ServerID), Val2: sID.VoteLeft}
+}
+
+// Result message (Server -> Client)
+type Results struct {
+	Yes   int
+	No    int
+	Error bool
+	Code  ErrorCode // Why the tally failed (ERR_NONE if it did not)
+
+	// The published R-sum points (ServerID, R-sum) the tally was computed from, so clients can verify it
+	Points []sharing.Point
+
+	// Hashes of the receipts of the ballots the server tallied, so voters can check theirs were
+	Receipts []string
+}
+
+// Converts the RMessage into a request
+func (m Results) ToRequest() Request {
+	return Request{RequestType: TALLY, Val1: m.Yes, Val2: m.No, Val3: int(m.Code), Flag: m.Error, Points: m.Points, Strs: m.Receipts}
+}
+
+// Check if two results agree on the tally (ignoring the published points)
+func (m Results) SameTally(o Results) bool {
+	return m.Yes == o.Yes && m.No == o.No && m.Error == o.Error && m.Code == o.Code
+}
+
+type StringSlice struct {
+	Slice []string
+}
+
+// Converts the StringSlice into a request
+func (SS StringSlice) ToRequest() Request {
+	return Request{RequestType: CLIENTLIST, Strs: SS.Slice}
+}
+
+// Hash set of strings
+type StringHashSet map[string]interface{}
+
+// Convert a string slice into a hash set of strings
+func CheckmapFromStringSlice(input []string) StringHashSet {
+	result := StringHashSet{}
+	for _, v := range input {
+		result[v] = nil
+	}
+	return result
+}
+
+// ID Message
+type ABORTmessage struct {
+	ServerID uint8
+	Message  string
+	Code     ErrorCode
+}
+
+//Converts the ServerJoinIDMessage into a request
+func (ABm ABORTmessage) ToRequest() Request {
+	return Request{RequestType: ABORT, Strs: []string{ABm.Message}, Val1: int(ABm.ServerID), Val2: int(ABm.Code)}
+}
+
+// Blinded client list (Server -> Server, private set intersection)
+type PSIMessage struct {
+	Origin  uint8    // ServerID of the server the list belongs to
+	Hops    int      // Amount of partners who blinded the list
+	Done    bool     // True if blinded by every server
+	Servers int      // Bit mask of the ServerIDs taking part in the intersection
+	Values  []string // Blinded voter IDs
+}
+
+// Converts the PSIMessage into a request
+func (m PSIMessage) ToRequest() Request {
+	return Request{RequestType: INTERSECTION, Val1: int(m.Origin), Val2: m.Hops, Val3: m.Servers, Flag: m.Done, Strs: m.Values}
+}
+
+// Notice that a blinded client list was passed on along its route (Server -> Server, so a stalled list can be traced)
+type PSIProgressMessage struct {
+	Origin  uint8 // ServerID of the server the list belongs to
+	Hops    int   // Amount of partners who blinded the list, when sent to the next server on the route
+	Servers int   // Bit mask of the ServerIDs taking part in the intersection
+}
+
+// Converts the PSIProgressMessage into a request
+func (m PSIProgressMessage) ToRequest() Request {
+	return Request{RequestType: PSIPROGRESS, Val1: int(m.Origin), Val2: m.Hops, Val3: m.Servers}
+}
+
+func (r Request) ToPSIProgressMsg() PSIProgressMessage {
+	return PSIProgressMessage{Origin: uint8(r.Val1), Hops: r.Val2, Servers: r.Val3}
+}
+
+// Commitment to a share of the MAC check (Server -> Server)
+type MACCommitMessage struct {
+	ServerID   uint8
+	Commitment string
+}
+
+// Converts the MACCommitMessage into a request
+func (m MACCommitMessage) ToRequest() Request {
+	return Request{RequestType: MACCOMMIT, Val1: int(m.ServerID), Strs: []string{m.Commitment}}
+}
+
+func (r Request) ToMACCommitMsg() MACCommitMessage {
+	m := MACCommitMessage{ServerID: uint8(r.Val1)}
+	if len(r.Strs) == 1 {
+		m.Commitment = r.Strs[0]
+	}
+	return m
+}
+
+// Opening of the commitment to a share of the MAC check (Server -> Server)
+type MACOpenMessage struct {
+	ServerID uint8
+	Sigma    int
+	Nonce    string
+}
+
+// Converts the MACOpenMessage into a request
+func (m MACOpenMessage) ToRequest() Request {
+	return Request{RequestType: MACOPEN, Val1: int(m.ServerID), Val2: m.Sigma, Strs: []string{m.Nonce}}
+}
+
+func (r Request) ToMACOpenMsg() MACOpenMessage {
+	m := MACOpenMessage{ServerID: uint8(r.Val1), Sigma: r.Val2}
+	if len(r.Strs) == 1 {
+		m.Nonce = r.Strs[0]
+	}
+	return m
+}
+
+// Dealing of the distributed key generation, with the share of the receiver (Server -> Server)
+type DKGMessage struct {
+	ServerID    uint8
+	Commitments []string // Commitments to the coefficients of the polynomium of the dealer
+	Share       string   // The share of the receiver (the polynomium at its ServerID)
+}
+
+// Converts the DKGMessage into a request
+func (m DKGMessage) ToRequest() Request {
+	return Request{RequestType: DKGDEAL, Val1: int(m.ServerID), Strs: append(append([]string{}, m.Commitments...), m.Share)}
+}
+
+func (r Request) ToDKGMsg() DKGMessage {
+	m := DKGMessage{ServerID: uint8(r.Val1)}
+	if len(r.Strs) > 0 {
+		m.Commitments = r.Strs[:len(r.Strs)-1]
+		m.Share = r.Strs[len(r.Strs)-1]
+	}
+	return m
+}
+
+// Public key of the election to encrypt the ballot with (Server -> Client)
+type KeyMessage struct {
+	Key string
+}
+
+// Converts the KeyMessage into a request
+func (m KeyMessage) ToRequest() Request {
+	return Request{RequestType: PUBLICKEY, Strs: []string{m.Key}}
+}
+
+func (r Request) ToKeyMsg() KeyMessage {
+	m := KeyMessage{}
+	if len(r.Strs) == 1 {
+		m.Key = r.Strs[0]
+	}
+	return m
+}
+
+// Encrypted ballot, with the proof it encrypts 0 or 1 if the voter attached one (Client -> Server)
+type BallotMessage struct {
+	Ciphertext []string
+	Proof      []string
+	Seq        int // Sequence number of the ballot (a recast ballot replaces the ones with lower numbers)
+}
+
+// Converts the BallotMessage into a request
+func (m BallotMessage) ToRequest() Request {
+	return Request{RequestType: BALLOT, Val1: len(m.Ciphertext), Val2: m.Seq, Strs: append(append([]string{}, m.Ciphertext...), m.Proof...)}
+}
+
+func (r Request) ToBallotMsg() BallotMessage {
+	m := BallotMessage{Ciphertext: r.Strs, Seq: r.Val2}
+	if r.Val1 > 0 && r.Val1 <= len(r.Strs) {
+		m.Ciphertext = r.Strs[:r.Val1]
+		m.Proof = r.Strs[r.Val1:]
+	}
+	return m
+}
+
+// A ballot the server did not take, and why (Server -> Client)
+type RejectMessage struct {
+	Code   ErrorCode
+	Reason string
+}
+
+// Converts the RejectMessage into a request
+func (m RejectMessage) ToRequest() Request {
+	return Request{RequestType: REJECT, Val1: int(m.Code), Strs: []string{m.Reason}}
+}
+
+func (r Request) ToRejectMsg() RejectMessage {
+	m := RejectMessage{Code: ErrorCode(r.Val1)}
+	if len(r.Strs) == 1 {
+		m.Reason = r.Strs[0]
+	}
+	return m
+}
+
+// Product of the encrypted ballots of the voters counted at a server (Server -> Server)
+type AggregateMessage struct {
+	ServerID   uint8
+	Voters     int // Amount of ballots in the product
+	Ciphertext []string
+	Signature  []string // Signature of the server on the ServerID, the amount of ballots and the product
+}
+
+// Converts the AggregateMessage into a request
+func (m AggregateMessage) ToRequest() Request {
+	return Request{RequestType: AGGREGATE, Val1: int(m.ServerID), Val2: m.Voters, Strs: append(append([]string{}, m.Ciphertext...), m.Signature...)}
+}
+
+func (r Request) ToAggregateMsg() AggregateMessage {
+	m := AggregateMessage{ServerID: uint8(r.Val1), Voters: r.Val2, Ciphertext: r.Strs}
+	if len(r.Strs) > 2 {
+		m.Ciphertext, m.Signature = r.Strs[:2], r.Strs[2:]
+	}
+	return m
+}
+
+// The signed aggregates a server holds, echoed to its partners before decrypting, so a server that signed different
+// aggregates for different partners is caught (Server -> Server)
+type AggregateEchoMessage struct {
+	ServerID   uint8 // ServerID of the server echoing the aggregates
+	Done       bool  // True if these are all aggregates the server holds (false if it only passes on a conflict)
+	Aggregates []AggregateMessage
+}
+
+// Converts the AggregateEchoMessage into a request (the ServerID and amount of ballots of every aggregate as a point)
+func (m AggregateEchoMessage) ToRequest() Request {
+	r := Request{RequestType: AGGREGATEECHO, Val1: int(m.ServerID), Flag: m.Done, Points: []sharing.Point{}, Strs: []string{}}
+	for _, agg := range m.Aggregates {
+		r.Points = append(r.Points, sharing.Point{X: int(agg.ServerID), Y: agg.Voters})
+		r.Strs = append(r.Strs, agg.ToRequest().Strs...)
+	}
+	return r
+}
+
+// Converts the request into an AggregateEchoMessage (with no aggregates if malformed)
+func (r Request) ToAggregateEchoMsg() AggregateEchoMessage {
+	m := AggregateEchoMessage{ServerID: uint8(r.Val1), Done: r.Flag}
+	if len(r.Strs) != 4*len(r.Points) {
+		return m
+	}
+	for i, p := range r.Points {
+		m.Aggregates = append(m.Aggregates, AggregateMessage{ServerID: uint8(p.X), Voters: p.Y, Ciphertext: r.Strs[4*i : 4*i+2], Signature: r.Strs[4*i+2 : 4*i+4]})
+	}
+	return m
+}
+
+// Partial decryption of the product of all ballots, with the proof it was done with the key share (Server -> Server)
+type PartialMessage struct {
+	ServerID uint8
+	Partial  string
+	Proof    []string
+}
+
+// Converts the PartialMessage into a request
+func (m PartialMessage) ToRequest() Request {
+	return Request{RequestType: PARTIALDECRYPT, Val1: int(m.ServerID), Strs: append([]string{m.Partial}, m.Proof...)}
+}
+
+func (r Request) ToPartialMsg() PartialMessage {
+	m := PartialMessage{ServerID: uint8(r.Val1)}
+	if len(r.Strs) > 0 {
+		m.Partial = r.Strs[0]
+		m.Proof = r.Strs[1:]
+	}
+	return m
+}
+
+// Blinded hash of a serial from a voter, and the signature of the registration authority on it (Client <-> Authority)
+type CredentialMessage struct {
+	Voter string // ID of the voter (empty in the answer)
+	Value string // The blinded hash, or the signature on it
+}
+
+// Converts the CredentialMessage into a request
+func (m CredentialMessage) ToRequest() Request {
+	return Request{RequestType: CREDENTIAL, Strs: []string{m.Voter, m.Value}}
+}
+
+func (r Request) ToCredentialMsg() CredentialMessage {
+	m := CredentialMessage{}
+	if len(r.Strs) == 2 {
+		m.Voter, m.Value = r.Strs[0], r.Strs[1]
+	}
+	return m
+}
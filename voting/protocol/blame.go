@@ -0,0 +1,59 @@
+package protocol
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/sharing"
+)
+
+// Methods a server can be caught lying with
+const (
+	BLAME_OUTSIDE_FIELD  = "outside-field"  // The R-sum point is outside the field
+	BLAME_OFF_POLYNOMIUM = "off-polynomium" // The R-sum point is not on the polynomium of the other points (Berlekamp-Welch)
+	BLAME_MAC_CHECK      = "mac-check"      // The MAC check failed, and the server is the only partner who could have altered its R-sum
+	BLAME_MAC_COMMITMENT = "mac-commitment" // The share of the MAC check does not open the commitment of the server
+	BLAME_DKG_SHARE      = "dkg-share"      // The share the server dealt us in the key generation does not match its commitments
+	BLAME_DECRYPTION     = "decryption"     // The partial decryption of the server gives no tally with any other servers
+	BLAME_AGGREGATE      = "aggregate"      // The server signed two different aggregates of its ballots
+)
+
+// Report blaming a server for misbehaving (Server -> Server, Server -> Client)
+type BlameReport struct {
+	Blamed     uint8         `json:"blamed"`      // ServerID of the blamed server
+	BlamedName string        `json:"blamed_name"` // Name of the blamed server
+	BlamedAddr string        `json:"blamed_addr"` // Address the reporter has configured for the ServerID of the blamed server
+	Reporter   string        `json:"reporter"`    // Name of the server making the report
+	Point      sharing.Point `json:"point"`       // The point of the blamed server
+	Expected   int           `json:"expected"`    // The value interpolated from the other points at the X of the blamed server (-1 if none)
+	Method     string        `json:"method"`      // How the misbehaviour was detected
+	Time       string        `json:"time"`        // When the report was made
+	Readmitted bool          `json:"readmitted"`  // Set when an operator re-admits the blamed server
+}
+
+// Converts the BlameReport into a request
+func (b BlameReport) ToRequest() Request {
+	return Request{RequestType: BLAME, Val1: int(b.Blamed), Val2: b.Expected, Strs: []string{b.BlamedName, b.Reporter, b.Method, b.Time, b.BlamedAddr}, Points: []sharing.Point{b.Point}}
+}
+
+// Converts the request into a BlameReport
+func (r Request) ToBlameMsg() BlameReport {
+	b := BlameReport{Blamed: uint8(r.Val1), Expected: r.Val2}
+	if len(r.Strs) >= 4 {
+		b.BlamedName, b.Reporter, b.Method, b.Time = r.Strs[0], r.Strs[1], r.Strs[2], r.Strs[3]
+	}
+	if len(r.Strs) == 5 {
+		b.BlamedAddr = r.Strs[4]
+	}
+	if len(r.Points) == 1 {
+		b.Point = r.Points[0]
+	}
+	return b
+}
+
+// Describe the report
+func (b BlameReport) String() string {
+	if b.Expected < 0 {
+		return fmt.Sprintf("%s blames %s (ServerID %v, %s): point %v", b.Reporter, b.BlamedName, b.Blamed, b.Method, b.Point)
+	}
+	return fmt.Sprintf("%s blames %s (ServerID %v, %s): point %v, but expected %v", b.Reporter, b.BlamedName, b.Blamed, b.Method, b.Point, b.Expected)
+}
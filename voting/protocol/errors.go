@@ -0,0 +1,98 @@
+package protocol
+
+import "fmt"
+
+// Code of a failure (sent in results and abort messages, and used for exit codes)
+type ErrorCode int
+
+// Error codes (never reorder, the values travel over the wire)
+const (
+	ERR_NONE                 ErrorCode = iota
+	ERR_POINTS_OUTSIDE_FIELD           // Too many R-sum points outside the field
+	ERR_NOT_ON_POLYNOMIUM              // An R-sum point is not on the polynomium of the others
+	ERR_UNRECOVERABLE                  // More corrupt servers than the error correction can handle
+	ERR_CORRECTION_FAILED              // The error correction failed
+	ERR_VOTE_OUT_OF_RANGE              // More yes votes than voters (a voter voted outside {0, 1})
+	ERR_BAD_CLIENT_LIST                // A server sent a malformed client list
+	ERR_ABORTED                        // A partner aborted the vote
+	ERR_PARTNER_ID_TAKEN               // A partner claimed a ServerID already in use
+	ERR_PARTNER_REFUSED                // A partner was refused (blamed in an earlier election)
+	ERR_PARTNER_LOST                   // The connection to a partner failed
+	ERR_SERVER_CONFIG                  // Wrong amount of server addresses or ports
+	ERR_SERVER_UNREACHABLE             // A server could not be reached
+	ERR_SERVER_ROLES                   // The servers did not report the expected roles
+	ERR_NO_TALLY                       // No tally (or something else) was received
+	ERR_TALLY_DISAGREE                 // The servers reported tallies that do not agree
+	ERR_TALLY_UNVERIFIED               // The tally could not be verified from the published points
+	ERR_TOO_FEW_SERVERS                // Too few servers online (or answering in time) to tally
+	ERR_MAC_CHECK_FAILED               // The MACs of the opened sum did not check out (an R-sum was altered)
+	ERR_KEYGEN_FAILED                  // The servers could not generate the election key together
+	ERR_DECRYPTION_FAILED              // The partial decryptions of the servers do not give a tally
+	ERR_BALLOT_REJECTED                // The ballot of the voter was rejected (its proof did not hold)
+	ERR_NOT_ELIGIBLE                   // The voter was refused a credential, or the credential does not hold
+	ERR_TALLY_PENDING                  // A server asked for the results has not tallied yet
+	ERR_AGGREGATE_CONFLICT             // A server signed different aggregates of its ballots for different partners
+)
+
+// Readable reasons of the error codes
+var errorReasons = map[ErrorCode]string{
+	ERR_NONE:                 "no error",
+	ERR_POINTS_OUTSIDE_FIELD: "too many R-sum points outside the field",
+	ERR_NOT_ON_POLYNOMIUM:    "an R-sum point is not on the polynomium",
+	ERR_UNRECOVERABLE:        "more corrupt servers than can be corrected",
+	ERR_CORRECTION_FAILED:    "error correction failed",
+	ERR_VOTE_OUT_OF_RANGE:    "a voter voted outside {0, 1}",
+	ERR_BAD_CLIENT_LIST:      "a server sent a bad client list",
+	ERR_ABORTED:              "the vote was aborted",
+	ERR_PARTNER_ID_TAKEN:     "a partner claimed a ServerID in use",
+	ERR_PARTNER_REFUSED:      "a partner was refused",
+	ERR_PARTNER_LOST:         "lost connection to a partner",
+	ERR_SERVER_CONFIG:        "wrong amount of servers",
+	ERR_SERVER_UNREACHABLE:   "a server could not be reached",
+	ERR_SERVER_ROLES:         "the servers did not report the expected roles",
+	ERR_NO_TALLY:             "no tally was received",
+	ERR_TALLY_DISAGREE:       "the servers do not agree on the tally",
+	ERR_TALLY_UNVERIFIED:     "the tally could not be verified",
+	ERR_TOO_FEW_SERVERS:      "too few servers to tally",
+	ERR_MAC_CHECK_FAILED:     "the MAC check of the tally failed",
+	ERR_KEYGEN_FAILED:        "the key generation failed",
+	ERR_DECRYPTION_FAILED:    "the decryption of the tally failed",
+	ERR_BALLOT_REJECTED:      "the ballot was rejected",
+	ERR_NOT_ELIGIBLE:         "the voter is not eligible",
+	ERR_TALLY_PENDING:        "the tally is not done yet",
+	ERR_AGGREGATE_CONFLICT:   "a server signed two different aggregates",
+}
+
+// Exit codes are offset, so they do not clash with the exit codes of Go itself (1 and 2)
+const EXIT_CODE_BASE = 10
+
+// Get the readable reason of the code
+func (code ErrorCode) String() string {
+	if reason, exists := errorReasons[code]; exists {
+		return reason
+	}
+	return fmt.Sprintf("unknown error %d", int(code))
+}
+
+// Get the exit code for the code (0 if no error)
+func (code ErrorCode) ExitCode() int {
+	if code == ERR_NONE {
+		return 0
+	}
+	return EXIT_CODE_BASE + int(code)
+}
+
+// Error with a code and details
+type VoteError struct {
+	Code   ErrorCode
+	Detail string
+}
+
+// Create an error with a code
+func Errorf(code ErrorCode, format string, args ...interface{}) *VoteError {
+	return &VoteError{Code: code, Detail: fmt.Sprintf(format, args...)}
+}
+
+func (e *VoteError) Error() string {
+	return fmt.Sprintf("%v (%s)", e.Code, e.Detail)
+}
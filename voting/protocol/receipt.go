@@ -0,0 +1,150 @@
+package protocol
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Election the receipts are made for, unless another is given
+const DEFAULT_ELECTION = "election"
+
+// Receipt of a share (or encrypted ballot) a server took, signed by the server (Server -> Client). The voter keeps
+// its receipts, and checks they are in the tallied set the server publishes with the tally. A server that took a
+// share without giving a receipt, or left a receipted ballot out of the tally, is caught with the receipt. Every
+// server signs with a fixed key published before the election (see SaveReceiptKeys), which the voters pin.
+type Receipt struct {
+	ServerID   uint8  // The server that took the share
+	Seq        int    // Sequence number of the ballot
+	Election   string // ID of the election
+	Voter      string // ID of the voter (the hash of its credential, if it has one)
+	Commitment string // Commitment to the share (see CommitShare and CommitBallot)
+	Time       string // When the server took the share
+	Key        string // Public key of the server (ed25519, hex)
+	Signature  string // Signature of the server on the hash of the receipt (hex)
+}
+
+// Converts the Receipt into a request
+func (r Receipt) ToRequest() Request {
+	return Request{RequestType: RECEIPT, Val1: int(r.ServerID), Val2: r.Seq, Strs: []string{r.Election, r.Voter, r.Commitment, r.Time, r.Key, r.Signature}}
+}
+
+// Converts the request into a Receipt
+func (r Request) ToReceiptMsg() Receipt {
+	m := Receipt{ServerID: uint8(r.Val1), Seq: r.Val2}
+	if len(r.Strs) == 6 {
+		m.Election, m.Voter, m.Commitment, m.Time, m.Key, m.Signature = r.Strs[0], r.Strs[1], r.Strs[2], r.Strs[3], r.Strs[4], r.Strs[5]
+	}
+	return m
+}
+
+// Hash of the server and its key, the election, the voter, the commitment and the time (what the server signs, and
+// publishes once tallied)
+func (r Receipt) Hash() string {
+	h := sha256.New()
+	for _, field := range []string{strconv.Itoa(int(r.ServerID)), r.Key, r.Election, r.Voter, r.Commitment, r.Time} {
+		h.Write([]byte(strconv.Itoa(len(field))))
+		h.Write([]byte{':'})
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Sign the receipt with the key of the server
+func (r Receipt) Sign(key ed25519.PrivateKey) Receipt {
+	r.Key = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	r.Signature = hex.EncodeToString(ed25519.Sign(key, []byte(r.Hash())))
+	return r
+}
+
+// Check the receipt is signed with the key pinned for its server (a receipt naming any other key is forged)
+func (r Receipt) Verify(key ed25519.PublicKey) bool {
+	if len(key) != ed25519.PublicKeySize || r.Key != hex.EncodeToString(key) {
+		return false
+	}
+	signature, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, []byte(r.Hash()), signature)
+}
+
+// Draw the keys the servers sign receipts with (one per server, by ServerID - 1)
+func GenerateReceiptKeys(random io.Reader, servers int) ([]ed25519.PrivateKey, error) {
+	keys := make([]ed25519.PrivateKey, servers)
+	for i := range keys {
+		_, key, err := ed25519.GenerateKey(random)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// Write the receipt keys to a folder (S1.key, ... for the servers and S1.pub, ... for the voters to pin, in hex)
+func SaveReceiptKeys(dir string, keys []ed25519.PrivateKey) error {
+	if e := os.MkdirAll(dir, 0700); e != nil {
+		return e
+	}
+	for i, key := range keys {
+		if e := os.WriteFile(filepath.Join(dir, fmt.Sprintf("S%v.key", i+1)), []byte(hex.EncodeToString(key)), 0600); e != nil {
+			return e
+		}
+		if e := os.WriteFile(filepath.Join(dir, fmt.Sprintf("S%v.pub", i+1)), []byte(hex.EncodeToString(key.Public().(ed25519.PublicKey))), 0644); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// Load the receipt key of the server with the given ServerID from the folder
+func LoadReceiptKey(dir string, serverID int) (ed25519.PrivateKey, error) {
+	key, e := readHexKey(filepath.Join(dir, fmt.Sprintf("S%v.key", serverID)), ed25519.PrivateKeySize)
+	return ed25519.PrivateKey(key), e
+}
+
+// Load the public receipt keys of the servers from the folder (to pin them, by ServerID - 1)
+func LoadReceiptPublicKeys(dir string, servers int) ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, servers)
+	for i := range keys {
+		key, e := readHexKey(filepath.Join(dir, fmt.Sprintf("S%v.pub", i+1)), ed25519.PublicKeySize)
+		if e != nil {
+			return nil, e
+		}
+		keys[i] = ed25519.PublicKey(key)
+	}
+	return keys, nil
+}
+
+func readHexKey(path string, size int) ([]byte, error) {
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return nil, e
+	}
+	key, e := hex.DecodeString(strings.TrimSpace(string(data)))
+	if e != nil || len(key) != size {
+		return nil, fmt.Errorf("invalid receipt key file '%s'", path)
+	}
+	return key, nil
+}
+
+// Commitment to a share of a ballot (the share is salted, as a field element is easily guessed from its hash)
+func CommitShare(share, seq int, salt string) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.Itoa(share) + ":" + strconv.Itoa(seq) + ":" + salt))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Commitment to an encrypted ballot (the ciphertext already hides the vote)
+func CommitBallot(ciphertext []string, seq int) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(ciphertext, ":") + ":" + strconv.Itoa(seq)))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,549 @@
+package protocol
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Wildcard matching any party in a link rule
+const SIM_ANY = "*"
+
+// Fault rule for a (directed) link between two named parties
+type LinkRule struct {
+	MinDelay      time.Duration // Lower bound of delivery delay
+	MaxDelay      time.Duration // Upper bound of delivery delay
+	DropRate      float64       // Chance a message is lost
+	DuplicateRate float64       // Chance a message is delivered twice
+	ReorderRate   float64       // Chance a message is held back and overtaken by later messages
+	ReorderDelay  time.Duration // Extra delay of a held back message
+}
+
+// The fate of a single message as decided by the scheduler
+type Fate struct {
+	Dropped  bool
+	HeldBack bool            // The first copy may be overtaken by later messages
+	Delays   []time.Duration // One delay per delivered copy
+}
+
+// Seeded scheduler deciding the fate of every message.
+// Each directed link has its own generator derived from the seed and the link name,
+// so the n'th message on a link always gets the same fate for the same seed,
+// no matter how the goroutines of the parties are interleaved.
+type Scheduler struct {
+	Seed  int64
+	mutex sync.Mutex
+	links map[string]*rand.Rand
+}
+
+// Creates a new scheduler from seed
+func NewScheduler(seed int64) *Scheduler {
+	return &Scheduler{Seed: seed, links: map[string]*rand.Rand{}}
+}
+
+// Decide what happens to the next message sent from 'from' to 'to'
+func (s *Scheduler) Decide(from, to string, rule LinkRule) Fate {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Grab link generator
+	key := from + ">" + to
+	rng, exists := s.links[key]
+	if !exists {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		rng = rand.New(rand.NewSource(s.Seed ^ int64(h.Sum64())))
+		s.links[key] = rng
+	}
+
+	// Always draw the same amount of numbers, so one decision never shifts the next
+	drop, dup, reorder := rng.Float64(), rng.Float64(), rng.Float64()
+	delays := []time.Duration{rule.delay(rng), rule.delay(rng)}
+
+	// Lost?
+	if drop < rule.DropRate {
+		return Fate{Dropped: true}
+	}
+
+	// Held back?
+	fate := Fate{HeldBack: reorder < rule.ReorderRate, Delays: delays[:1]}
+	if fate.HeldBack {
+		delays[0] += rule.ReorderDelay
+	}
+
+	// Duplicated?
+	if dup < rule.DuplicateRate {
+		fate.Delays = delays
+	}
+
+	return fate
+
+}
+
+// Draw a delay within the bounds of the rule
+func (rule LinkRule) delay(rng *rand.Rand) time.Duration {
+	span := int64(rule.MaxDelay - rule.MinDelay)
+	if span <= 0 {
+		rng.Int63()
+		return rule.MinDelay
+	}
+	return rule.MinDelay + time.Duration(rng.Int63n(span+1))
+}
+
+// Simulated network of named parties
+type SimNetwork struct {
+	Scheduler *Scheduler
+
+	// Log every fault that is injected
+	Verbose bool
+
+	mutex      sync.Mutex
+	listeners  map[string]*simListener
+	rules      map[string]LinkRule
+	partitions [][2]StringHashSet
+	connCount  int
+}
+
+// Creates a new simulated network, where all faults are derived from seed
+func NewSimNetwork(seed int64) *SimNetwork {
+	return &SimNetwork{
+		Scheduler: NewScheduler(seed),
+		listeners: map[string]*simListener{},
+		rules:     map[string]LinkRule{},
+	}
+}
+
+// Set the fault rule for messages from 'from' to 'to' (SIM_ANY matches all parties)
+func (n *SimNetwork) SetLink(from, to string, rule LinkRule) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.rules[from+">"+to] = rule
+}
+
+// Cut all traffic between the two groups of parties (both directions)
+func (n *SimNetwork) Partition(a, b []string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.partitions = append(n.partitions, [2]StringHashSet{CheckmapFromStringSlice(a), CheckmapFromStringSlice(b)})
+}
+
+// Remove all partitions
+func (n *SimNetwork) Heal() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.partitions = nil
+}
+
+// Get a transport for the party with the given name
+func (n *SimNetwork) Endpoint(name string) Transport {
+	return &simEndpoint{network: n, name: name}
+}
+
+// Find the rule of a link, the most specific rule wins
+func (n *SimNetwork) rule(from, to string) LinkRule {
+	for _, key := range []string{from + ">" + to, from + ">" + SIM_ANY, SIM_ANY + ">" + to, SIM_ANY + ">" + SIM_ANY} {
+		if r, exists := n.rules[key]; exists {
+			return r
+		}
+	}
+	return LinkRule{}
+}
+
+// Check if two parties are separated by a partition
+func (n *SimNetwork) partitioned(from, to string) bool {
+	for _, p := range n.partitions {
+		_, fa := p[0][from]
+		_, fb := p[1][from]
+		_, ta := p[0][to]
+		_, tb := p[1][to]
+		if (fa && tb) || (fb && ta) {
+			return true
+		}
+	}
+	return false
+}
+
+// Route a message over a link
+func (n *SimNetwork) route(link *simLink, req Request) {
+
+	n.mutex.Lock()
+	rule := n.rule(link.from, link.to)
+	cut := n.partitioned(link.from, link.to)
+	n.mutex.Unlock()
+
+	// Always consult the scheduler, so a partition does not shift later decisions
+	fate := n.Scheduler.Decide(link.from, link.to, rule)
+	if cut || fate.Dropped {
+		n.logf("dropped request %v %s->%s (partition: %v)", req.RequestType, link.from, link.to, cut)
+		return
+	}
+	if len(fate.Delays) > 1 {
+		n.logf("duplicated request %v %s->%s", req.RequestType, link.from, link.to)
+	}
+
+	// Schedule each copy
+	for k, d := range fate.Delays {
+		link.push(req, d, k == 0 && fate.HeldBack)
+	}
+
+}
+
+func (n *SimNetwork) logf(format string, args ...interface{}) {
+	if n.Verbose {
+		fmt.Printf("[SimNet] "+format+"\n", args...)
+	}
+}
+
+// Transport bound to a single named party
+type simEndpoint struct {
+	network *SimNetwork
+	name    string
+}
+
+func (e *simEndpoint) Listen(ip, port string) (Listener, error) {
+	n := e.network
+	addr := net.JoinHostPort(ip, port)
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if _, exists := n.listeners[addr]; exists {
+		return nil, fmt.Errorf("simnet: address %s already in use", addr)
+	}
+	l := &simListener{network: n, owner: e.name, addr: addr, accept: make(chan Conn, 1024), done: make(chan struct{})}
+	n.listeners[addr] = l
+	return l, nil
+}
+
+func (e *simEndpoint) Dial(ip, port string) (Conn, error) {
+	n := e.network
+	addr := net.JoinHostPort(ip, port)
+	n.mutex.Lock()
+	l, exists := n.listeners[addr]
+	if !exists {
+		n.mutex.Unlock()
+		return nil, fmt.Errorf("simnet: connection refused by %s", addr)
+	}
+	if n.partitioned(e.name, l.owner) {
+		n.mutex.Unlock()
+		return nil, fmt.Errorf("simnet: %s cannot reach %s (partitioned)", e.name, l.owner)
+	}
+	n.connCount++
+	id := n.connCount
+	n.mutex.Unlock()
+
+	// Make both ends
+	local := newSimConn(e.name, l.owner, id)
+	remote := newSimConn(l.owner, e.name, id)
+	local.out = newSimLink(n, e.name, l.owner, remote.inbox)
+	remote.out = newSimLink(n, l.owner, e.name, local.inbox)
+
+	// Hand over to listener
+	select {
+	case l.accept <- remote:
+		return local, nil
+	case <-l.done:
+		return nil, fmt.Errorf("simnet: connection refused by %s", addr)
+	}
+}
+
+// Simulated listener
+type simListener struct {
+	network *SimNetwork
+	owner   string
+	addr    string
+	accept  chan Conn
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (l *simListener) Accept() (Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *simListener) Close() error {
+	l.once.Do(func() {
+		l.network.mutex.Lock()
+		delete(l.network.listeners, l.addr)
+		l.network.mutex.Unlock()
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *simListener) Addr() string {
+	return l.addr
+}
+
+// One end of a simulated connection
+type simConn struct {
+	local  string
+	remote string
+	id     int
+	inbox  *simInbox
+	out    *simLink
+}
+
+func newSimConn(local, remote string, id int) *simConn {
+	return &simConn{local: local, remote: remote, id: id, inbox: newSimInbox()}
+}
+
+func (c *simConn) Send(req Request) error {
+	if c.out.isClosed() || c.out.target.isClosed() {
+		return io.ErrClosedPipe
+	}
+	c.out.network.route(c.out, req)
+	return nil
+}
+
+func (c *simConn) Receive() (Request, error) {
+	return c.inbox.pop()
+}
+
+func (c *simConn) Close() error {
+	c.inbox.close()
+	c.out.close()
+	return nil
+}
+
+func (c *simConn) RemoteAddr() string {
+	return c.remote + ":" + strconv.Itoa(c.id)
+}
+
+// Unbounded queue of delivered requests
+type simInbox struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	queue  []Request
+	closed bool
+}
+
+func newSimInbox() *simInbox {
+	i := &simInbox{}
+	i.cond = sync.NewCond(&i.mutex)
+	return i
+}
+
+func (i *simInbox) push(req Request) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if !i.closed {
+		i.queue = append(i.queue, req)
+		i.cond.Signal()
+	}
+}
+
+func (i *simInbox) pop() (Request, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for len(i.queue) == 0 && !i.closed {
+		i.cond.Wait()
+	}
+	if len(i.queue) == 0 {
+		return Request{}, io.EOF
+	}
+	req := i.queue[0]
+	i.queue = i.queue[1:]
+	return req, nil
+}
+
+func (i *simInbox) isClosed() bool {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.closed
+}
+
+func (i *simInbox) close() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.closed = true
+	i.cond.Broadcast()
+}
+
+// Directed link delivering requests at their scheduled time
+type simLink struct {
+	network *SimNetwork
+	from    string
+	to      string
+	target  *simInbox
+	mutex   sync.Mutex
+	pending simQueue
+	seq     int
+	lastAt  time.Time
+	closed  bool
+	wake    chan struct{}
+}
+
+func newSimLink(n *SimNetwork, from, to string, target *simInbox) *simLink {
+	l := &simLink{network: n, from: from, to: to, target: target, wake: make(chan struct{}, 1)}
+	go l.run()
+	return l
+}
+
+// Schedule a request for delivery. Like TCP, a link keeps messages in order,
+// unless the message was explicitly held back by the scheduler.
+func (l *simLink) push(req Request, delay time.Duration, heldBack bool) {
+	l.mutex.Lock()
+	l.seq++
+	at := time.Now().Add(delay)
+	if !heldBack {
+		if at.Before(l.lastAt) {
+			at = l.lastAt
+		}
+		l.lastAt = at
+	}
+	heap.Push(&l.pending, simDelivery{at: at, seq: l.seq, req: req})
+	l.mutex.Unlock()
+	l.signal()
+}
+
+func (l *simLink) close() {
+	l.mutex.Lock()
+	l.closed = true
+	l.mutex.Unlock()
+	l.signal()
+}
+
+func (l *simLink) isClosed() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.closed
+}
+
+func (l *simLink) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Deliver pending requests in order of their delivery time.
+// Requests still in flight when the link closes are delivered before the far end sees EOF.
+func (l *simLink) run() {
+	for {
+		l.mutex.Lock()
+		if len(l.pending) == 0 {
+			closed := l.closed
+			l.mutex.Unlock()
+			if closed {
+				l.target.close()
+				return
+			}
+			<-l.wake
+			continue
+		}
+		next := l.pending[0]
+		if wait := time.Until(next.at); wait > 0 {
+			l.mutex.Unlock()
+			select {
+			case <-time.After(wait):
+			case <-l.wake:
+			}
+			continue
+		}
+		heap.Pop(&l.pending)
+		l.mutex.Unlock()
+		l.target.push(next.req)
+	}
+}
+
+// Scheduled delivery of a request
+type simDelivery struct {
+	at  time.Time
+	seq int
+	req Request
+}
+
+// Priority queue of deliveries (earliest first, then in order of sending)
+type simQueue []simDelivery
+
+func (q simQueue) Len() int { return len(q) }
+func (q simQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+func (q simQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *simQueue) Push(x interface{}) { *q = append(*q, x.(simDelivery)) }
+func (q *simQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// Parses link rules of the form "from>to:delay=5ms-20ms,drop=0.1,dup=0.05,reorder=0.1;..."
+// where from and to are party names or '*'.
+func (n *SimNetwork) ParseLinkRules(spec string) error {
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		linkAndOpts := strings.SplitN(entry, ":", 2)
+		parties := strings.Split(linkAndOpts[0], ">")
+		if len(parties) != 2 || len(linkAndOpts) != 2 {
+			return fmt.Errorf("invalid link rule '%s'", entry)
+		}
+		rule := LinkRule{}
+		for _, opt := range strings.Split(linkAndOpts[1], ",") {
+			kv := strings.SplitN(opt, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid link option '%s'", opt)
+			}
+			var err error
+			switch kv[0] {
+			case "delay":
+				bounds := strings.SplitN(kv[1], "-", 2)
+				if rule.MinDelay, err = time.ParseDuration(bounds[0]); err == nil {
+					rule.MaxDelay = rule.MinDelay
+					if len(bounds) == 2 {
+						rule.MaxDelay, err = time.ParseDuration(bounds[1])
+					}
+				}
+			case "drop":
+				rule.DropRate, err = strconv.ParseFloat(kv[1], 64)
+			case "dup":
+				rule.DuplicateRate, err = strconv.ParseFloat(kv[1], 64)
+			case "reorder":
+				rule.ReorderRate, err = strconv.ParseFloat(kv[1], 64)
+			default:
+				err = errors.New("unknown option")
+			}
+			if err != nil {
+				return fmt.Errorf("invalid link option '%s': %v", opt, err)
+			}
+		}
+		if rule.ReorderDelay == 0 {
+			rule.ReorderDelay = rule.MaxDelay + 10*time.Millisecond
+		}
+		n.SetLink(strings.TrimSpace(parties[0]), strings.TrimSpace(parties[1]), rule)
+	}
+	return nil
+}
+
+// Parses a partition of the form "S1,S2|S3,S4"
+func (n *SimNetwork) ParsePartition(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	groups := strings.Split(spec, "|")
+	if len(groups) != 2 {
+		return fmt.Errorf("invalid partition '%s', expected two groups", spec)
+	}
+	n.Partition(strings.Split(groups[0], ","), strings.Split(groups[1], ","))
+	return nil
+}
@@ -0,0 +1,124 @@
+package protocol
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// A message oriented connection between two parties
+type Conn interface {
+
+	// Send a request to the other end of the connection
+	Send(req Request) error
+
+	// Block until the next request arrives (io.EOF once closed)
+	Receive() (Request, error)
+
+	// Close the connection
+	Close() error
+
+	// Address of the other end (used as key for voters)
+	RemoteAddr() string
+}
+
+// Accepts incoming connections on an address
+type Listener interface {
+	Accept() (Conn, error)
+	Close() error
+	Addr() string
+}
+
+// Transport defines how parties reach each other.
+// Server and Client only ever talk through a Transport, which allows the
+// real network (TCPTransport) to be swapped with a simulated one (SimNetwork).
+type Transport interface {
+	Listen(ip, port string) (Listener, error)
+	Dial(ip, port string) (Conn, error)
+}
+
+// The transport used when none is specified
+var DefaultTransport Transport = TCPTransport{}
+
+// Transport over TCP, encoding requests using gob
+type TCPTransport struct{}
+
+// Connection over TCP
+type tcpConn struct {
+	conn      net.Conn
+	encoder   *gob.Encoder
+	decoder   *gob.Decoder
+	sendMutex sync.Mutex
+}
+
+// Listener over TCP
+type tcpListener struct {
+	ln net.Listener
+}
+
+// Wraps a net.Conn into a Conn
+func newTCPConn(conn net.Conn) *tcpConn {
+	return &tcpConn{
+		conn:    conn,
+		encoder: gob.NewEncoder(conn),
+		decoder: gob.NewDecoder(conn),
+	}
+}
+
+func (c *tcpConn) Send(req Request) error {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	return c.encoder.Encode(req)
+}
+
+func (c *tcpConn) Receive() (Request, error) {
+	var req Request
+	e := c.decoder.Decode(&req)
+	// A connection closed at either end (also mid-message) is io.EOF, as promised by Conn
+	if errors.Is(e, net.ErrClosed) || errors.Is(e, io.ErrUnexpectedEOF) {
+		e = io.EOF
+	}
+	return req, e
+}
+
+func (c *tcpConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *tcpConn) RemoteAddr() string {
+	return c.conn.RemoteAddr().String()
+}
+
+func (l *tcpListener) Accept() (Conn, error) {
+	conn, err := l.ln.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return newTCPConn(conn), nil
+}
+
+func (l *tcpListener) Close() error {
+	return l.ln.Close()
+}
+
+func (l *tcpListener) Addr() string {
+	return l.ln.Addr().String()
+}
+
+func (TCPTransport) Listen(ip, port string) (Listener, error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort(ip, port))
+	if err != nil {
+		return nil, err
+	}
+	return &tcpListener{ln: ln}, nil
+}
+
+func (TCPTransport) Dial(ip, port string) (Conn, error) {
+	conn, err := net.Dial("tcp", net.JoinHostPort(ip, port))
+	if err != nil {
+		return nil, err
+	}
+	return newTCPConn(conn), nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/tallyserver"
+	"cs.au.dk/voting/voteclient"
+)
+
+// Scenario describing an election with (possibly) bad servers and a faulty network
+type Scenario struct {
+	Seed      int64                                    `json:"seed"`      // Seed of the simulation
+	Scheme    string                                   `json:"scheme"`    // Sharing scheme (see scheme.Names)
+	Tally     string                                   `json:"tally"`     // Tally strategy (see tally.Names)
+	Count     int                                      `json:"count"`     // Amount of servers (additive and elgamal schemes only)
+	MAC       bool                                     `json:"mac"`       // Check the tally with SPDZ MACs (additive scheme only)
+	Proofs    bool                                     `json:"proofs"`    // Prove the ballots encrypt 0 or 1 (elgamal scheme only)
+	Anonymous bool                                     `json:"anonymous"` // Voters join with credentials from a registration authority
+	Detach    bool                                     `json:"detach"`    // Voters hang up after voting, and fetch the results later
+	Voters    int                                      `json:"voters"`    // Amount of voters
+	VoteTime  int                                      `json:"votetime"`  // Voting period in seconds
+	Links     string                                   `json:"links"`     // Simulated link faults
+	Partition string                                   `json:"partition"` // Simulated partition
+	Offline   []int                                    `json:"offline"`   // ServerIDs of servers that are never started
+	Restarts  []int                                    `json:"restarts"`  // ServerIDs of servers that crash and restart
+	Crashes   []int                                    `json:"crashes"`   // ServerIDs of servers that crash once the votes are cast
+	Outage    string                                   `json:"outage"`    // Simulated partition lasting a while
+	Servers   map[string][]tallyserver.BehaviourConfig `json:"servers"`   // Behaviours keyed by ServerID
+	Clients   map[string]VoterMode                     `json:"clients"`   // Bad voters keyed by voter number
+}
+
+// The scenarios shipped in the scenarios folder (built in, so the tests find them wherever they run)
+//
+//go:embed scenarios/*.json
+var builtinScenarios embed.FS
+
+// Load scenario from a JSON file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseScenario(data, path)
+}
+
+// Load one of the scenarios shipped in the scenarios folder by its file name
+func BuiltinScenario(name string) (*Scenario, error) {
+	data, err := builtinScenarios.ReadFile("scenarios/" + name)
+	if err != nil {
+		return nil, err
+	}
+	return parseScenario(data, name)
+}
+
+// Parse a scenario from JSON
+func parseScenario(data []byte, path string) (*Scenario, error) {
+	scenario := new(Scenario)
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario '%s': %v", path, err)
+	}
+	return scenario, nil
+}
+
+// Create the behaviours of the server with the given ServerID
+func (s *Scenario) BehavioursOf(serverID int) ([]tallyserver.Behaviour, error) {
+	behaviours := make([]tallyserver.Behaviour, 0)
+	for _, cfg := range s.Servers[strconv.Itoa(serverID)] {
+		b, err := tallyserver.NewBehaviour(cfg)
+		if err != nil {
+			return nil, err
+		}
+		behaviours = append(behaviours, b)
+	}
+	return behaviours, nil
+}
+
+// Apply the scenario to a simulated election (values set in the scenario take precedence)
+func (s *Scenario) Apply(election SimElection) (SimElection, error) {
+	if s.Seed != 0 {
+		election.Seed = s.Seed
+	}
+	if s.Scheme != "" {
+		sch, err := scheme.ByName(s.Scheme)
+		if err != nil {
+			return election, err
+		}
+		election.Scheme = sch
+	}
+	if s.Count != 0 {
+		sch, err := scheme.Sized(election.SchemeOrDefault(), s.Count)
+		if err != nil {
+			return election, err
+		}
+		election.Scheme = sch
+	}
+	if s.Tally != "" || s.Scheme != "" {
+		strategy, err := scheme.StrategyOf(election.SchemeOrDefault(), s.Tally)
+		if err != nil {
+			return election, err
+		}
+		election.Strategy = strategy
+	}
+	if s.MAC {
+		election.MAC = true
+	}
+	if s.Proofs {
+		election.Proofs = true
+	}
+	if s.Anonymous {
+		election.Anonymous = true
+	}
+	if s.Detach {
+		election.Detach = true
+	}
+	if s.Voters != 0 {
+		election.Voters = s.Voters
+	}
+	if s.VoteTime != 0 {
+		election.VoteTime = s.VoteTime
+	}
+	if s.Links != "" {
+		election.Links = s.Links
+	}
+	if s.Partition != "" {
+		election.Partition = s.Partition
+	}
+	if len(s.Offline) != 0 {
+		election.Offline = s.Offline
+	}
+	if len(s.Restarts) != 0 {
+		election.Restarts = s.Restarts
+	}
+	if len(s.Crashes) != 0 {
+		election.Crashes = s.Crashes
+	}
+	if s.Outage != "" {
+		election.Outage = s.Outage
+	}
+	election.Behaviours = map[int][]tallyserver.Behaviour{}
+	for i := 1; i <= election.SchemeOrDefault().Servers(); i++ {
+		behaviours, err := s.BehavioursOf(i)
+		if err != nil {
+			return election, err
+		}
+		election.Behaviours[i] = behaviours
+	}
+	election.BadVoters = map[int]VoterMode{}
+	for voter, mode := range s.Clients {
+		i, err := strconv.Atoi(voter)
+		if err != nil || !voteclient.IsClientMode(mode.Mode) {
+			return election, fmt.Errorf("invalid bad voter '%s' (%+v)", voter, mode)
+		}
+		election.BadVoters[i] = mode
+	}
+	return election, nil
+}
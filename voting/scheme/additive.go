@@ -0,0 +1,33 @@
+package scheme
+
+import (
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/tally"
+)
+
+// Amount of servers of the additive scheme if not given
+const ADDITIVE_SERVERS = 2
+
+// Additive sharing among n servers (r1 + ... + rn = x mod p). Every server is needed to tally, and lies cannot be
+// detected, but the vote stays secret as long as one server is honest.
+type Additive struct {
+	N int // Amount of servers (ADDITIVE_SERVERS if 0)
+}
+
+func (Additive) Name() string { return "additive" }
+
+func (a Additive) Servers() int {
+	if a.N == 0 {
+		return ADDITIVE_SERVERS
+	}
+	return a.N
+}
+
+func (a Additive) MinServers() int { return a.Servers() }
+
+func (a Additive) Share(rng sharing.RNG, vote, p, k int) []int {
+	return sharing.AdditiveSecrify(rng, vote, p, a.Servers())
+}
+
+// The shares are not points on a polynomium, so they can only be added up
+func (Additive) Strategies() []string { return []string{tally.SUM} }
@@ -0,0 +1,21 @@
+package scheme
+
+import (
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/tally"
+)
+
+// Shamir sharing among four servers, correcting a lying server with the Berlekamp-Welch decoder (error correction)
+type ShamirCorrect struct{}
+
+func (ShamirCorrect) Name() string    { return "shamir-correct" }
+func (ShamirCorrect) Servers() int    { return 4 }
+func (ShamirCorrect) MinServers() int { return sharing.MIN_SERVERS }
+
+func (ShamirCorrect) Share(rng sharing.RNG, vote, p, k int) []int {
+	return sharing.Secrify(rng, vote, p, k, 4)
+}
+
+func (ShamirCorrect) Strategies() []string {
+	return withDefault(tally.BERLEKAMP_WELCH, shamirStrategies)
+}
@@ -0,0 +1,19 @@
+package scheme
+
+import (
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/tally"
+)
+
+// Shamir sharing among three servers, checking the third point against the line through the other two (error detection)
+type ShamirDetect struct{}
+
+func (ShamirDetect) Name() string    { return "shamir-detect" }
+func (ShamirDetect) Servers() int    { return 3 }
+func (ShamirDetect) MinServers() int { return sharing.MIN_SERVERS }
+
+func (ShamirDetect) Share(rng sharing.RNG, vote, p, k int) []int {
+	return sharing.Secrify(rng, vote, p, k, 3)
+}
+
+func (ShamirDetect) Strategies() []string { return withDefault(tally.REEVALUATE, shamirStrategies) }
@@ -0,0 +1,34 @@
+package scheme
+
+import (
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/tally"
+)
+
+// Amount of servers of the elgamal scheme if not given
+const ELGAMAL_SERVERS = 3
+
+// Threshold exponential ElGamal among n servers (see package elgamal). The servers generate the election key
+// together, and a voter sends a single encrypted ballot to one server of its choice. The ballots are multiplied
+// together (adding up the votes) and any k+1 servers decrypt the sum, so the vote stays secret as long as no more
+// than k servers collude, and the tally survives servers going offline once the key is generated.
+type ElGamal struct {
+	N int // Amount of servers (ELGAMAL_SERVERS if 0)
+}
+
+func (ElGamal) Name() string { return "elgamal" }
+
+func (e ElGamal) Servers() int {
+	if e.N == 0 {
+		return ELGAMAL_SERVERS
+	}
+	return e.N
+}
+
+func (ElGamal) MinServers() int { return sharing.MIN_SERVERS }
+
+// The ballot is encrypted rather than shared (see elgamal.Encrypt), so there are no shares
+func (ElGamal) Share(rng sharing.RNG, vote, p, k int) []int { return nil }
+
+// The sum is decrypted rather than reconstructed
+func (ElGamal) Strategies() []string { return []string{tally.DECRYPT} }
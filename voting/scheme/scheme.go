@@ -0,0 +1,117 @@
+package scheme
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/tally"
+)
+
+// A secret sharing scheme an election is run with. The servers, their mesh, the voter registration and the
+// client list intersection are the same for every scheme, only the shares and the tally differ.
+type Scheme interface {
+
+	// Name of the scheme (as given to -scheme)
+	Name() string
+
+	// Amount of servers the votes are shared among
+	Servers() int
+
+	// Least amount of servers needed to tally
+	MinServers() int
+
+	// Split the vote into one share per server with the randomness (k is the degree of the polynomium, if any)
+	Share(rng sharing.RNG, vote, p, k int) []int
+
+	// Names of the tally strategies the shares can be reconstructed with (the first is the default)
+	Strategies() []string
+}
+
+// Name of the scheme used if none is given
+const DEFAULT = "shamir-correct"
+
+// Lists the known schemes
+var Names = []string{"additive", "shamir", "shamir-detect", "shamir-correct", "elgamal"}
+
+// Tally strategies of the Shamir schemes (each scheme puts its own default first)
+var shamirStrategies = []string{tally.INTERPOLATE, tally.REEVALUATE, tally.MAJORITY, tally.BERLEKAMP_WELCH}
+
+// Get the scheme with the given name
+func ByName(name string) (Scheme, error) {
+	switch name {
+	case "additive":
+		return Additive{}, nil
+	case "shamir":
+		return Shamir{}, nil
+	case "shamir-detect":
+		return ShamirDetect{}, nil
+	case "shamir-correct":
+		return ShamirCorrect{}, nil
+	case "elgamal":
+		return ElGamal{}, nil
+	}
+	return nil, fmt.Errorf("unknown scheme '%s' (known: %v)", name, Names)
+}
+
+// Get the scheme used if none is given
+func Default() Scheme {
+	s, _ := ByName(DEFAULT)
+	return s
+}
+
+// Get the scheme with the given amount of servers (0 keeps the amount of the scheme).
+// Only the additive and elgamal schemes can be run among any amount of servers.
+func Sized(s Scheme, servers int) (Scheme, error) {
+	if servers == 0 || servers == s.Servers() {
+		return s, nil
+	}
+	switch s.(type) {
+	case Additive, ElGamal:
+	default:
+		return nil, fmt.Errorf("the %s scheme has a fixed amount of %v servers", s.Name(), s.Servers())
+	}
+	if servers < 2 {
+		return nil, fmt.Errorf("the %s scheme needs at least 2 servers, not %v", s.Name(), servers)
+	}
+	if _, ok := s.(ElGamal); ok {
+		return ElGamal{N: servers}, nil
+	}
+	return Additive{N: servers}, nil
+}
+
+// Check if the tally of the scheme can be MAC checked (SPDZ MACs are on additive shares only)
+func SupportsMACs(s Scheme) bool {
+	_, ok := s.(Additive)
+	return ok
+}
+
+// Check if the ballots of the scheme are encrypted (a voter then reaches only one server, which it sends its
+// whole ballot to)
+func Encrypts(s Scheme) bool {
+	_, ok := s.(ElGamal)
+	return ok
+}
+
+// Get the tally strategy with the given name for the scheme (its default if the name is empty)
+func StrategyOf(s Scheme, name string) (tally.Strategy, error) {
+	if name == "" {
+		return tally.ByName(s.Strategies()[0])
+	}
+	for _, known := range s.Strategies() {
+		if known == name {
+			return tally.ByName(name)
+		}
+	}
+	return nil, fmt.Errorf("the %s scheme cannot be tallied with '%s' (known: %v)", s.Name(), name, s.Strategies())
+}
+
+// Put the strategy first among the strategies
+func withDefault(strategy string, strategies []string) []string {
+	ordered := []string{strategy}
+	for _, s := range strategies {
+		if s != strategy {
+			ordered = append(ordered, s)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,19 @@
+package scheme
+
+import (
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/tally"
+)
+
+// Shamir sharing among three servers, interpolating whatever points there are (no error detection)
+type Shamir struct{}
+
+func (Shamir) Name() string    { return "shamir" }
+func (Shamir) Servers() int    { return 3 }
+func (Shamir) MinServers() int { return sharing.MIN_SERVERS }
+
+func (Shamir) Share(rng sharing.RNG, vote, p, k int) []int {
+	return sharing.Secrify(rng, vote, p, k, 3)
+}
+
+func (Shamir) Strategies() []string { return withDefault(tally.INTERPOLATE, shamirStrategies) }
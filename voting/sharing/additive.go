@@ -0,0 +1,19 @@
+package sharing
+
+// Secrifies the secret 'x' using prime 'p', using additive sharing among n parties.
+// Returns the secret shares r1, ..., rn (r1 + ... + rn = x mod p), where any n-1 of them reveal nothing about x.
+func AdditiveSecrify(rng RNG, x, p, n int) []int {
+
+	// Pick R1, ..., Rn-1 uniformly at random within the field of Z
+	shares := make([]int, n)
+	sum := 0
+	for i := 0; i < n-1; i++ {
+		shares[i] = RandomElement(rng, p)
+		sum = SumField(p, sum, shares[i])
+	}
+
+	// Calculate Rn
+	shares[n-1] = Pmod(x-sum, p)
+
+	return shares
+}
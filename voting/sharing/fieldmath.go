@@ -1,10 +1,10 @@
-package main
+package sharing
 
 import "fmt"
 
 // Positive integer mod operation (Thanks reddit)
 // That is, it computes y = x mod d such that y >= 0
-func pmod(x, d int) int {
+func Pmod(x, d int) int {
 	x = x % d
 	if x >= 0 {
 		return x
@@ -15,67 +15,55 @@ func pmod(x, d int) int {
 	return x + d
 }
 
-// Finds the multiplicative inverse of a*t mod n (that is, find -t)
+// Finds the multiplicative inverse of a mod p (the t with a*t = 1 mod p), panicking if a has none (a = 0 mod p)
 // https://en.wikipedia.org/wiki/Extended_Euclidean_algorithm
 // Section on calculating the inverse
 func Inverse(a, p int) int {
 	t, nt := 0, 1
-	r, nr := p, a
+	r, nr := p, Pmod(a, p)
 	for nr != 0 {
 		q := r / nr
 		r, nr = nr, r-q*nr
 		t, nt = nt, t-q*nt
 	}
-	if r > p {
+	if r != 1 {
 		panic(fmt.Errorf("cannot invert %v given %v", a, p))
 	}
-	return (1 / r) * t
+	return Pmod(t, p)
 }
 
 // Computes n/d % p
 // Multiplicative inverse, which we need for staying in the field
 func DivMod(n, d, p int) int {
-	return n * Inverse(d, p)
+	return MulField(Pmod(n, p), Inverse(d, p), p)
 }
 
 func SubField(lhs, rhs, p int) int {
-	return pmod(lhs-rhs, p)
+	return Pmod(lhs-rhs, p)
 }
 
 func MulField(lhs, rhs, p int) int {
-	return pmod(lhs*rhs, p)
+	return Pmod(lhs*rhs, p)
 }
 
 func SumField(p int, vals ...int) int {
 	sum := 0
 	for _, v := range vals {
-		sum = pmod(sum+v, p)
+		sum = Pmod(sum+v, p)
 	}
 	return sum
 }
 
-// Peforms x^y operation and stays in integer domain.
+// Computes x^y mod p by square-and-multiply (y >= 0), staying in the field.
 // Using math.pow would require casting which *could* lead to incorrect values because floating points
-func IPow(x, y int) int {
-	if y == 0 {
-		return 1
+func PowField(x, y, p int) int {
+	z := 1
+	x = Pmod(x, p)
+	for ; y > 0; y >>= 1 {
+		if y&1 == 1 {
+			z = MulField(z, x, p)
+		}
+		x = MulField(x, x, p)
 	}
-	z := x
-	for i := 2; i <= y; i++ {
-		z *= x
-	}
-	return z
-}
-
-// Peforms x^y operation and stays in integer domain.
-// Using math.pow would require casting which *could* lead to incorrect values because floating points
-func IPowF(x, y, p int) int {
-	if y == 0 {
-		return 1
-	}
-	z := x
-	for i := 2; i <= y; i++ {
-		z = MulField(z, x, p)
-	}
-	return z
+	return Pmod(z, p)
 }
@@ -0,0 +1,215 @@
+package sharing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Matrix over the field Z_p (the entries are always reduced into the field)
+type Matrix struct {
+	Rows [][]int
+	P    int
+}
+
+// Errors of linear algebra
+var (
+	ErrSingular     = errors.New("singular matrix (no unique solution)")
+	ErrInconsistent = errors.New("inconsistent system (no solution)")
+	ErrNotSquare    = errors.New("matrix is not square")
+	ErrDimensions   = errors.New("dimensions do not match")
+)
+
+// Create a matrix over Z_p from its rows (copied, and every row must be as long as the first)
+func NewMatrix(p int, rows [][]int) Matrix {
+	m := Matrix{Rows: make([][]int, len(rows)), P: p}
+	for i, row := range rows {
+		if len(row) != len(rows[0]) {
+			panic(fmt.Errorf("row %v has %v entries, not %v", i, len(row), len(rows[0])))
+		}
+		m.Rows[i] = make([]int, len(row))
+		for j, v := range row {
+			m.Rows[i][j] = Pmod(v, p)
+		}
+	}
+	return m
+}
+
+// Create the n x n identity matrix over Z_p
+func IdentityMatrix(p, n int) Matrix {
+	rows := make([][]int, n)
+	for i := range rows {
+		rows[i] = make([]int, n)
+		rows[i][i] = 1
+	}
+	return NewMatrix(p, rows)
+}
+
+// Amount of rows and columns
+func (m Matrix) Dims() (int, int) {
+	if len(m.Rows) == 0 {
+		return 0, 0
+	}
+	return len(m.Rows), len(m.Rows[0])
+}
+
+// Compute m * o
+func (m Matrix) Mul(o Matrix) (Matrix, error) {
+	r, c := m.Dims()
+	or, oc := o.Dims()
+	if c != or {
+		return Matrix{}, ErrDimensions
+	}
+	prod := make([][]int, r)
+	for i := range prod {
+		prod[i] = make([]int, oc)
+		for j := range prod[i] {
+			for k := 0; k < c; k++ {
+				prod[i][j] = SumField(m.P, prod[i][j], MulField(m.Rows[i][k], o.Rows[k][j], m.P))
+			}
+		}
+	}
+	return Matrix{Rows: prod, P: m.P}, nil
+}
+
+// Compute m * v
+func (m Matrix) MulVec(v []int) ([]int, error) {
+	r, c := m.Dims()
+	if c != len(v) {
+		return nil, ErrDimensions
+	}
+	prod := make([]int, r)
+	for i := range prod {
+		for k := 0; k < c; k++ {
+			prod[i] = SumField(m.P, prod[i], MulField(m.Rows[i][k], v[k], m.P))
+		}
+	}
+	return prod, nil
+}
+
+// Bring a copy of the matrix into reduced row echelon form (Gauss-Jordan), giving the columns of the pivots.
+// In a field every non-zero entry can be a pivot, so the first one in the column is taken. The determinant is
+// tracked along the way (it is only meaningful for square matrices).
+func (m Matrix) reduce() (Matrix, []int, int) {
+	r, c := m.Dims()
+	red := NewMatrix(m.P, m.Rows)
+	pivots := make([]int, 0)
+	det := 1
+	row := 0
+	for col := 0; col < c && row < r; col++ {
+
+		// Find a row with a non-zero entry in the column
+		pivot := -1
+		for i := row; i < r; i++ {
+			if red.Rows[i][col] != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			det = 0
+			continue
+		}
+		if pivot != row {
+			red.Rows[pivot], red.Rows[row] = red.Rows[row], red.Rows[pivot]
+			det = SubField(0, det, m.P)
+		}
+
+		// Scale the pivot to 1, and clear the column in every other row
+		lead := red.Rows[row][col]
+		det = MulField(det, lead, m.P)
+		inv := Inverse(lead, m.P)
+		for j := range red.Rows[row] {
+			red.Rows[row][j] = MulField(red.Rows[row][j], inv, m.P)
+		}
+		for i := 0; i < r; i++ {
+			if i == row || red.Rows[i][col] == 0 {
+				continue
+			}
+			f := red.Rows[i][col]
+			for j := range red.Rows[i] {
+				red.Rows[i][j] = SubField(red.Rows[i][j], MulField(f, red.Rows[row][j], m.P), m.P)
+			}
+		}
+		pivots = append(pivots, col)
+		row++
+	}
+	if len(pivots) < r {
+		det = 0
+	}
+	return red, pivots, det
+}
+
+// Rank of the matrix
+func (m Matrix) Rank() int {
+	_, pivots, _ := m.reduce()
+	return len(pivots)
+}
+
+// Determinant of the (square) matrix
+func (m Matrix) Det() (int, error) {
+	r, c := m.Dims()
+	if r != c {
+		return 0, ErrNotSquare
+	}
+	_, _, det := m.reduce()
+	return det, nil
+}
+
+// Check if the (square) matrix is singular
+func (m Matrix) Singular() bool {
+	det, err := m.Det()
+	return err != nil || det == 0
+}
+
+// Inverse of the (square) matrix
+func (m Matrix) Inverse() (Matrix, error) {
+	r, c := m.Dims()
+	if r != c {
+		return Matrix{}, ErrNotSquare
+	}
+
+	// Reduce [m | I], giving [I | m^-1]
+	aug := make([][]int, r)
+	for i := range aug {
+		aug[i] = append(append([]int{}, m.Rows[i]...), make([]int, r)...)
+		aug[i][c+i] = 1
+	}
+	red, pivots, _ := NewMatrix(m.P, aug).reduce()
+	if len(pivots) < r || pivots[r-1] >= c {
+		return Matrix{}, ErrSingular
+	}
+	inv := make([][]int, r)
+	for i := range inv {
+		inv[i] = red.Rows[i][c:]
+	}
+	return Matrix{Rows: inv, P: m.P}, nil
+}
+
+// Solve m * x = b for the unique x. Fails with ErrInconsistent if there is no solution, and with ErrSingular if
+// there is more than one. The system may have more equations than unknowns (if they are consistent).
+func (m Matrix) Solve(b []int) ([]int, error) {
+	r, c := m.Dims()
+	if r != len(b) {
+		return nil, ErrDimensions
+	}
+
+	// Reduce [m | b]
+	aug := make([][]int, r)
+	for i := range aug {
+		aug[i] = append(append([]int{}, m.Rows[i]...), b[i])
+	}
+	red, pivots, _ := NewMatrix(m.P, aug).reduce()
+
+	// A pivot in the column of b means 0 = 1, too few pivots means free unknowns
+	if len(pivots) > 0 && pivots[len(pivots)-1] == c {
+		return nil, ErrInconsistent
+	}
+	if len(pivots) < c {
+		return nil, ErrSingular
+	}
+	x := make([]int, c)
+	for i := range x {
+		x[i] = red.Rows[i][c]
+	}
+	return x, nil
+}
@@ -0,0 +1,215 @@
+package sharing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Polynomium over the field Z_p, f(x) = a_0 + a_1x + ... + a_dx^d. The coefficients are always reduced into the
+// field, and the leading coefficient is never 0 (the zero polynomium has no coefficients).
+type Polynomial struct {
+	Coeffs []int // a_0, a_1, ..., a_d
+	P      int   // Prime
+}
+
+// Errors of polynomium arithmetic
+var (
+	ErrDivisionByZero = errors.New("division by the zero polynomium")
+	ErrDuplicateX     = errors.New("two points with the same x")
+)
+
+// Create the polynomium with the coefficients a_0, a_1, ... over Z_p
+func NewPolynomial(p int, coeffs ...int) Polynomial {
+	f := Polynomial{Coeffs: make([]int, len(coeffs)), P: p}
+	for i, c := range coeffs {
+		f.Coeffs[i] = Pmod(c, p)
+	}
+	return f.trim()
+}
+
+// Drop leading zero coefficients
+func (f Polynomial) trim() Polynomial {
+	d := len(f.Coeffs)
+	for d > 0 && f.Coeffs[d-1] == 0 {
+		d--
+	}
+	f.Coeffs = f.Coeffs[:d]
+	return f
+}
+
+// Degree of the polynomium (-1 for the zero polynomium)
+func (f Polynomial) Degree() int {
+	return len(f.Coeffs) - 1
+}
+
+// Check if it is the zero polynomium
+func (f Polynomial) IsZero() bool {
+	return len(f.Coeffs) == 0
+}
+
+// Coefficient of x^i (0 above the degree)
+func (f Polynomial) Coeff(i int) int {
+	if i < 0 || i >= len(f.Coeffs) {
+		return 0
+	}
+	return f.Coeffs[i]
+}
+
+// Evaluate f(x) with Horner's rule
+func (f Polynomial) Eval(x int) int {
+	x = Pmod(x, f.P)
+	y := 0
+	for i := len(f.Coeffs) - 1; i >= 0; i-- {
+		y = Pmod(y*x+f.Coeffs[i], f.P)
+	}
+	return y
+}
+
+// Compute f + g
+func (f Polynomial) Add(g Polynomial) Polynomial {
+	n := len(f.Coeffs)
+	if len(g.Coeffs) > n {
+		n = len(g.Coeffs)
+	}
+	sum := make([]int, n)
+	for i := range sum {
+		sum[i] = f.Coeff(i) + g.Coeff(i)
+	}
+	return NewPolynomial(f.P, sum...)
+}
+
+// Compute f - g
+func (f Polynomial) Sub(g Polynomial) Polynomial {
+	return f.Add(g.Scale(-1))
+}
+
+// Compute c * f
+func (f Polynomial) Scale(c int) Polynomial {
+	scaled := make([]int, len(f.Coeffs))
+	for i, a := range f.Coeffs {
+		scaled[i] = MulField(a, Pmod(c, f.P), f.P)
+	}
+	return NewPolynomial(f.P, scaled...)
+}
+
+// Compute f * g
+func (f Polynomial) Mul(g Polynomial) Polynomial {
+	if f.IsZero() || g.IsZero() {
+		return NewPolynomial(f.P)
+	}
+	prod := make([]int, len(f.Coeffs)+len(g.Coeffs)-1)
+	for i, a := range f.Coeffs {
+		for j, b := range g.Coeffs {
+			prod[i+j] = SumField(f.P, prod[i+j], MulField(a, b, f.P))
+		}
+	}
+	return NewPolynomial(f.P, prod...)
+}
+
+// Divide f by g, giving the quotient q and the remainder r (f = q * g + r, with deg r < deg g)
+func (f Polynomial) DivMod(g Polynomial) (Polynomial, Polynomial, error) {
+	if g.IsZero() {
+		return Polynomial{}, Polynomial{}, ErrDivisionByZero
+	}
+
+	// Long division, cancelling the leading coefficient of the remainder in every step
+	r := append([]int{}, f.Coeffs...)
+	q := make([]int, 0)
+	if d := len(r) - len(g.Coeffs) + 1; d > 0 {
+		q = make([]int, d)
+	}
+	lead := Inverse(g.Coeffs[len(g.Coeffs)-1], f.P)
+	for i := len(q) - 1; i >= 0; i-- {
+		c := MulField(r[i+len(g.Coeffs)-1], lead, f.P)
+		q[i] = c
+		for j, b := range g.Coeffs {
+			r[i+j] = SubField(r[i+j], MulField(c, b, f.P), f.P)
+		}
+	}
+	return NewPolynomial(f.P, q...), NewPolynomial(f.P, r...), nil
+}
+
+// Check if f and g are the same polynomium
+func (f Polynomial) Equal(g Polynomial) bool {
+	if f.P != g.P || len(f.Coeffs) != len(g.Coeffs) {
+		return false
+	}
+	for i := range f.Coeffs {
+		if f.Coeffs[i] != g.Coeffs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Find the roots of f among the given x-values
+func (f Polynomial) RootsAmong(xs []int) []int {
+	roots := make([]int, 0)
+	for _, x := range xs {
+		if f.Eval(x) == 0 {
+			roots = append(roots, x)
+		}
+	}
+	return roots
+}
+
+// Find every root of f in the field (by trying every element, so only for the small fields of the vote).
+// The zero polynomium has every element as root, which gives nil.
+func (f Polynomial) Roots() []int {
+	if f.IsZero() {
+		return nil
+	}
+	roots := make([]int, 0, f.Degree())
+	for x := 0; x < f.P && len(roots) < f.Degree(); x++ {
+		if f.Eval(x) == 0 {
+			roots = append(roots, x)
+		}
+	}
+	return roots
+}
+
+func (f Polynomial) String() string {
+	if f.IsZero() {
+		return "0"
+	}
+	terms := make([]string, 0, len(f.Coeffs))
+	for i, a := range f.Coeffs {
+		switch {
+		case a == 0:
+		case i == 0:
+			terms = append(terms, fmt.Sprint(a))
+		case i == 1:
+			terms = append(terms, fmt.Sprintf("%vx", a))
+		default:
+			terms = append(terms, fmt.Sprintf("%vx^%v", a, i))
+		}
+	}
+	return strings.Join(terms, " + ")
+}
+
+// Interpolate the polynomium of degree at most len(points)-1 through the points (Lagrange)
+func Interpolate(p int, points []Point) (Polynomial, error) {
+	f := NewPolynomial(p)
+	for i, pi := range points {
+
+		// Basis polynomium delta_i(x) = prod_{j != i} (x - x_j) / (x_i - x_j)
+		basis := NewPolynomial(p, 1)
+		den := 1
+		for j, pj := range points {
+			if i == j {
+				continue
+			}
+			d := SubField(pi.X, pj.X, p)
+			if d == 0 {
+				return Polynomial{}, ErrDuplicateX
+			}
+			basis = basis.Mul(NewPolynomial(p, -pj.X, 1))
+			den = MulField(den, d, p)
+		}
+
+		// Add y_i * delta_i(x)
+		f = f.Add(basis.Scale(DivMod(pi.Y, den, p)))
+	}
+	return f, nil
+}
@@ -0,0 +1,49 @@
+package sharing
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+)
+
+// Source of the randomness of shares. Shares must be unpredictable, so the default is the cryptographically secure
+// generator of the operating system, while a deterministic source is only for test mode and the simulator.
+type RNG interface {
+
+	// A uniformly random integer in [0, n)
+	Intn(n int) int
+}
+
+// Cryptographically secure randomness (crypto/rand)
+type secureRNG struct{}
+
+func (secureRNG) Intn(n int) int {
+	v, err := crand.Int(crand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		panic(fmt.Errorf("no secure randomness: %v", err))
+	}
+	return int(v.Int64())
+}
+
+// The randomness of shares, unless a deterministic source is injected
+var Secure RNG = secureRNG{}
+
+// Deterministic randomness from a seed (for test mode and the simulator only, as its shares are predictable).
+// Not safe for concurrent use, so every party gets its own.
+func Deterministic(seed int64) RNG {
+	return mrand.New(mrand.NewSource(seed))
+}
+
+// Get the given randomness, or the secure randomness if none is given
+func OrSecure(rng RNG) RNG {
+	if rng == nil {
+		return Secure
+	}
+	return rng
+}
+
+// Pick an element of the field uniformly at random (all of 0, ..., p-1)
+func RandomElement(rng RNG, p int) int {
+	return rng.Intn(p)
+}
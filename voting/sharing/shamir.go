@@ -0,0 +1,146 @@
+package sharing
+
+import (
+	"fmt"
+)
+
+// Secrifies the vote 'x' into n shares (one per server), using shamir sharing.
+// @x = The vote (in {0, 1})
+// @p = The prime number to limit Z-field (-p, p)
+// @k = The polynomium degree (amount of corrupt parties we allow)
+// @n = The amount of shares (the share of server i is f(i))
+// @rng = The randomness of the coefficients (sharing.Secure outside of tests and simulations)
+func Secrify(rng RNG, x, p, k, n int) []int {
+
+	// Generate random a-values (uniform over the whole field), f(x) = x + a_1x + ... + a_kx^k
+	as := []int{x}
+	for i := 0; i < k; i++ {
+		ai := RandomElement(rng, p)
+		as = append(as, ai)
+	}
+	f := NewPolynomial(p, as...)
+
+	// Compute shares
+	shares := make([]int, n)
+	for i := range shares {
+		shares[i] = f.Eval(i + 1)
+	}
+
+	// Return
+	return shares
+
+}
+
+// Least amount of servers needed for a tally (points to interpolate a polynomium of degree 1)
+const MIN_SERVERS = 2
+
+// Represents a point in a coordinate system
+type Point struct {
+	X int // X-Value
+	Y int // Y-Value
+}
+
+// Define sort by X for a point slice
+type PointXSort []Point
+
+func (a PointXSort) Len() int           { return len(a) }
+func (a PointXSort) Less(i, j int) bool { return a[i].X < a[j].X }
+func (a PointXSort) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// Computes L(x) in field p given points p (the points must have different x), with the weights of their x-values
+func Lagrange(x, p int, points []Point) int {
+	xs := make([]int, len(points))
+	for i, pt := range points {
+		xs[i] = pt.X
+	}
+	w, err := WeightsFor(p, x, xs)
+	if err != nil {
+		panic(fmt.Errorf("cannot interpolate %v: %v", points, err))
+	}
+	sum := 0
+	for i, pt := range points {
+		sum = SumField(p, sum, MulField(w.W[i], Pmod(pt.Y, p), p))
+	}
+	return sum
+}
+
+// Decodes the polynomium f of degree k through the points, where at most e of them may be wrong (Berlekamp-Welch).
+// Solves Q(x_i) = y_i * E(x_i) for Q of degree k+e and the monic error locator E of degree e, so that f = Q / E and
+// the roots of E are the x of the wrong points. Fewer wrong points than e leave the system singular, so fewer are
+// tried in turn. Returns f and E, or an error if more than e points are wrong.
+func BerlekampWelch(points []Point, k, e, p int) (Polynomial, Polynomial, error) {
+	for errs := e; errs >= 0; errs-- {
+
+		// Decoding errs wrong points needs k+2errs+1 points
+		if len(points) < k+2*errs+1 {
+			continue
+		}
+
+		// Construct system of linear equations, with unknowns q_0, ..., q_k+errs, e_0, ..., e_errs-1
+		A := make([][]int, len(points))
+		B := make([]int, len(points))
+		for i, pt := range points {
+			row := make([]int, 0, k+2*errs+1)
+			for j := 0; j <= k+errs; j++ {
+				row = append(row, PowField(pt.X, j, p))
+			}
+			for j := 0; j < errs; j++ {
+				row = append(row, SubField(0, MulField(pt.Y, PowField(pt.X, j, p), p), p))
+			}
+			A[i] = row
+			B[i] = MulField(pt.Y, PowField(pt.X, errs, p), p)
+		}
+
+		// Solve (singular if fewer points are wrong)
+		sol, err := NewMatrix(p, A).Solve(B)
+		if err == ErrSingular {
+			continue
+		} else if err != nil {
+			return Polynomial{}, Polynomial{}, fmt.Errorf("more than %v wrong point(s): %v", e, err)
+		}
+		Q := NewPolynomial(p, sol[:k+errs+1]...)
+		E := NewPolynomial(p, append(append([]int{}, sol[k+errs+1:]...), 1)...)
+
+		// The error locator must divide Q, and f must agree with all but errs points
+		f, rem, err := Q.DivMod(E)
+		if err != nil || !rem.IsZero() || f.Degree() > k {
+			return Polynomial{}, Polynomial{}, fmt.Errorf("more than %v wrong point(s): E(x) = %v does not divide Q(x) = %v", e, E, Q)
+		}
+		wrong := 0
+		for _, pt := range points {
+			if f.Eval(pt.X) != Pmod(pt.Y, p) {
+				wrong++
+			}
+		}
+		if wrong > errs {
+			return Polynomial{}, Polynomial{}, fmt.Errorf("more than %v wrong point(s): %v points are off f(x) = %v", e, wrong, f)
+		}
+		return f, E, nil
+
+	}
+	return Polynomial{}, Polynomial{}, fmt.Errorf("too few points (%v) to decode a polynomium of degree %v", len(points), k)
+}
+
+// Check if all points are on the polynomium of degree k through the first k+1 points
+func OnPolynomium(points []Point, k, p int) bool {
+	for _, pt := range points[k+1:] {
+		if Lagrange(pt.X, p, points[:k+1]) != pt.Y {
+			return false
+		}
+	}
+	return true
+}
+
+// Find the one point off the polynomium of degree k through the other points.
+// Returns the index of the point (-1 if there is no single such point) and the value the others expect at its X.
+func OffPolynomium(points []Point, k, p int) (int, int) {
+	for i, pt := range points {
+		rest := append(append([]Point{}, points[:i]...), points[i+1:]...)
+		if len(rest) > k && OnPolynomium(rest, k, p) {
+			if expected := Lagrange(pt.X, p, rest[:k+1]); expected != pt.Y {
+				return i, expected
+			}
+		}
+	}
+	return -1, 0
+}
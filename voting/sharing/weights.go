@@ -0,0 +1,138 @@
+package sharing
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Lagrange weights to evaluate the polynomium through points with the given x-values at a fixed x, so that
+// f(x) = w_1 * y_1 + ... + w_n * y_n. They only depend on the x-values (the servers), so they are computed once
+// and every secret shared among the same servers is then opened with a linear combination of its shares.
+type Weights struct {
+	At int   // The x the weights evaluate at (0 to open the secret)
+	Xs []int // The x-values of the points
+	W  []int // The weight of each point
+	P  int   // Prime
+}
+
+// Compute the Lagrange weights at x for the x-values, w_i = prod_{j != i} (x - x_j) / (x_i - x_j).
+// The numerators are taken from prefix and suffix products of (x - x_j), and all denominators are inverted at once.
+func NewWeights(p, at int, xs []int) (Weights, error) {
+	n := len(xs)
+	w := Weights{At: Pmod(at, p), Xs: make([]int, n), W: make([]int, n), P: p}
+	for i, x := range xs {
+		w.Xs[i] = Pmod(x, p)
+	}
+
+	// Denominators prod_{j != i} (x_i - x_j)
+	dens := make([]int, n)
+	for i := range dens {
+		dens[i] = 1
+		for j := range w.Xs {
+			if i == j {
+				continue
+			}
+			d := SubField(w.Xs[i], w.Xs[j], p)
+			if d == 0 {
+				return Weights{}, ErrDuplicateX
+			}
+			dens[i] = MulField(dens[i], d, p)
+		}
+	}
+	invs := BatchInverse(dens, p)
+
+	// Numerators prod_{j != i} (x - x_j) = prefix_i * suffix_i+1
+	prefix := make([]int, n+1)
+	suffix := make([]int, n+1)
+	prefix[0], suffix[n] = 1, 1
+	for i := 0; i < n; i++ {
+		prefix[i+1] = MulField(prefix[i], SubField(w.At, w.Xs[i], p), p)
+		suffix[n-i-1] = MulField(suffix[n-i], SubField(w.At, w.Xs[n-i-1], p), p)
+	}
+	for i := range w.W {
+		w.W[i] = MulField(MulField(prefix[i], suffix[i+1], p), invs[i], p)
+	}
+
+	return w, nil
+}
+
+// Evaluate the polynomium through the points (y_i at x_i, in the order of the x-values) at the x of the weights
+func (w Weights) Combine(ys []int) int {
+	if len(ys) != len(w.W) {
+		panic(fmt.Errorf("%v values for %v weights", len(ys), len(w.W)))
+	}
+	sum := 0
+	for i, y := range ys {
+		sum = SumField(w.P, sum, MulField(w.W[i], Pmod(y, w.P), w.P))
+	}
+	return sum
+}
+
+// Open many secrets shared among the same servers at once, where shares[s] are the shares of secret s
+// (in the order of the x-values)
+func (w Weights) CombineAll(shares [][]int) []int {
+	secrets := make([]int, len(shares))
+	for s, ys := range shares {
+		secrets[s] = w.Combine(ys)
+	}
+	return secrets
+}
+
+// Invert every (non-zero) value with a single inversion (Montgomery's trick): with the prefix products
+// c_i = a_1 * ... * a_i, the inverse of c_n is walked back through a_i^-1 = c_i-1 * c_i^-1 and c_i-1^-1 = a_i * c_i^-1
+func BatchInverse(as []int, p int) []int {
+	n := len(as)
+	invs := make([]int, n)
+	if n == 0 {
+		return invs
+	}
+	prefix := make([]int, n)
+	acc := 1
+	for i, a := range as {
+		prefix[i] = acc
+		acc = MulField(acc, Pmod(a, p), p)
+	}
+	inv := Inverse(acc, p)
+	for i := n - 1; i >= 0; i-- {
+		invs[i] = MulField(inv, prefix[i], p)
+		inv = MulField(inv, Pmod(as[i], p), p)
+	}
+	return invs
+}
+
+// Weights computed so far, by prime, x and x-values (a tally only ever sees a few sets of servers)
+var weightCache = struct {
+	sync.RWMutex
+	weights map[string]Weights
+}{weights: map[string]Weights{}}
+
+// Get the Lagrange weights at x for the x-values, computing them only the first time they are asked for
+func WeightsFor(p, at int, xs []int) (Weights, error) {
+	key := weightKey(p, at, xs)
+	weightCache.RLock()
+	w, exists := weightCache.weights[key]
+	weightCache.RUnlock()
+	if exists {
+		return w, nil
+	}
+	w, err := NewWeights(p, at, xs)
+	if err != nil {
+		return Weights{}, err
+	}
+	weightCache.Lock()
+	weightCache.weights[key] = w
+	weightCache.Unlock()
+	return w, nil
+}
+
+// Key of the weights in the cache, "p|at|x_1,x_2,..."
+func weightKey(p, at int, xs []int) string {
+	buf := make([]byte, 0, 8*(len(xs)+2))
+	buf = append(strconv.AppendInt(buf, int64(p), 10), '|')
+	buf = append(strconv.AppendInt(buf, int64(at), 10), '|')
+	for _, x := range xs {
+		buf = append(strconv.AppendInt(buf, int64(x), 10), ',')
+	}
+	return string(buf)
+}
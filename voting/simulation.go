@@ -0,0 +1,560 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/spdz"
+	"cs.au.dk/voting/tally"
+	"cs.au.dk/voting/tallyserver"
+	"cs.au.dk/voting/voteclient"
+)
+
+// Address all simulated parties listen on
+const SIM_IP = "sim"
+
+// How long simulated servers wait for partners in each phase
+const SIM_PHASE_TIMEOUT = 3 * time.Second
+
+// How often simulated servers send heartbeats (so a dead link is noticed within a second)
+const SIM_HEARTBEAT_INTERVAL = 250 * time.Millisecond
+
+// How long an outage (see SimElection.Outage) lasts
+const SIM_OUTAGE_TIME = 2 * time.Second
+
+// How often voters that hung up after voting ask for the results (see SimElection.Detach)
+const SIM_RESULT_INTERVAL = 500 * time.Millisecond
+
+// Port the simulated registration authority listens on
+const SIM_AUTHORITY_PORT = "9001"
+
+// Configuration of an election run on the simulated network
+type SimElection struct {
+	Seed      int64          // Seed for votes, shares and all network faults
+	Voters    int            // Amount of voters
+	VoteTime  int            // Voting period in seconds
+	P         int            // Prime
+	K         int            // Amount of dishonest servers we prepare for
+	Scheme    scheme.Scheme  // Sharing scheme (the default if nil)
+	Strategy  tally.Strategy // Tally strategy (the default of the scheme if nil)
+	MAC       bool           // Check the tally with SPDZ MACs, dealt to S1-Sn and C1-Cn in-process (additive scheme only)
+	Proofs    bool           // Voters prove their ballots encrypt 0 or 1, and servers require it (elgamal scheme only)
+	Anonymous bool           // Voters join with a credential from a registration authority (RA) instead of their ID
+	Detach    bool           // Voters hang up once they have their receipts, and fetch the results from the servers later
+	Links     string         // Link rules (see SimNetwork.ParseLinkRules)
+	Partition string         // Partition (see SimNetwork.ParsePartition)
+	Verbose   bool           // Log injected faults
+
+	// Byzantine behaviours keyed by ServerID
+	Behaviours map[int][]tallyserver.Behaviour
+
+	// Bad voters keyed by voter number (C1 = 1)
+	BadVoters map[int]VoterMode
+
+	// Folder to keep the blame reports of each server in (S1.json, ...), empty to keep them in memory
+	BlameDir string
+
+	// ServerIDs of servers that are never started
+	Offline []int
+
+	// ServerIDs of servers that crash and restart before the votes are cast
+	Restarts []int
+
+	// ServerIDs of servers that crash once the votes are cast (before the voting period ends)
+	Crashes []int
+
+	// Partition in force for SIM_OUTAGE_TIME once the votes are being cast (see SimNetwork.ParsePartition)
+	Outage string
+}
+
+// Get the sharing scheme of the election
+func (cfg SimElection) SchemeOrDefault() scheme.Scheme {
+	if cfg.Scheme == nil {
+		return scheme.Default()
+	}
+	return cfg.Scheme
+}
+
+// Get the tally strategy of the election
+func (cfg SimElection) StrategyOrDefault() tally.Strategy {
+	if cfg.Strategy == nil {
+		strategy, _ := scheme.StrategyOf(cfg.SchemeOrDefault(), "")
+		return strategy
+	}
+	return cfg.Strategy
+}
+
+// Check if the server with the given ServerID is never started
+func (cfg SimElection) IsOffline(serverID int) bool {
+	for _, id := range cfg.Offline {
+		if id == serverID {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the server with the given ServerID crashes once the votes are cast
+func (cfg SimElection) IsCrashing(serverID int) bool {
+	for _, id := range cfg.Crashes {
+		if id == serverID {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse a comma separated list of ServerIDs (e.g. "1,3") of an election with the given amount of servers
+func ParseServerIDs(list string, servers int) ([]int, error) {
+	ids := make([]int, 0)
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 || id > servers {
+			return nil, fmt.Errorf("invalid ServerID '%s' (must be 1-%v)", v, servers)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Format ServerIDs as a comma separated list
+func FormatServerIDs(ids []int) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.Itoa(id)
+	}
+	return strings.Join(strs, ",")
+}
+
+// Misbehaviour of a simulated voter (see clientVariability.go)
+type VoterMode struct {
+	Mode string `json:"mode"` // The client mode
+	Arg  int    `json:"arg"`  // The argument of the mode (0 = default)
+}
+
+// Check if the servers should count the vote of the voter (when voters join with credentials, the first vote of a
+// double voter is counted, as only its other IDs are refused). A voter recasting its ballot is counted with the vote
+// it cast last (or first, when its recast does not reach every server or it cannot rejoin).
+func (m VoterMode) Counted(credentials bool) bool {
+	if credentials && m.Mode == voteclient.CLIENT_MODE_DOUBLE_VOTE {
+		return true
+	}
+	switch m.Mode {
+	case voteclient.CLIENT_MODE_RECAST, voteclient.CLIENT_MODE_PART_RECAST, voteclient.CLIENT_MODE_IMPOSTOR:
+		return true
+	}
+	return m.Mode == voteclient.CLIENT_MODE_HONEST || m.Mode == voteclient.CLIENT_MODE_FLOOD
+}
+
+// Outcome of a simulated election
+type SimOutcome struct {
+	Expected protocol.Results   // The tally if every vote was counted
+	Results  []protocol.Results // Result of each server (S1, S2, ...)
+	Hung     bool               // True if not all servers produced a result
+	Honest   []bool             // Marks which servers were honest
+	Offline  []bool             // Marks which servers were never started (or crashed)
+	MayAbort bool               // True if bad parties are at most detected by the tally strategy, so honest servers may abort instead
+
+	// Tallies verified by the honest voters (keyed by voter name)
+	Verified map[string]VoterTally
+
+	// Blame reports made by each server
+	Blames [][]protocol.BlameReport
+}
+
+// Tally a voter verified from the published points
+type VoterTally struct {
+	Name  string           // The voter
+	Tally protocol.Results // The verified tally
+	Liars []int            // ServerIDs of the servers the voter caught lying
+	Drops []int            // ServerIDs of the servers that gave no receipt of its ballot, or did not tally it
+}
+
+// Check if every honest server (and every voter who verified the tally) agrees on the expected tally
+func (o SimOutcome) Passed() bool {
+	if o.Hung || len(o.Results) == 0 {
+		return false
+	}
+	for i, r := range o.Results {
+		if !o.Honest[i] || o.Offline[i] || (o.MayAbort && r.Error) {
+			continue
+		}
+		if !r.SameTally(o.Expected) {
+			return false
+		}
+	}
+	for _, v := range o.Verified {
+		if !v.Tally.SameTally(o.Expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// Runs a full election (all servers and a set of voters) in-process on a simulated network.
+// Servers are named S1-Sn (n given by the scheme) and voters C1-Cn, which are the names used in link rules.
+// The same configuration and seed always injects the same faults.
+func RunSimulatedElection(cfg SimElection) SimOutcome {
+
+	// Create network
+	network := protocol.NewSimNetwork(cfg.Seed)
+	network.Verbose = cfg.Verbose
+	if e := network.ParseLinkRules(cfg.Links); e != nil {
+		panic(e)
+	}
+	if e := network.ParsePartition(cfg.Partition); e != nil {
+		panic(e)
+	}
+
+	// Seed the randomness of bad parties and pick votes (every voter draws its shares from its own seeded source)
+	rand.Seed(cfg.Seed)
+	voteRand := rand.New(rand.NewSource(cfg.Seed))
+
+	// Spawn servers (in order, so the logs read the same every run)
+	sch, strategy := cfg.SchemeOrDefault(), cfg.StrategyOrDefault()
+
+	// Deal the MAC keys and the masks of the voters (as the trusted dealer would, before the election)
+	var keys []spdz.ServerKey
+	masks := map[string]*spdz.VoterKey{}
+	if cfg.MAC {
+		if !scheme.SupportsMACs(sch) {
+			panic(fmt.Errorf("the %s scheme cannot be MAC checked", sch.Name()))
+		}
+		names := make([]string, cfg.Voters)
+		for i := range names {
+			names[i] = fmt.Sprintf("C%v", i+1)
+		}
+		var voterKeys []spdz.VoterKey
+		keys, voterKeys = spdz.Deal(sharing.Deterministic(cfg.Seed), sch.Servers(), cfg.P, names)
+		for i := range voterKeys {
+			masks[voterKeys[i].Name] = &voterKeys[i]
+		}
+	}
+
+	// Start the registration authority, issuing a credential to every voter (C1-Cn)
+	var authority *credential.PublicKey
+	if cfg.Anonymous {
+		if cfg.MAC {
+			panic(fmt.Errorf("credentials cannot be used with MAC checked tallies"))
+		}
+		key, err := credential.GenerateKey(nil, credential.KEY_BITS)
+		if err != nil {
+			panic(err)
+		}
+		names := make([]string, cfg.Voters)
+		for i := range names {
+			names[i] = fmt.Sprintf("C%v", i+1)
+		}
+		ra := credential.NewAuthority("RA", key, names)
+		ra.Transport = network.Endpoint("RA")
+		if err := ra.Start(SIM_IP, SIM_AUTHORITY_PORT); err != nil {
+			panic(err)
+		}
+		defer ra.Stop()
+		authority = &key.PublicKey
+	}
+	// Publish the keys the servers sign receipts with (the voters pin them)
+	receiptKeys, err := protocol.GenerateReceiptKeys(rand.New(rand.NewSource(cfg.Seed)), sch.Servers())
+	if err != nil {
+		panic(err)
+	}
+	pinned := make([]ed25519.PublicKey, len(receiptKeys))
+	for i, key := range receiptKeys {
+		pinned[i] = key.Public().(ed25519.PublicKey)
+	}
+
+	servers := make([]*tallyserver.Server, sch.Servers())
+	clientPorts := make([]string, len(servers))
+	partnerPorts := make([]string, len(servers)-1)
+	for i := range servers {
+		clientPorts[i] = fmt.Sprint(10001 + i)
+		if i < len(partnerPorts) {
+			partnerPorts[i] = fmt.Sprint(11001 + i)
+		}
+	}
+	spawn := func(i int) {
+		partnerPorts := partnerPorts
+		if i == 0 {
+			partnerPorts = partnerPorts[:1]
+		}
+		blames := &tallyserver.BlameStore{}
+		if cfg.BlameDir != "" {
+			store, err := tallyserver.LoadBlameStore(filepath.Join(cfg.BlameDir, fmt.Sprintf("S%v.json", i+1)))
+			if err != nil {
+				panic(err)
+			}
+			blames = store
+		}
+		var key *spdz.ServerKey
+		if keys != nil {
+			key = &keys[i]
+		}
+		servers[i] = tallyserver.New(
+			tallyserver.WithID(i+1, fmt.Sprintf("S%v", i+1)),
+			tallyserver.WithListen(SIM_IP, clientPorts[i]),
+			tallyserver.WithPartners([]string{SIM_IP}, partnerPorts),
+			tallyserver.WithVoteTime(cfg.VoteTime),
+			tallyserver.WithPrime(cfg.P),
+			tallyserver.WithScheme(sch),
+			tallyserver.WithStrategy(strategy),
+			tallyserver.WithDegree(cfg.K),
+			tallyserver.WithMACKey(key),
+			tallyserver.WithProofs(cfg.Proofs),
+			tallyserver.WithAuthority(authority),
+			tallyserver.WithReceiptKey(receiptKeys[i]),
+			tallyserver.WithTransport(network.Endpoint(fmt.Sprintf("S%v", i+1))),
+			tallyserver.WithPhaseTimeout(SIM_PHASE_TIMEOUT),
+			tallyserver.WithHeartbeatInterval(SIM_HEARTBEAT_INTERVAL),
+			tallyserver.WithBlames(blames),
+			tallyserver.WithBehaviours(cfg.Behaviours[i+1]...),
+		)
+		if err := servers[i].Start(); err != nil {
+			panic(err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	for i := range servers {
+		if !cfg.IsOffline(i + 1) {
+			spawn(i)
+		}
+	}
+
+	// Crash and restart servers (which must rejoin the running election)
+	for _, id := range cfg.Restarts {
+		if servers[id-1] == nil {
+			continue
+		}
+		time.Sleep(500 * time.Millisecond)
+		fmt.Printf("\033[33m@@@ SIMULATION: Restarting S%v\033[0m\n", id)
+		servers[id-1].Stop()
+		time.Sleep(200 * time.Millisecond)
+		spawn(id - 1)
+	}
+
+	// Collect results
+	resultChan := make(chan struct {
+		i int
+		r protocol.Results
+	}, len(servers))
+	online := 0
+	for i, s := range servers {
+		if s == nil || cfg.IsCrashing(i+1) {
+			continue
+		}
+		online++
+		go func(i int, s *tallyserver.Server) {
+			resultChan <- struct {
+				i int
+				r protocol.Results
+			}{i, s.WaitForResults()}
+		}(i, s)
+	}
+
+	// Let servers settle before voting
+	time.Sleep(500 * time.Millisecond)
+
+	// Cut the network for a while (healing back to the permanent partition)
+	if cfg.Outage != "" {
+		if e := network.ParsePartition(cfg.Outage); e != nil {
+			panic(e)
+		}
+		time.AfterFunc(SIM_OUTAGE_TIME, func() {
+			network.Heal()
+			network.ParsePartition(cfg.Partition)
+		})
+	}
+
+	// Cast votes
+	outcome := SimOutcome{Results: make([]protocol.Results, len(servers)), Honest: make([]bool, len(servers)), Offline: make([]bool, len(servers)), Verified: map[string]VoterTally{}}
+	verifiedChan := make(chan VoterTally, cfg.Voters)
+	honestVoters := 0
+	for i := range servers {
+		outcome.Honest[i] = len(cfg.Behaviours[i+1]) == 0
+		outcome.Offline[i] = servers[i] == nil || cfg.IsCrashing(i+1)
+		if !outcome.Honest[i] {
+			outcome.MayAbort = !strategy.Corrects()
+		}
+	}
+	if len(cfg.BadVoters) > 0 && !strategy.Corrects() {
+		outcome.MayAbort = true
+	}
+	for i := 0; i < cfg.Voters; i++ {
+		vote := voteRand.Intn(2)
+		mode := cfg.BadVoters[i+1]
+		if mode.Counted(cfg.Anonymous) {
+			outcome.Expected.Yes += vote
+			outcome.Expected.No += 1 - vote
+		}
+		if mode.Mode == voteclient.CLIENT_MODE_HONEST {
+			honestVoters++
+			SimulateVoter(network, fmt.Sprintf("C%v", i+1), SIM_IP, clientPorts, sch, strategy, masks[fmt.Sprintf("C%v", i+1)], sharing.Deterministic(cfg.Seed+int64(i+1)), authority, pinned, cfg.Proofs, cfg.Detach, vote, cfg.P, cfg.K, mode, verifiedChan)
+		} else {
+			// Bad voters may take their time, so don't hold up the rest
+			go SimulateVoter(network, fmt.Sprintf("C%v", i+1), SIM_IP, clientPorts, sch, strategy, masks[fmt.Sprintf("C%v", i+1)], sharing.Deterministic(cfg.Seed+int64(i+1)), authority, pinned, cfg.Proofs, cfg.Detach, vote, cfg.P, cfg.K, mode, nil)
+		}
+	}
+
+	// Crash servers before the voting period ends (the rest must close the voting without them)
+	for _, id := range cfg.Crashes {
+		if servers[id-1] != nil {
+			fmt.Printf("\033[33m@@@ SIMULATION: Crashing S%v\033[0m\n", id)
+			servers[id-1].Stop()
+		}
+	}
+
+	// Wait for results (or give up)
+	timeout := time.After(time.Duration(cfg.VoteTime)*time.Second + 20*time.Second)
+	for i := 0; i < online; i++ {
+		select {
+		case r := <-resultChan:
+			outcome.Results[r.i] = r.r
+		case <-timeout:
+			outcome.Hung = true
+		}
+		if outcome.Hung {
+			break
+		}
+	}
+
+	// Give the honest voters a moment to verify the tally
+	verifyTimeout := time.After(3 * time.Second)
+	for waiting := !outcome.Hung; waiting && len(outcome.Verified) < honestVoters; {
+		select {
+		case v := <-verifiedChan:
+			outcome.Verified[v.Name] = v
+		case <-verifyTimeout:
+			waiting = false
+		}
+	}
+
+	// Stop servers (and collect their blame reports)
+	outcome.Blames = make([][]protocol.BlameReport, len(servers))
+	for i, s := range servers {
+		if s != nil {
+			s.Halt()
+			outcome.Blames[i] = s.BlameReports()
+		}
+	}
+
+	return outcome
+
+}
+
+// Runs a single voter on the simulated network (reporting the verified tally on the channel, if any)
+func SimulateVoter(network *protocol.SimNetwork, name, ip string, ports []string, sch scheme.Scheme, strategy tally.Strategy, mask *spdz.VoterKey, rng sharing.RNG, authority *credential.PublicKey, receiptKeys []ed25519.PublicKey, prove, detach bool, vote, p, k int, mode VoterMode, verified chan VoterTally) {
+
+	// Connection failures make the client panic, which we only want to log here
+	defer RecoverVoter(name)
+
+	// Create client on its own endpoint
+	client := new(voteclient.Client)
+	client.Transport = network.Endpoint(name)
+	client.Scheme = sch
+	client.Strategy = strategy
+	client.Mask = mask
+	client.RNG = rng
+	client.Prove = prove
+	client.ReceiptKeys = receiptKeys
+	client.Mode = mode.Mode
+	client.ModeArg = mode.Arg
+	if scheme.Encrypts(sch) {
+		client.Entry = rng.Intn(len(ports)) // Spreads the voters over the servers they vote at
+	}
+	if authority != nil && !client.Register(name, ip, SIM_AUTHORITY_PORT, authority, mode.Mode != voteclient.CLIENT_MODE_HONEST) {
+		return
+	}
+	if client.Init(name, []string{ip}, ports, p, k, mode.Mode != voteclient.CLIENT_MODE_HONEST) == nil {
+		client.SendVote(vote)
+		go func() {
+			defer RecoverVoter(name)
+			client.Shutdown(!detach)
+			// Ask for the results until the servers tallied (the servers are stopped soon after)
+			for detach && client.Verified == nil {
+				time.Sleep(SIM_RESULT_INTERVAL)
+				if client.FetchResults(); client.Code != protocol.ERR_TALLY_PENDING {
+					break
+				}
+			}
+			if verified != nil && client.Verified != nil {
+				verified <- VoterTally{Name: name, Tally: *client.Verified, Liars: client.Liars, Drops: client.Dropped}
+			}
+		}()
+	}
+
+}
+
+// Logs a crashed voter instead of taking down the whole simulation
+func RecoverVoter(name string) {
+	if e := recover(); e != nil {
+		fmt.Printf("[%s] Voter crashed: %v\n", name, e)
+	}
+}
+
+// Runs a simulated election and reports how to reproduce it
+func RunSimulation(cfg SimElection) bool {
+	return RunAndReportSimulation(cfg).Passed()
+}
+
+// Runs a simulated election, logs the outcome and returns it
+func RunAndReportSimulation(cfg SimElection) SimOutcome {
+
+	// Log what we're doing
+	macs := ""
+	if cfg.MAC {
+		macs = ", MAC checked"
+	}
+	if cfg.Proofs {
+		macs += ", ballots proven"
+	}
+	if cfg.Anonymous {
+		macs += ", voters registered by an authority"
+	}
+	if cfg.Detach {
+		macs += ", voters hanging up after voting"
+	}
+	fmt.Printf("--- Simulating %s election (tallied with %s%s) with seed %v ---\n", cfg.SchemeOrDefault().Name(), cfg.StrategyOrDefault().Name(), macs, cfg.Seed)
+
+	// Run
+	outcome := RunSimulatedElection(cfg)
+
+	// Log results
+	fmt.Println()
+	fmt.Printf("\033[33m@@@ SIMULATION: Expected %+v, got:\033[0m\n", outcome.Expected)
+	for i, r := range outcome.Results {
+		if outcome.Offline[i] {
+			fmt.Printf("\033[33m\tS%v = offline\033[0m\n", i+1)
+		} else if outcome.Honest[i] {
+			fmt.Printf("\033[33m\tS%v = %+v\033[0m\n", i+1, r)
+		} else {
+			fmt.Printf("\033[33m\tS%v = %+v (bad server)\033[0m\n", i+1, r)
+		}
+	}
+	if outcome.Hung {
+		fmt.Printf("\033[31mNot all servers produced a result.\033[0m\n")
+	}
+	fmt.Printf("\033[33m\t%v voter(s) verified the tally\033[0m\n", len(outcome.Verified))
+	for _, v := range outcome.Verified {
+		if !v.Tally.SameTally(outcome.Expected) {
+			fmt.Printf("\033[31m\t%s verified %+v\033[0m\n", v.Name, v.Tally)
+		}
+	}
+	if !outcome.Passed() {
+		fmt.Printf("\033[31mReproduce with: -mode sim -scheme %s -servers %v -tally %s -mac=%v -proof=%v -anonymous=%v -detach=%v -s %v -voters %v -t %v -links \"%s\" -partition \"%s\" -offline \"%s\" -restart \"%s\" -crash \"%s\" -outage \"%s\"\033[0m\n", cfg.SchemeOrDefault().Name(), cfg.SchemeOrDefault().Servers(), cfg.StrategyOrDefault().Name(), cfg.MAC, cfg.Proofs, cfg.Anonymous, cfg.Detach, cfg.Seed, cfg.Voters, cfg.VoteTime, cfg.Links, cfg.Partition, FormatServerIDs(cfg.Offline), FormatServerIDs(cfg.Restarts), FormatServerIDs(cfg.Crashes), cfg.Outage)
+	}
+	fmt.Println()
+
+	return outcome
+
+}
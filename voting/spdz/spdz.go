@@ -0,0 +1,170 @@
+package spdz
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cs.au.dk/voting/sharing"
+)
+
+// SPDZ-style information-theoretic MACs on additive shares. A trusted dealer shares a global MAC key alpha among the
+// servers (alpha = alpha_1 + ... + alpha_n), and a random mask r for every voter together with shares of its MAC
+// (m_1 + ... + m_n = alpha * r). The voter is told r, and sends the masked vote e = x - r to every server, which turns
+// it into a share of the vote (server 1 adds e to its share of r) and a share of its MAC (m_i + alpha_i * e).
+// Sums of shares keep their MACs, so once the sum y is opened, the servers check sigma_1 + ... + sigma_n = 0 where
+// sigma_i = M_i - alpha_i * y. A server altering its R-sum would have to guess alpha to pass the check.
+
+// Share of the mask of a voter, and of its MAC
+type Mask struct {
+	R int `json:"r"`
+	M int `json:"m"`
+}
+
+// Key material the dealer gives a server
+type ServerKey struct {
+	ServerID int             `json:"server_id"` // ServerID of the server
+	P        int             `json:"p"`         // Prime
+	Alpha    int             `json:"alpha"`     // Share of the global MAC key
+	Masks    map[string]Mask `json:"masks"`     // Shares of the mask of each voter (by voter ID)
+}
+
+// Mask the dealer gives a voter
+type VoterKey struct {
+	Name string `json:"name"` // Voter ID
+	P    int    `json:"p"`    // Prime
+	R    int    `json:"r"`    // The mask
+}
+
+// Deal the MAC key and the masks of the voters among the servers with the randomness (the trusted dealer)
+func Deal(rng sharing.RNG, servers, p int, voters []string) ([]ServerKey, []VoterKey) {
+
+	// Share the global MAC key
+	alpha := sharing.RandomElement(rng, p)
+	keys := make([]ServerKey, servers)
+	for i, a := range sharing.AdditiveSecrify(rng, alpha, p, servers) {
+		keys[i] = ServerKey{ServerID: i + 1, P: p, Alpha: a, Masks: map[string]Mask{}}
+	}
+
+	// Share a mask and its MAC for every voter
+	masks := make([]VoterKey, len(voters))
+	for j, voter := range voters {
+		r := sharing.RandomElement(rng, p)
+		rs := sharing.AdditiveSecrify(rng, r, p, servers)
+		ms := sharing.AdditiveSecrify(rng, sharing.MulField(alpha, r, p), p, servers)
+		for i := range keys {
+			keys[i].Masks[voter] = Mask{R: rs[i], M: ms[i]}
+		}
+		masks[j] = VoterKey{Name: voter, P: p, R: r}
+	}
+
+	return keys, masks
+
+}
+
+// Mask the vote (what the voter sends to every server)
+func (k VoterKey) Mask(vote int) int {
+	return sharing.Pmod(vote-k.R, k.P)
+}
+
+// Turn the masked vote of a voter into our share of the vote and of its MAC (false if no mask was dealt for the voter)
+func (k ServerKey) Input(voter string, masked int) (int, int, bool) {
+	mask, exists := k.Masks[voter]
+	if !exists {
+		return 0, 0, false
+	}
+	share := mask.R
+	if k.ServerID == 1 {
+		share = sharing.Pmod(share+masked, k.P)
+	}
+	mac := sharing.Pmod(mask.M+sharing.MulField(k.Alpha, sharing.Pmod(masked, k.P), k.P), k.P)
+	return share, mac, true
+}
+
+// Our share of the MAC check of the opened value, from our share of its MAC
+func (k ServerKey) Sigma(mac, opened int) int {
+	return sharing.SubField(mac, sharing.MulField(k.Alpha, sharing.Pmod(opened, k.P), k.P), k.P)
+}
+
+// Check the shares of the MAC check of all servers add up to 0
+func Check(sigmas []int, p int) bool {
+	return sharing.SumField(p, sigmas...) == 0
+}
+
+// Commit to our share of the MAC check (so no server can pick its share after seeing the others)
+func Commit(sigma int) (string, string) {
+	nonce := make([]byte, 16)
+	if _, e := rand.Read(nonce); e != nil {
+		panic(e)
+	}
+	return commitment(sigma, hex.EncodeToString(nonce)), hex.EncodeToString(nonce)
+}
+
+// Check the share of the MAC check opens the commitment
+func Opens(commit string, sigma int, nonce string) bool {
+	return commitment(sigma, nonce) == commit
+}
+
+func commitment(sigma int, nonce string) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%d|%s", sigma, nonce)))
+	return hex.EncodeToString(h[:])
+}
+
+// Write the dealt keys to a folder (S1.json, ... for the servers and voter-{ID}.json for the voters)
+func Save(dir string, keys []ServerKey, voters []VoterKey) error {
+	if e := os.MkdirAll(dir, 0700); e != nil {
+		return e
+	}
+	for _, k := range keys {
+		if e := write(filepath.Join(dir, fmt.Sprintf("S%v.json", k.ServerID)), k); e != nil {
+			return e
+		}
+	}
+	for _, v := range voters {
+		if e := write(filepath.Join(dir, fmt.Sprintf("voter-%s.json", v.Name)), v); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// Load the key of the server with the given ServerID from the folder
+func LoadServerKey(dir string, serverID int) (*ServerKey, error) {
+	key := new(ServerKey)
+	if e := read(filepath.Join(dir, fmt.Sprintf("S%v.json", serverID)), key); e != nil {
+		return nil, e
+	}
+	return key, nil
+}
+
+// Load the mask of the voter from the folder
+func LoadVoterKey(dir, name string) (*VoterKey, error) {
+	key := new(VoterKey)
+	if e := read(filepath.Join(dir, fmt.Sprintf("voter-%s.json", name)), key); e != nil {
+		return nil, e
+	}
+	return key, nil
+}
+
+func write(path string, v interface{}) error {
+	data, e := json.MarshalIndent(v, "", "    ")
+	if e != nil {
+		return e
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func read(path string, v interface{}) error {
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return e
+	}
+	if e := json.Unmarshal(data, v); e != nil {
+		return fmt.Errorf("invalid key file '%s': %v", path, e)
+	}
+	return nil
+}
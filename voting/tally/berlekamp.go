@@ -0,0 +1,145 @@
+package tally
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// Corrects the lying servers using the Berlekamp-Welch decoder (error correction), which decodes the polynomium of
+// degree k with up to (n-k-1)/2 wrong points among n. With too few points to correct one, errors can only be detected
+// (k+2 points), or not at all (k+1 points).
+type BerlekampWelch struct{}
+
+func (BerlekampWelch) Name() string   { return BERLEKAMP_WELCH }
+func (BerlekampWelch) Corrects() bool { return true }
+
+func (s BerlekampWelch) Tally(points []sharing.Point, params Params) (Result, Diagnostic) {
+	name, p, k := params.Name, params.P, params.K
+	diag := Diagnostic{Strategy: BERLEKAMP_WELCH}
+
+	// The amount of wrong points we can correct (none if servers went offline, leaving too few points)
+	e := (len(points) - k - 1) / 2
+	if e < 1 {
+		return s.degradedTally(points, params)
+	}
+
+	// Log points
+	fmt.Printf("[%s] My points for decoding are: %v (correcting up to %v error(s)).\n", name, points, e)
+
+	// Points outside the field are wrong for sure, so we decode the rest (with fewer errors left to correct)
+	inside := points
+	outside := make([]sharing.Point, 0)
+	if ok, out := AllInField(points, p); !ok {
+		logOutside(name, points, out)
+		if len(out) > e {
+			fmt.Printf("[%s] \033[31mDetected too many points outside field and aborting!\033[0m\n", name)
+			diag.Note = "too many points outside the field"
+			return Result{Code: protocol.ERR_POINTS_OUTSIDE_FIELD}, diag
+		}
+		inside = make([]sharing.Point, 0, len(points)-len(out))
+		for i, pt := range points {
+			if len(out) > 0 && out[0] == i {
+				outside, out = append(outside, pt), out[1:]
+			} else {
+				inside = append(inside, pt)
+			}
+		}
+	} else {
+		fmt.Printf("[%s] \033[32mAll points are in the field\033[0m\n", name)
+	}
+
+	// Without errors left to correct, the points inside the field must all be on one polynomium
+	left := (len(inside) - k - 1) / 2
+	if left < 1 && len(inside) >= k+2 && !sharing.OnPolynomium(inside, k, p) {
+		fmt.Printf("[%s] Error - failed to recover, more corrupt servers than expected.\n", name)
+		diag.Note = "off the polynomium besides the points outside the field"
+		return Result{Code: protocol.ERR_UNRECOVERABLE}, diag
+	} else if left < 0 {
+		left = 0
+	}
+
+	// Decode the polynomium
+	f, E, err := sharing.BerlekampWelch(inside, k, left, p)
+	if err == nil && len(E.RootsAmong(xsOf(inside))) != E.Degree() {
+		err = fmt.Errorf("invalid error locator %v", E) // Its roots must be the X of wrong points
+	}
+	if err != nil {
+		fmt.Printf("[%s] Error - failed to recover from error, %v\n", name, err)
+		diag.Note = fmt.Sprintf("decoding failed: %v", err)
+		return Result{Code: protocol.ERR_CORRECTION_FAILED}, diag
+	}
+
+	// Blame the points outside the field, and the points off the decoded polynomium
+	blames := make([]Blame, 0)
+	for _, pt := range outside {
+		blames = append(blames, Blame{Point: pt, Expected: f.Eval(pt.X), Method: protocol.BLAME_OUTSIDE_FIELD})
+	}
+	for _, pt := range inside {
+		if y := f.Eval(pt.X); y != pt.Y {
+			fmt.Printf("[%s] \033[31mError - Point %v is not a point on polynomium, got f(%v)=%v. Correcting it.\033[0m\n", name, pt, pt.X, y)
+			blames = append(blames, Blame{Point: pt, Expected: y, Method: protocol.BLAME_OFF_POLYNOMIUM})
+		} else {
+			diag.Interpolated = append(diag.Interpolated, pt)
+		}
+	}
+	if len(blames) > 0 {
+		diag.Note = "corrected by decoding"
+	} else {
+		diag.Note = "all points on the polynomium"
+	}
+
+	return Result{Yes: f.Eval(0), Blames: blames}, diag
+
+}
+
+// Tally with too few points to correct an error (some servers went offline).
+// Errors can then only be detected (k+2 points), or not at all (k+1 points).
+func (BerlekampWelch) degradedTally(points []sharing.Point, params Params) (Result, Diagnostic) {
+	name, p, k := params.Name, params.P, params.K
+	diag := Diagnostic{Strategy: BERLEKAMP_WELCH, Interpolated: points}
+
+	// Log points
+	fmt.Printf("[%s] \033[33mOnly %v points for lagrange interpolation: %v, errors cannot be corrected.\033[0m\n", name, len(points), points)
+
+	// Verify all fall within field (there are not enough points left to do without one)
+	if inside, e := AllInField(points, p); !inside {
+		for _, v := range e {
+			fmt.Printf("[%s] \033[31mPoint %v is outside the field and is invalid!\033[0m\n", name, points[v])
+		}
+		diag.Note = "points outside the field"
+		return Result{Code: protocol.ERR_POINTS_OUTSIDE_FIELD}, diag
+	}
+
+	// Cannot interpolate with fewer points than the degree
+	if len(points) < k+1 {
+		diag.Note = "too few points to interpolate"
+		return Result{Code: protocol.ERR_TOO_FEW_SERVERS}, diag
+	}
+
+	// With a point more than the degree, the rest must be on the polynomium of the first points
+	if len(points) < k+2 {
+		fmt.Printf("[%s] \033[33mOnly %v points, so errors cannot be detected in this tally.\033[0m\n", name, len(points))
+		diag.Note = "too few points to check, unchecked"
+	} else if !sharing.OnPolynomium(points, k, p) {
+		fmt.Printf("[%s] \033[31mError - The points %v are not on one polynomium.\033[0m\n", name, points)
+		diag.Note = "too few points to correct, off the polynomium"
+		return Result{Code: protocol.ERR_NOT_ON_POLYNOMIUM}, diag
+	} else {
+		diag.Note = "too few points to correct, all on the polynomium"
+	}
+
+	// Get votes
+	return Result{Yes: sharing.Lagrange(0, p, points[:k+1])}, diag
+
+}
+
+// The X of the points
+func xsOf(points []sharing.Point) []int {
+	xs := make([]int, len(points))
+	for i, pt := range points {
+		xs[i] = pt.X
+	}
+	return xs
+}
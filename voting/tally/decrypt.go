@@ -0,0 +1,94 @@
+package tally
+
+import (
+	"fmt"
+	"sort"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// The sum one group of K+1 servers decrypted the votes to
+type Decryption struct {
+	Group []int // ServerIDs of the servers in the group
+	Sum   int   // The sum the group decrypted to (-1 if it decrypted to no sum of the votes)
+}
+
+// A strategy settling the sum of encrypted votes from the decryptions of the groups of servers, as there are no
+// R-sum points to reconstruct it from
+type Decrypter interface {
+	Strategy
+
+	// Settle on the sum of the votes from the decryptions, telling how it was done in the diagnostic
+	TallyDecryptions(decryptions []Decryption, params Params) (Result, Diagnostic)
+}
+
+// Settles the sum of encrypted votes from the threshold decryptions of every group of K+1 servers. The partial
+// decryptions are proven before they are combined, so the groups should all agree; a server found in no group
+// decrypting to a sum is blamed, and disagreeing groups fail the tally.
+type Decrypt struct{}
+
+func (Decrypt) Name() string   { return DECRYPT }
+func (Decrypt) Corrects() bool { return true }
+
+// The votes are not shared, so there are no points to tally (see TallyDecryptions)
+func (Decrypt) Tally(points []sharing.Point, params Params) (Result, Diagnostic) {
+	fmt.Printf("[%s] \033[31mThe %s strategy tallies decryptions, not points.\033[0m\n", params.Name, DECRYPT)
+	return Result{Code: protocol.ERR_DECRYPTION_FAILED}, Diagnostic{Strategy: DECRYPT, Note: "no decryptions to tally"}
+}
+
+func (Decrypt) TallyDecryptions(decryptions []Decryption, params Params) (Result, Diagnostic) {
+	diag := Diagnostic{Strategy: DECRYPT}
+
+	// Find the sums the groups decrypted to, and the servers taking part in an honest group
+	sums := map[int]int{}
+	servers := map[int]bool{}
+	honest := map[int]bool{}
+	for _, d := range decryptions {
+		for _, id := range d.Group {
+			servers[id] = true
+			if d.Sum >= 0 {
+				honest[id] = true
+			}
+		}
+		if d.Sum >= 0 {
+			sums[d.Sum]++
+		}
+	}
+	if len(sums) == 0 {
+		fmt.Printf("[%s] \033[31mNo group of %v servers decrypted the votes.\033[0m\n", params.Name, params.K+1)
+		diag.Note = "no group decrypted"
+		return Result{Code: protocol.ERR_DECRYPTION_FAILED}, diag
+	}
+
+	// Honest groups cannot disagree (more than K servers lied together)
+	if len(sums) > 1 {
+		decrypted := make([]int, 0, len(sums))
+		for sum := range sums {
+			decrypted = append(decrypted, sum)
+		}
+		sort.Ints(decrypted)
+		fmt.Printf("[%s] \033[31mThe groups decrypted to different sums %v.\033[0m\n", params.Name, decrypted)
+		diag.Note = fmt.Sprintf("groups disagree on %v", decrypted)
+		return Result{Code: protocol.ERR_DECRYPTION_FAILED}, diag
+	}
+	result := Result{}
+	for sum := range sums {
+		result.Yes = sum
+	}
+
+	// Blame the servers found in no honest group (in order of ServerID)
+	ids := make([]int, 0, len(servers))
+	for id := range servers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		if !honest[id] {
+			result.Blames = append(result.Blames, Blame{Point: sharing.Point{X: id, Y: -1}, Expected: -1, Method: protocol.BLAME_DECRYPTION})
+		}
+	}
+	diag.Note = fmt.Sprintf("%v of %v group(s) decrypted", sums[result.Yes], len(decryptions))
+	return result, diag
+
+}
@@ -0,0 +1,23 @@
+package tally
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/sharing"
+)
+
+// Interpolates whatever points there are (no error detection)
+type Interpolate struct{}
+
+func (Interpolate) Name() string   { return INTERPOLATE }
+func (Interpolate) Corrects() bool { return false }
+
+func (Interpolate) Tally(points []sharing.Point, params Params) (Result, Diagnostic) {
+
+	// Log points
+	fmt.Printf("[%s] My points for lagrange interpolation is: %v.\n", params.Name, points)
+
+	// Get (yes) votes
+	return Result{Yes: sharing.Lagrange(0, params.P, points)}, Diagnostic{Strategy: INTERPOLATE, Interpolated: points, Note: "interpolated, unchecked"}
+
+}
@@ -0,0 +1,121 @@
+package tally
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// Interpolates every subset of k+1 points, and takes the sum most subsets agree on. The points off the polynomium of
+// a winning subset are blamed. If no sum wins outright, the lie is detected but cannot be corrected.
+type Majority struct{}
+
+func (Majority) Name() string   { return MAJORITY }
+func (Majority) Corrects() bool { return true }
+
+func (Majority) Tally(points []sharing.Point, params Params) (Result, Diagnostic) {
+	name, p, k := params.Name, params.P, params.K
+	diag := Diagnostic{Strategy: MAJORITY}
+	blames := make([]Blame, 0)
+
+	// Log points
+	fmt.Printf("[%s] My points for lagrange interpolation is: %v.\n", name, points)
+
+	// Points outside the field cannot be on the polynomium, so leave them out (and blame them once we know the polynomium)
+	inField := points
+	if inside, e := AllInField(points, p); !inside {
+		logOutside(name, points, e)
+		inField = make([]sharing.Point, 0, len(points))
+		outside := map[int]bool{}
+		for _, v := range e {
+			outside[v] = true
+		}
+		for i, pt := range points {
+			if !outside[i] {
+				inField = append(inField, pt)
+			}
+		}
+	}
+	if len(inField) < k+1 {
+		fmt.Printf("[%s] \033[31mOnly %v point(s) in the field, cannot interpolate.\033[0m\n", name, len(inField))
+		diag.Note = "too few points in the field"
+		return Result{Code: protocol.ERR_POINTS_OUTSIDE_FIELD}, diag
+	}
+
+	// Interpolate every subset (remembering a subset for each sum)
+	votes := map[int]int{}
+	subsetOf := map[int][]sharing.Point{}
+	subsets := 0
+	forEachSubset(inField, k+1, func(subset []sharing.Point) {
+		sum := sharing.Lagrange(0, p, subset)
+		votes[sum]++
+		subsets++
+		if subsetOf[sum] == nil {
+			subsetOf[sum] = append([]sharing.Point{}, subset...)
+		}
+	})
+
+	// Find the sum of most subsets, which must win outright
+	best, bestCount, runnerUp := 0, 0, 0
+	for sum, count := range votes {
+		if count > bestCount || (count == bestCount && sum < best) {
+			best, runnerUp = sum, bestCount
+			bestCount = count
+		} else if count > runnerUp {
+			runnerUp = count
+		}
+	}
+	fmt.Printf("[%s] %v of %v subset(s) agree on the sum %v.\n", name, bestCount, subsets, best)
+	diag.Interpolated = subsetOf[best]
+	if bestCount == runnerUp {
+		fmt.Printf("[%s] \033[31mError - No sum is agreed on by most subsets of the points.\033[0m\n", name)
+		diag.Note = fmt.Sprintf("no majority among %v subsets", subsets)
+		return Result{Code: protocol.ERR_NOT_ON_POLYNOMIUM}, diag
+	}
+
+	// Blame the points off the polynomium of the winning subset
+	interpolated := map[int]bool{}
+	for _, pt := range subsetOf[best] {
+		interpolated[pt.X] = true
+	}
+	for _, pt := range points {
+		if interpolated[pt.X] {
+			continue
+		}
+		diag.Checked = append(diag.Checked, pt)
+		expected := sharing.Lagrange(pt.X, p, subsetOf[best])
+		if expected == pt.Y {
+			continue
+		}
+		method := protocol.BLAME_OFF_POLYNOMIUM
+		if pt.Y < 0 || pt.Y >= p {
+			method = protocol.BLAME_OUTSIDE_FIELD
+		}
+		fmt.Printf("[%s] \033[31mPoint %v is off the polynomium of the majority, expected %v.\033[0m\n", name, pt, expected)
+		blames = append(blames, Blame{Point: pt, Expected: expected, Method: method})
+	}
+
+	// Get (yes) votes
+	diag.Note = fmt.Sprintf("%v of %v subsets agree", bestCount, subsets)
+	return Result{Yes: best, Blames: blames}, diag
+
+}
+
+// Call f with every subset of size n of the points (in order)
+func forEachSubset(points []sharing.Point, n int, f func([]sharing.Point)) {
+	subset := make([]sharing.Point, 0, n)
+	var pick func(from int)
+	pick = func(from int) {
+		if len(subset) == n {
+			f(subset)
+			return
+		}
+		for i := from; i <= len(points)-(n-len(subset)); i++ {
+			subset = append(subset, points[i])
+			pick(i + 1)
+			subset = subset[:len(subset)-1]
+		}
+	}
+	pick(0)
+}
@@ -0,0 +1,71 @@
+package tally
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// Interpolates k+1 of the points, and re-evaluates the polynomium at the rest of them (error detection).
+// A server picks its first alpha point by its ServerID, and the rest at random.
+type Reevaluate struct{}
+
+func (Reevaluate) Name() string   { return REEVALUATE }
+func (Reevaluate) Corrects() bool { return false }
+
+func (Reevaluate) Tally(points []sharing.Point, params Params) (Result, Diagnostic) {
+	name, p, k := params.Name, params.P, params.K
+	diag := Diagnostic{Strategy: REEVALUATE}
+
+	// Log points
+	fmt.Printf("[%s] My points for lagrange interpolation is: %v.\n", name, points)
+
+	// Verify all fall within field
+	if inside, e := AllInField(points, p); !inside {
+		logOutside(name, points, e)
+		diag.Note = "points outside the field"
+		return Result{Code: protocol.ERR_POINTS_OUTSIDE_FIELD}, diag
+	}
+
+	// Log all points valid
+	fmt.Printf("[%s] \033[32mAll points are in the field\033[0m\n", name)
+
+	// Without a point more than the degree there is nothing to check the polynomium against
+	if len(points) < k+2 {
+		fmt.Printf("[%s] \033[33mOnly %v points, so errors cannot be detected in this tally.\033[0m\n", name, len(points))
+		diag.Interpolated = points
+		diag.Note = "too few points to check, unchecked"
+		return Result{Yes: sharing.Lagrange(0, p, points)}, diag
+	}
+
+	// Pick alpha points given our server ID
+	tmp := make([]int, len(points))
+	for i := range tmp {
+		tmp[i] = i
+	}
+	var alpha int
+	alpha, tmp = Pop(tmp, firstAlpha(params.ServerID, len(points)))
+	sample_set := []sharing.Point{points[alpha]}
+	for len(sample_set) < k+1 {
+		alpha, tmp = Pop(tmp, -1)
+		sample_set = append(sample_set, points[alpha])
+	}
+	diag.Interpolated = sample_set
+
+	// Try compute the other points, given selection
+	for _, i := range tmp {
+		check := points[i]
+		diag.Checked = append(diag.Checked, check)
+		if sharing.Lagrange(check.X, p, sample_set) != check.Y {
+			fmt.Printf("[%s] Error - Point %v is not a point on polynomium\n", name, check)
+			diag.Note = fmt.Sprintf("point %v is off the polynomium", check)
+			return Result{Code: protocol.ERR_NOT_ON_POLYNOMIUM}, diag
+		}
+	}
+
+	// Get (yes) votes
+	diag.Note = "all points on the polynomium"
+	return Result{Yes: sharing.Lagrange(0, p, sample_set)}, diag
+
+}
@@ -0,0 +1,129 @@
+package tally
+
+import (
+	"fmt"
+	"math/rand"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// A way of reconstructing the sum of the votes from the R-sum points of the servers, and of handling the points of
+// corrupt servers. The strategy is picked per election, so the strategies can be compared on the same points.
+type Strategy interface {
+
+	// Name of the strategy (as given to -tally)
+	Name() string
+
+	// True if a lying server is corrected for, given enough points (false if lies are at most detected)
+	Corrects() bool
+
+	// Reconstruct the sum of the votes from the points (ordered by X), telling how it was done in the diagnostic
+	Tally(points []sharing.Point, params Params) (Result, Diagnostic)
+}
+
+// Parameters of a tally
+type Params struct {
+	Name     string // Who is tallying (for the log)
+	ServerID uint8  // ServerID of who is tallying (0 for a voter verifying the tally)
+	P        int    // Prime
+	K        int    // Degree of the polynomium (the amount of dishonest servers prepared for)
+	Servers  int    // Amount of servers the votes were shared among
+}
+
+// Outcome of reconstructing the sum of the votes
+type Result struct {
+	Yes    int                // The sum of the votes
+	Code   protocol.ErrorCode // Why the sum could not be reconstructed (ERR_NONE if it was)
+	Blames []Blame            // Points caught lying
+}
+
+// A point caught lying, and how
+type Blame struct {
+	Point    sharing.Point // The point of the lying server
+	Expected int           // The value interpolated from the other points at the X of the lying server
+	Method   string        // How the lie was detected (see protocol.BLAME_OUTSIDE_FIELD, ...)
+}
+
+// How a strategy got to its result
+type Diagnostic struct {
+	Strategy     string          // Name of the strategy
+	Interpolated []sharing.Point // Points the sum was interpolated from
+	Checked      []sharing.Point // Points checked against the interpolation
+	Note         string          // What the strategy concluded
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s interpolated %v, checked %v: %s", d.Strategy, d.Interpolated, d.Checked, d.Note)
+}
+
+// Names of the strategies
+const (
+	SUM             = "sum"
+	INTERPOLATE     = "interpolate"
+	REEVALUATE      = "reevaluate"
+	MAJORITY        = "majority"
+	BERLEKAMP_WELCH = "berlekamp-welch"
+	DECRYPT         = "decrypt"
+)
+
+// Lists the known strategies
+var Names = []string{SUM, INTERPOLATE, REEVALUATE, MAJORITY, BERLEKAMP_WELCH, DECRYPT}
+
+// Get the strategy with the given name
+func ByName(name string) (Strategy, error) {
+	switch name {
+	case SUM:
+		return Sum{}, nil
+	case INTERPOLATE:
+		return Interpolate{}, nil
+	case REEVALUATE:
+		return Reevaluate{}, nil
+	case MAJORITY:
+		return Majority{}, nil
+	case BERLEKAMP_WELCH:
+		return BerlekampWelch{}, nil
+	case DECRYPT:
+		return Decrypt{}, nil
+	}
+	return nil, fmt.Errorf("unknown tally strategy '%s' (known: %v)", name, Names)
+}
+
+func Pop(ints []int, i int) (int, []int) {
+	if len(ints) == 1 {
+		return ints[0], []int{}
+	}
+	j := i
+	if i == -1 {
+		j = rand.Intn(len(ints))
+	}
+	rval := ints[j] // We must capture return value before returning (Go evaluates multiple returns from right to left...)
+	return rval, append(ints[:j], ints[j+1:]...)
+}
+
+// Index of the first alpha point of who is tallying among n points (-1 picks at random, as a voter does)
+func firstAlpha(serverID uint8, n int) int {
+	if serverID == 0 {
+		return -1
+	}
+	return (int(serverID) - 1) % n
+}
+
+// Check the points are in the field (0 <= Y < p), returning the indices of those that are not
+func AllInField(points []sharing.Point, p int) (bool, []int) {
+	errs := make([]int, 0)
+	for i := 0; i < len(points); i++ {
+		if points[i].Y >= p || points[i].Y < 0 {
+			errs = append(errs, i)
+		}
+	}
+	return len(errs) == 0, errs
+}
+
+// Log the points outside the field
+func logOutside(name string, points []sharing.Point, e []int) {
+	fmt.Printf("[%s] \033[31mDetected %v point(s) outside the field!\033[0m\n", name, len(e))
+	for _, v := range e {
+		fmt.Printf("[%s] \033[31mPoint %v is outside the field and is invalid!\033[0m\n", name, points[v])
+	}
+}
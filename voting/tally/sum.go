@@ -0,0 +1,34 @@
+package tally
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// Adds up the R-sums of additive shares. Every share is needed, and lies cannot be detected.
+type Sum struct{}
+
+func (Sum) Name() string   { return SUM }
+func (Sum) Corrects() bool { return false }
+
+func (Sum) Tally(points []sharing.Point, params Params) (Result, Diagnostic) {
+	diag := Diagnostic{Strategy: SUM, Interpolated: points}
+
+	// Every share is needed to add up the votes (one per server)
+	if len(points) < params.Servers {
+		fmt.Printf("[%s] \033[31mOnly %v of %v R-sums, the votes cannot be added up.\033[0m\n", params.Name, len(points), params.Servers)
+		diag.Note = "too few R-sums to add up"
+		return Result{Code: protocol.ERR_TOO_FEW_SERVERS}, diag
+	}
+
+	// Get (yes) votes
+	sum := 0
+	for _, pt := range points {
+		sum = sharing.SumField(params.P, sum, pt.Y)
+	}
+	diag.Note = "added up, unchecked"
+	return Result{Yes: sum}, diag
+
+}
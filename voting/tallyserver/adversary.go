@@ -0,0 +1,394 @@
+package tallyserver
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"cs.au.dk/voting/elgamal"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// A (byzantine) behaviour of a bad server.
+// Behaviours are composed, so every hook is applied in the order the behaviours were given,
+// each one getting the output of the previous behaviour.
+type Behaviour interface {
+
+	// Called once the server has its honest defaults (may replace the variability points)
+	Install(server *Server)
+
+	// Decide which R-sum to send to a partner, given the R-sum we would otherwise send
+	RSumTo(server *Server, partner *PartnerServer, sum int) int
+
+	// Filter a request to a partner, returning the requests actually sent (nil = silent)
+	ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request
+
+	// Filter a request to a voter, returning the requests actually sent (nil = silent)
+	ToVoter(server *Server, voter *Voter, req protocol.Request) []protocol.Request
+}
+
+// Honest behaviour, can be embedded by behaviours only overriding some of the hooks
+type HonestBehaviour struct{}
+
+func (HonestBehaviour) Install(server *Server) {}
+
+func (HonestBehaviour) RSumTo(server *Server, partner *PartnerServer, sum int) int {
+	return sum
+}
+
+func (HonestBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	return []protocol.Request{req}
+}
+
+func (HonestBehaviour) ToVoter(server *Server, voter *Voter, req protocol.Request) []protocol.Request {
+	return []protocol.Request{req}
+}
+
+// Configuration of a behaviour (as found in a scenario file)
+type BehaviourConfig struct {
+	Behaviour string `json:"behaviour"` // Name of the behaviour
+	Mode      int    `json:"mode"`      // Corruption mode of 'wrong-rsum' (-1 = random)
+	Offset    int    `json:"offset"`    // Offset used by 'split-rsum'
+	As        int    `json:"as"`        // ServerID claimed by 'forge-id'
+	Yes       int    `json:"yes"`       // Yes votes reported by 'forge-tally'
+	No        int    `json:"no"`        // No votes reported by 'forge-tally'
+	Group     string `json:"group"`     // Name of the group of colluding servers
+	Seed      int64  `json:"seed"`      // Shared secret of colluding servers
+}
+
+// Lists the known behaviours
+var BehaviourNames = []string{"wrong-rsum", "bad-intersection", "split-rsum", "silent", "replay", "forge-id", "forge-tally", "collude", "crash", "wrong-partial", "bad-dealing", "split-aggregate", "no-receipt", "hide-receipt"}
+
+// Create behaviour from its configuration
+func NewBehaviour(cfg BehaviourConfig) (Behaviour, error) {
+	switch cfg.Behaviour {
+	case "wrong-rsum":
+		return &WrongRSumBehaviour{Mode: cfg.Mode}, nil
+	case "bad-intersection":
+		return &BadIntersectionBehaviour{}, nil
+	case "split-rsum":
+		return &SplitRSumBehaviour{Offset: cfg.Offset}, nil
+	case "silent":
+		return &SilentBehaviour{}, nil
+	case "replay":
+		return &ReplayBehaviour{sent: map[string]protocol.Request{}}, nil
+	case "forge-id":
+		return &ForgeIDBehaviour{As: uint8(cfg.As)}, nil
+	case "forge-tally":
+		return &ForgeTallyBehaviour{Yes: cfg.Yes, No: cfg.No}, nil
+	case "collude":
+		return NewColludeBehaviour(cfg.Group, cfg.Seed), nil
+	case "crash":
+		return &CrashBehaviour{}, nil
+	case "wrong-partial":
+		return &WrongPartialBehaviour{}, nil
+	case "bad-dealing":
+		return &BadDealingBehaviour{}, nil
+	case "split-aggregate":
+		return &SplitAggregateBehaviour{}, nil
+	case "no-receipt":
+		return &NoReceiptBehaviour{}, nil
+	case "hide-receipt":
+		return &HideReceiptBehaviour{}, nil
+	}
+	return nil, fmt.Errorf("unknown behaviour '%s' (known: %v)", cfg.Behaviour, BehaviourNames)
+}
+
+// Legacy corrupt R-sum (-b 1 -bb {mode})
+type WrongRSumBehaviour struct {
+	HonestBehaviour
+	Mode int
+}
+
+func (b *WrongRSumBehaviour) Install(server *Server) {
+	if b.Mode >= 0 {
+		server.SumCalculation = func(s *Server) int {
+			return CorruptRSumDet(s, b.Mode)
+		}
+	} else {
+		server.SumCalculation = CorruptRSum
+	}
+}
+
+// Legacy corrupt client intersection (-b 0)
+type BadIntersectionBehaviour struct {
+	HonestBehaviour
+}
+
+func (b *BadIntersectionBehaviour) Install(server *Server) {
+	server.IntersectFunc = CorruptIntersection
+}
+
+// Sends a different R-sum to each partner (the R-sum is shifted by offset times the partner ID)
+type SplitRSumBehaviour struct {
+	HonestBehaviour
+	Offset int
+}
+
+func (b *SplitRSumBehaviour) RSumTo(server *Server, partner *PartnerServer, sum int) int {
+	offset := b.Offset
+	if offset == 0 {
+		offset = 1
+	}
+	forged := sharing.Pmod(sum+offset*int(partner.ServerID), server.P)
+	fmt.Printf("[BadServer] \033[31mSending R-sum %v to %s instead of %v.\033[0m\n", forged, partner.Id, sum)
+	return forged
+}
+
+// Registers with partners and voters, and then never says anything again
+type SilentBehaviour struct {
+	HonestBehaviour
+}
+
+func (b *SilentBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	if req.RequestType == protocol.SERVERJOIN || req.RequestType == protocol.SERVERRESPONCE {
+		return []protocol.Request{req}
+	}
+	fmt.Printf("[BadServer] \033[31mSilently dropping request %v to %s.\033[0m\n", req.RequestType, partner.Id)
+	return nil
+}
+
+func (b *SilentBehaviour) ToVoter(server *Server, voter *Voter, req protocol.Request) []protocol.Request {
+	if req.RequestType == protocol.ID {
+		return []protocol.Request{req}
+	}
+	return nil
+}
+
+// Crashes once the voting is closed, instead of sending its client list (closing every connection)
+type CrashBehaviour struct {
+	HonestBehaviour
+	crashed bool
+}
+
+func (b *CrashBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	if req.RequestType != protocol.INTERSECTION {
+		return []protocol.Request{req}
+	}
+	if !b.crashed {
+		b.crashed = true
+		fmt.Printf("[BadServer] \033[31mCrashing instead of sending the client list.\033[0m\n")
+		go server.Crash()
+	}
+	return nil
+}
+
+// Stop the server as if it crashed, telling whoever waits for its results
+func (server *Server) Crash() {
+	server.Tally <- protocol.Results{Error: true, Code: protocol.ERR_PARTNER_LOST}
+	server.Stop()
+}
+
+// Replays the previous message sent to a partner after every new message
+type ReplayBehaviour struct {
+	HonestBehaviour
+	sent map[string]protocol.Request
+}
+
+func (b *ReplayBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	out := []protocol.Request{req}
+	if old, exists := b.sent[partner.Id]; exists {
+		fmt.Printf("[BadServer] \033[31mReplaying request %v to %s.\033[0m\n", old.RequestType, partner.Id)
+		out = append(out, old)
+	}
+	b.sent[partner.Id] = req
+	return out
+}
+
+// Claims to be another server when joining partners
+type ForgeIDBehaviour struct {
+	HonestBehaviour
+	As uint8
+}
+
+func (b *ForgeIDBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	if req.RequestType == protocol.SERVERJOIN || req.RequestType == protocol.SERVERRESPONCE {
+		fmt.Printf("[BadServer] \033[31mClaiming to be server %v when joining.\033[0m\n", b.As)
+		req.Val1 = int(b.As)
+	}
+	return []protocol.Request{req}
+}
+
+// Reports a made up tally to voters
+type ForgeTallyBehaviour struct {
+	HonestBehaviour
+	Yes int
+	No  int
+}
+
+func (b *ForgeTallyBehaviour) ToVoter(server *Server, voter *Voter, req protocol.Request) []protocol.Request {
+	if req.RequestType == protocol.TALLY {
+		req = protocol.Results{Yes: b.Yes, No: b.No}.ToRequest()
+	}
+	return []protocol.Request{req}
+}
+
+// Colluding servers shift their R-sums along a common line g(x)=a+bx.
+// Every member of a group derives the same line from the shared seed, so the
+// corrupt points are consistent with each other (but not with the honest points).
+type ColludeBehaviour struct {
+	HonestBehaviour
+	Group string
+	A     int
+	B     int
+}
+
+// Create a colluding behaviour for the group
+func NewColludeBehaviour(group string, seed int64) *ColludeBehaviour {
+	shared := rand.New(rand.NewSource(seed))
+	return &ColludeBehaviour{Group: group, A: 1 + shared.Intn(100), B: 1 + shared.Intn(100)}
+}
+
+func (b *ColludeBehaviour) RSumTo(server *Server, partner *PartnerServer, sum int) int {
+	forged := sharing.Pmod(sum+b.A+b.B*int(server.ServerID), server.P)
+	fmt.Printf("[BadServer] \033[31mColluding with group '%s', sending R-sum %v instead of %v.\033[0m\n", b.Group, forged, sum)
+	return forged
+}
+
+// Sends a wrong partial decryption of the encrypted tally (multiplied by the generator, so still in the group, but
+// no longer matching its proof)
+type WrongPartialBehaviour struct {
+	HonestBehaviour
+}
+
+func (b *WrongPartialBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	if req.RequestType != protocol.PARTIALDECRYPT {
+		return []protocol.Request{req}
+	}
+	msg := req.ToPartialMsg()
+	partial, err := elgamal.Decode(msg.Partial)
+	if err != nil {
+		return []protocol.Request{req}
+	}
+	partial.Mul(partial, elgamal.G).Mod(partial, elgamal.P)
+	fmt.Printf("[BadServer] \033[31mSending a wrong partial decryption to %s.\033[0m\n", partner.Id)
+	msg.Partial = elgamal.Encode(partial)
+	return []protocol.Request{msg.ToRequest()}
+}
+
+// Deals shares of the election key that do not match its commitments
+type BadDealingBehaviour struct {
+	HonestBehaviour
+}
+
+func (b *BadDealingBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	if req.RequestType != protocol.DKGDEAL {
+		return []protocol.Request{req}
+	}
+	msg := req.ToDKGMsg()
+	share, err := elgamal.DecodeExponent(msg.Share)
+	if err != nil {
+		return []protocol.Request{req}
+	}
+	share.Add(share, big.NewInt(1)).Mod(share, elgamal.Q)
+	fmt.Printf("[BadServer] \033[31mDealing a wrong share of the election key to %s.\033[0m\n", partner.Id)
+	msg.Share = elgamal.Encode(share)
+	return []protocol.Request{msg.ToRequest()}
+}
+
+// Sends partners with an even ServerID another aggregate of its ballots (with one more yes vote and voter), signed
+// with its key share all the same
+type SplitAggregateBehaviour struct {
+	HonestBehaviour
+}
+
+func (b *SplitAggregateBehaviour) ToPartner(server *Server, partner *PartnerServer, req protocol.Request) []protocol.Request {
+	if req.RequestType != protocol.AGGREGATE || partner.ServerID%2 != 0 || server.threshold.key == nil {
+		return []protocol.Request{req}
+	}
+	msg := req.ToAggregateMsg()
+	ballots, err := elgamal.DecodeCiphertext(msg.Ciphertext)
+	if err != nil {
+		return []protocol.Request{req}
+	}
+	r, err := elgamal.RandomExponent(crand.Reader)
+	if err != nil {
+		return []protocol.Request{req}
+	}
+	forged := aggregate{ballots: ballots.Mul(elgamal.EncryptWith(server.threshold.key.H, 1, r)), voters: msg.Voters + 1}
+	if forged.signature, err = server.threshold.key.Sign(crand.Reader, server.proofContext(), forged.signed(msg.ServerID)...); err != nil {
+		return []protocol.Request{req}
+	}
+	fmt.Printf("[BadServer] \033[31mSending another aggregate of %v ballot(s) to %s.\033[0m\n", forged.voters, partner.Id)
+	return []protocol.Request{forged.message(msg.ServerID).ToRequest()}
+}
+
+// Takes the shares of the voters without giving them a receipt
+type NoReceiptBehaviour struct {
+	HonestBehaviour
+}
+
+func (b *NoReceiptBehaviour) ToVoter(server *Server, voter *Voter, req protocol.Request) []protocol.Request {
+	if req.RequestType != protocol.RECEIPT {
+		return []protocol.Request{req}
+	}
+	fmt.Printf("[BadServer] \033[31mWithholding the receipt of %s.\033[0m\n", voter.Id)
+	return nil
+}
+
+// Leaves the receipt of the voter out of the tallied set sent along with the tally (as if its ballot was dropped)
+type HideReceiptBehaviour struct {
+	HonestBehaviour
+}
+
+func (b *HideReceiptBehaviour) ToVoter(server *Server, voter *Voter, req protocol.Request) []protocol.Request {
+	if req.RequestType != protocol.TALLY {
+		return []protocol.Request{req}
+	}
+	results := req.ToTallyMsg()
+	receipts := make([]string, 0, len(results.Receipts))
+	for _, r := range results.Receipts {
+		if r != voter.receipts[voter.Seq] {
+			receipts = append(receipts, r)
+		}
+	}
+	fmt.Printf("[BadServer] \033[31mHiding the receipt of %s from the tallied set.\033[0m\n", voter.Id)
+	results.Receipts = receipts
+	return []protocol.Request{results.ToRequest()}
+}
+
+// Apply all behaviours to the R-sum sent to partner
+func (server *Server) rsumTo(partner *PartnerServer, sum int) int {
+	for _, b := range server.Behaviours {
+		sum = b.RSumTo(server, partner, sum)
+	}
+	return sum
+}
+
+// Send request to partner through all behaviours
+func (server *Server) sendToPartner(partner *PartnerServer, req protocol.Request) error {
+	reqs := []protocol.Request{req}
+	for _, b := range server.Behaviours {
+		next := make([]protocol.Request, 0, len(reqs))
+		for _, r := range reqs {
+			next = append(next, b.ToPartner(server, partner, r)...)
+		}
+		reqs = next
+	}
+	for _, r := range reqs {
+		if e := partner.Connection.Send(r); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// Send request to voter through all behaviours
+func (server *Server) sendToVoter(voter *Voter, req protocol.Request) error {
+	reqs := []protocol.Request{req}
+	for _, b := range server.Behaviours {
+		next := make([]protocol.Request, 0, len(reqs))
+		for _, r := range reqs {
+			next = append(next, b.ToVoter(server, voter, r)...)
+		}
+		reqs = next
+	}
+	for _, r := range reqs {
+		if e := voter.Connection.Send(r); e != nil {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package tallyserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// Blame reports kept on disk, so servers blamed in earlier elections can be refused as peers
+type BlameStore struct {
+	Path    string
+	Reports []protocol.BlameReport
+	mutex   sync.Mutex
+}
+
+// Load the blame reports from file (a missing file is an empty store)
+func LoadBlameStore(path string) (*BlameStore, error) {
+	store := &BlameStore{Path: path, Reports: make([]protocol.BlameReport, 0)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.Reports); err != nil {
+		return nil, fmt.Errorf("invalid blame file '%s': %v", path, err)
+	}
+	return store, nil
+}
+
+// Add a report and save the store
+func (store *BlameStore) Add(report protocol.BlameReport) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if report.Time == "" {
+		report.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+	store.Reports = append(store.Reports, report)
+	return store.save()
+}
+
+// Check if the server with the ServerID at the configured address was blamed by us (and not re-admitted). The name a
+// peer gives itself is not used, as a blamed server could join under another name. Reports without an address (made
+// before reports had one) refuse whoever joins with the ServerID.
+// Reports from partners are kept as evidence, but only our own detections refuse a peer, so a lying partner cannot get honest servers refused.
+func (store *BlameStore) Refused(serverID uint8, addr, self string) bool {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	for _, r := range store.Reports {
+		if r.Blamed == serverID && (r.BlamedAddr == "" || r.BlamedAddr == addr) && r.Reporter == self && !r.Readmitted {
+			return true
+		}
+	}
+	return false
+}
+
+// Re-admit a blamed server by its name or address (returns the amount of reports cleared)
+func (store *BlameStore) Readmit(name string) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	cleared := 0
+	for i := range store.Reports {
+		if (store.Reports[i].BlamedName == name || store.Reports[i].BlamedAddr == name) && !store.Reports[i].Readmitted {
+			store.Reports[i].Readmitted = true
+			cleared++
+		}
+	}
+	return cleared, store.save()
+}
+
+// Write the store to disk (if it has a path)
+func (store *BlameStore) save() error {
+	if store.Path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(store.Reports, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(store.Path, data, 0644)
+}
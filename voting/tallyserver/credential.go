@@ -0,0 +1,38 @@
+package tallyserver
+
+import (
+	"errors"
+	"fmt"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/protocol"
+)
+
+// The ID a joining voter is known by. When the voters are registered by an authority, the voter shows its credential
+// (the serial and signature) instead of its ID, and is known by the hash of the credential, which must be signed by
+// the authority. As a credential is only issued once per voter, the ID is refused if it registers again.
+func (server *Server) joiningVoter(conn protocol.Conn, join protocol.ClientJoinMessage) (string, bool) {
+
+	// Voters join with their ID, unless they are registered by an authority
+	if join.ID == "" {
+		return "", false
+	}
+	if server.Authority == nil {
+		return join.ID, true
+	}
+
+	// The credential must be signed by the authority
+	token, err := credential.DecodeToken(join.Credential)
+	if join.Credential == nil {
+		err = errors.New("the voter joined without one")
+	} else if err == nil && !server.Authority.Verify(token) {
+		err = errors.New("it is not signed by the authority")
+	}
+	if err != nil {
+		fmt.Printf("[%s] \033[31mRefusing registration of %s, the credential does not hold: %v.\033[0m\n", server.ID, join.ID, err)
+		conn.Send(protocol.RejectMessage{Code: protocol.ERR_NOT_ELIGIBLE, Reason: fmt.Sprintf("the credential does not hold: %v", err)}.ToRequest())
+		return "", false
+	}
+	return token.Key(), true
+
+}
@@ -0,0 +1,613 @@
+package tallyserver
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"cs.au.dk/voting/elgamal"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/tally"
+)
+
+// State of the threshold ElGamal tally. Every server of the scheme deals in the key generation (once its voting
+// period starts), the ballots counted at each server are multiplied into its aggregate, and the product of all
+// aggregates is decrypted by the servers together once every aggregate arrived. Every server signs its aggregate
+// with its key share, and echoes the aggregates it got to the partners before decrypting, so a server signing
+// different aggregates for different partners is caught instead of failing the partial decryptions of the others.
+type thresholdTally struct {
+
+	// Key generation (our dealing, the shares dealt to us and the commitments of every dealer, by ServerID)
+	dealing     *elgamal.Dealing
+	shares      map[uint8]*big.Int
+	commitments map[uint8][]*big.Int
+	key         *elgamal.KeyShare
+
+	// Aggregates of every server (by ServerID, they may arrive before we summed)
+	aggregates map[uint8]aggregate
+
+	// The cross-check of the aggregates (the partners whose echo arrived, and the servers that signed two different
+	// aggregates), done before decrypting
+	echoed    map[uint8]bool
+	conflicts map[uint8]bool
+	echoing   bool
+	checked   bool
+
+	// The product of all aggregates being decrypted (nil until we tally), and the partial decryptions of the servers
+	// (the proven ones, and the servers whose proof failed)
+	total    *elgamal.Ciphertext
+	voters   int
+	partials map[uint8]*big.Int
+	rejected map[uint8]bool
+	pending  []pendingPartial
+	done     bool
+}
+
+// Product of the ballots counted at a server, signed by the server
+type aggregate struct {
+	ballots   elgamal.Ciphertext
+	voters    int
+	signature elgamal.Signature
+}
+
+// The values a server signs for its aggregate
+func (agg aggregate) signed(id uint8) []*big.Int {
+	return []*big.Int{big.NewInt(int64(id)), big.NewInt(int64(agg.voters)), agg.ballots.A, agg.ballots.B}
+}
+
+// Check if two aggregates are the same (ignoring the signatures)
+func (agg aggregate) equals(other aggregate) bool {
+	return agg.voters == other.voters && agg.ballots.A.Cmp(other.ballots.A) == 0 && agg.ballots.B.Cmp(other.ballots.B) == 0
+}
+
+// The aggregate of the server as a message
+func (agg aggregate) message(id uint8) protocol.AggregateMessage {
+	return protocol.AggregateMessage{ServerID: id, Voters: agg.voters, Ciphertext: agg.ballots.Encode(), Signature: agg.signature.Encode()}
+}
+
+// Deal our polynomium of the key generation to the partners (once), giving them PhaseTimeout to deal theirs
+func (server *Server) startKeyGeneration() {
+	if server.threshold.dealing != nil {
+		return
+	}
+	dealing, err := elgamal.NewDealing(rand.Reader, server.K)
+	if err != nil {
+		server.failTally(protocol.ERR_KEYGEN_FAILED, fmt.Sprintf("cannot deal: %v", err))
+		return
+	}
+	server.threshold.dealing = dealing
+	server.threshold.shares = map[uint8]*big.Int{server.ServerID: dealing.Share(int(server.ServerID))}
+	server.threshold.commitments = map[uint8][]*big.Int{server.ServerID: dealing.Commitments}
+	fmt.Printf("[%s] Dealing the election key to %v partner(s).\n", server.ID, len(server.PartnerConns))
+	for _, partner := range server.PartnerConns {
+		server.dealTo(partner)
+	}
+	time.AfterFunc(server.PhaseTimeout, server.keyGenerationTimedOut)
+	server.tryKey()
+}
+
+// Send our dealing to a partner (with its share), if we dealt already
+func (server *Server) dealTo(partner *PartnerServer) {
+	if server.threshold.dealing == nil {
+		return
+	}
+	commitments := make([]string, len(server.threshold.dealing.Commitments))
+	for i, c := range server.threshold.dealing.Commitments {
+		commitments[i] = elgamal.Encode(c)
+	}
+	msg := protocol.DKGMessage{ServerID: server.ServerID, Commitments: commitments, Share: elgamal.Encode(server.threshold.dealing.Share(int(partner.ServerID)))}
+	if e := server.sendToPartner(partner, msg.ToRequest()); e != nil {
+		fmt.Printf("[%s] Failed to deal the election key to %s: %v\n", server.ID, partner.Id, e)
+	}
+}
+
+// Handle the dealing of a partner, checking our share against its commitments
+func (server *Server) receiveDealing(partner *PartnerServer, msg protocol.DKGMessage) {
+	if server.didTally {
+		return
+	}
+	if _, exists := server.threshold.commitments[partner.ServerID]; exists || server.threshold.key != nil {
+		fmt.Printf("[%s] Ignoring another dealing of %s, the key is already dealt.\n", server.ID, partner.Id)
+		return
+	}
+
+	// Decode the dealing, which must be of a polynomium of our degree
+	valid := len(msg.Commitments) == server.K+1
+	commitments := make([]*big.Int, len(msg.Commitments))
+	for i, s := range msg.Commitments {
+		c, err := elgamal.Decode(s)
+		valid = valid && err == nil
+		commitments[i] = c
+	}
+	share, err := elgamal.DecodeExponent(msg.Share)
+	valid = valid && err == nil
+
+	// The share must match the commitments (or the dealer lied to us)
+	if !valid || !elgamal.VerifyShare(commitments, int(server.ServerID), share) {
+		reason := fmt.Sprintf("the share dealt by %s does not match its commitments", partner.Id)
+		server.blame(sharing.Point{X: int(partner.ServerID), Y: -1}, -1, protocol.BLAME_DKG_SHARE)
+		server.sendABORT(protocol.ERR_KEYGEN_FAILED, reason)
+		server.failTally(protocol.ERR_KEYGEN_FAILED, reason)
+		return
+	}
+	server.threshold.shares[partner.ServerID] = share
+	server.threshold.commitments[partner.ServerID] = commitments
+	server.tryKey()
+}
+
+// Combine the key once every server of the scheme dealt, and hand the public key to the voters
+func (server *Server) tryKey() {
+	if server.threshold.dealing == nil || server.threshold.key != nil || server.didTally {
+		return
+	}
+	for id := 1; id <= server.Scheme.Servers(); id++ {
+		if _, exists := server.threshold.commitments[uint8(id)]; !exists {
+			return
+		}
+	}
+	shares := map[int]*big.Int{}
+	commitments := map[int][]*big.Int{}
+	for id, c := range server.threshold.commitments {
+		shares[int(id)] = server.threshold.shares[id]
+		commitments[int(id)] = c
+	}
+	key, err := elgamal.CombineShares(int(server.ServerID), shares, commitments)
+	if err != nil {
+		server.failTally(protocol.ERR_KEYGEN_FAILED, err.Error())
+		return
+	}
+	server.threshold.key = &key
+	fmt.Printf("[%s] \033[32mGenerated the election key with %v server(s).\033[0m\n", server.ID, len(commitments))
+
+	// Every server must have got the same public key (a dealer may have sent different commitments around)
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, protocol.KeyMessage{Key: elgamal.Encode(key.H)}.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to send the public key to %s: %v\n", server.ID, partner.Id, e)
+		}
+	}
+
+	// Voters who registered before can now encrypt their ballots
+	for _, voter := range server.Clientsconnections {
+		server.sendKey(voter)
+	}
+}
+
+// Check the public key a partner got is ours
+func (server *Server) receiveKey(partner *PartnerServer, msg protocol.KeyMessage) {
+	if server.threshold.key == nil || server.didTally || msg.Key == elgamal.Encode(server.threshold.key.H) {
+		return
+	}
+	reason := fmt.Sprintf("%s got another public key than we did", partner.Id)
+	server.sendABORT(protocol.ERR_KEYGEN_FAILED, reason)
+	server.failTally(protocol.ERR_KEYGEN_FAILED, reason)
+}
+
+// Give up if not every server dealt in time
+func (server *Server) keyGenerationTimedOut() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.threshold.key != nil || server.didTally {
+		return
+	}
+	server.failTally(protocol.ERR_KEYGEN_FAILED, fmt.Sprintf("only %v of %v server(s) dealt in time", len(server.threshold.commitments), server.Scheme.Servers()))
+}
+
+// Send the public key to a voter (once the key is generated, and only once)
+func (server *Server) sendKey(voter *Voter) {
+	if server.threshold.key == nil || voter.keySent {
+		return
+	}
+	voter.keySent = true
+	if e := server.sendToVoter(voter, protocol.KeyMessage{Key: elgamal.Encode(server.threshold.key.H)}.ToRequest()); e != nil {
+		fmt.Printf("[%s] Failed to send the public key to %s.\n", server.ID, voter.Id)
+	}
+}
+
+// Take the encrypted ballot of a voter, checking its proof (if attached, or required)
+func (server *Server) takeBallot(voter *Voter, msg protocol.BallotMessage) bool {
+	if server.threshold.key == nil {
+		server.rejectBallot(voter, "the election key is not generated")
+		return false
+	}
+	ballot, err := elgamal.DecodeCiphertext(msg.Ciphertext)
+	if err != nil {
+		server.rejectBallot(voter, err.Error())
+		return false
+	}
+
+	// The ballot must encrypt 0 or 1
+	if len(msg.Proof) == 0 && server.RequireProofs {
+		server.rejectBallot(voter, "no proof that it encrypts 0 or 1")
+		return false
+	}
+	if len(msg.Proof) > 0 {
+		proof, err := elgamal.DecodeBitProof(msg.Proof)
+		if err != nil {
+			server.rejectBallot(voter, err.Error())
+			return false
+		}
+		if !elgamal.VerifyBit(server.proofContext(), ballot, proof, voter.Id) {
+			server.rejectBallot(voter, "the proof that it encrypts 0 or 1 does not hold")
+			return false
+		}
+		fmt.Printf("[%s] Checked the proof of the ballot of %s.\n", server.ID, voter.Id)
+	}
+	voter.Ballot = &ballot
+	return true
+}
+
+// Tell a voter why we did not take its ballot
+func (server *Server) rejectBallot(voter *Voter, reason string) {
+	fmt.Printf("[%s] \033[31mRejecting ballot of %s: %s.\033[0m\n", server.ID, voter.Id, reason)
+	if e := server.sendToVoter(voter, protocol.RejectMessage{Code: protocol.ERR_BALLOT_REJECTED, Reason: reason}.ToRequest()); e != nil {
+		fmt.Printf("[%s] Failed to tell %s its ballot was rejected.\n", server.ID, voter.Id)
+	}
+}
+
+// Multiply the ballots of the voters we count into our aggregate, and send it to the partners
+func (server *Server) shareAggregate() {
+	product := elgamal.Zero()
+	voters := 0
+	for _, v := range server.Clientsconnections {
+		if _, exists := server.VoterIntersection[v.Id]; exists && v.Ballot != nil {
+			fmt.Printf("[%s] Counting ballot of %s\n", server.ID, v.Id)
+			product = product.Mul(*v.Ballot)
+			voters++
+		}
+	}
+	fmt.Printf("[%s] Voting period ended. Multiplied %v ballot(s).\n", server.ID, voters)
+
+	// Sign it (without a key the tally fails anyway)
+	agg := aggregate{ballots: product, voters: voters}
+	if server.threshold.key != nil {
+		signature, err := server.threshold.key.Sign(rand.Reader, server.proofContext(), agg.signed(server.ServerID)...)
+		if err != nil {
+			server.failTally(protocol.ERR_DECRYPTION_FAILED, fmt.Sprintf("cannot sign our ballots: %v", err))
+			return
+		}
+		agg.signature = signature
+	}
+	server.storeAggregate(server.ServerID, agg)
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, agg.message(server.ServerID).ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to send our ballots to %s, %v\n", server.ID, partner.Id, e)
+		}
+	}
+}
+
+// Keep the aggregate of a server (the first one it sent). A server that signed another aggregate before is blamed,
+// and the other aggregate is passed on to the partners, so they catch it as well.
+func (server *Server) storeAggregate(id uint8, agg aggregate) {
+	if server.threshold.aggregates == nil {
+		server.threshold.aggregates = make(map[uint8]aggregate)
+	}
+	kept, exists := server.threshold.aggregates[id]
+	if !exists {
+		server.threshold.aggregates[id] = agg
+		return
+	}
+	if kept.equals(agg) || server.threshold.conflicts[id] || id == server.ServerID {
+		return
+	}
+	if server.threshold.conflicts == nil {
+		server.threshold.conflicts = make(map[uint8]bool)
+	}
+	server.threshold.conflicts[id] = true
+	fmt.Printf("[%s] \033[31mServer %v signed two different aggregates (%v and %v ballot(s)).\033[0m\n", server.ID, id, kept.voters, agg.voters)
+	server.blame(sharing.Point{X: int(id), Y: -1}, -1, protocol.BLAME_AGGREGATE)
+	echo := protocol.AggregateEchoMessage{ServerID: server.ServerID, Aggregates: []protocol.AggregateMessage{agg.message(id)}}
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, echo.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to pass on the aggregates of server %v to %s: %v\n", server.ID, id, partner.Id, e)
+		}
+	}
+}
+
+// Decode the aggregate of a server, checking it was signed by the server (if we have the key to check it with)
+func (server *Server) checkAggregate(msg protocol.AggregateMessage) (aggregate, error) {
+	ballots, err := elgamal.DecodeCiphertext(msg.Ciphertext)
+	if err != nil {
+		return aggregate{}, err
+	}
+	if msg.Voters < 0 {
+		return aggregate{}, fmt.Errorf("%v ballots", msg.Voters)
+	}
+	agg := aggregate{ballots: ballots, voters: msg.Voters}
+	if server.threshold.key == nil {
+		return agg, nil
+	}
+	if agg.signature, err = elgamal.DecodeSignature(msg.Signature); err != nil {
+		return aggregate{}, err
+	}
+	if !elgamal.VerifySignature(server.proofContext(), server.verificationKey(msg.ServerID), agg.signature, agg.signed(msg.ServerID)...) {
+		return aggregate{}, fmt.Errorf("not signed by server %v", msg.ServerID)
+	}
+	return agg, nil
+}
+
+// Handle the aggregate of a partner
+func (server *Server) receiveAggregate(partner *PartnerServer, msg protocol.AggregateMessage) {
+	if server.didTally {
+		fmt.Printf("[%s] Ignoring ballots from [%s], which came after the tally.\n", server.ID, partner.Id)
+		return
+	}
+	agg, err := server.checkAggregate(msg)
+	if err != nil || msg.ServerID != partner.ServerID {
+		fmt.Printf("[%s] \033[31mIgnoring malformed ballots from %s: %v.\033[0m\n", server.ID, partner.Id, err)
+		return
+	}
+	fmt.Printf("[%s] Got %v multiplied ballot(s) from [%s].\n", server.ID, msg.Voters, partner.Id)
+	server.storeAggregate(partner.ServerID, agg)
+	server.tryTally()
+}
+
+// Handle the aggregates a partner echoed (or passed on), which must be signed by the servers they came from
+func (server *Server) receiveEcho(partner *PartnerServer, msg protocol.AggregateEchoMessage) {
+	if server.threshold.checked {
+		return
+	}
+	for _, m := range msg.Aggregates {
+		agg, err := server.checkAggregate(m)
+		if err != nil {
+			fmt.Printf("[%s] \033[31mIgnoring aggregate of server %v echoed by %s: %v.\033[0m\n", server.ID, m.ServerID, partner.Id, err)
+			continue
+		}
+		server.storeAggregate(m.ServerID, agg)
+	}
+	if msg.Done {
+		if server.threshold.echoed == nil {
+			server.threshold.echoed = make(map[uint8]bool)
+		}
+		server.threshold.echoed[partner.ServerID] = true
+	}
+
+	// An echo may bring the aggregate of a server we did not get yet
+	if !server.didTally {
+		server.tryTally()
+		return
+	}
+	server.tryCrossCheck(false)
+}
+
+// Amount of servers whose part of the tally we have (aggregates if the ballots are encrypted, R-sums if not)
+func (server *Server) tallyParts() int {
+	if scheme.Encrypts(server.Scheme) {
+		return len(server.threshold.aggregates)
+	}
+	return len(server.RPoints)
+}
+
+// Echo the aggregates we got to the partners, and decrypt once theirs arrived (or time is up)
+func (server *Server) decryptTally() {
+	if server.threshold.key == nil {
+		server.Tally <- protocol.Results{Error: true, Code: protocol.ERR_KEYGEN_FAILED}
+		return
+	}
+	echo := protocol.AggregateEchoMessage{ServerID: server.ServerID, Done: true}
+	for id, agg := range server.threshold.aggregates {
+		echo.Aggregates = append(echo.Aggregates, agg.message(id))
+	}
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, echo.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to echo the aggregates to %s: %v\n", server.ID, partner.Id, e)
+		}
+	}
+	server.threshold.echoing = true
+	time.AfterFunc(server.PhaseTimeout, func() {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		server.tryCrossCheck(true)
+	})
+	server.tryCrossCheck(false)
+}
+
+// Check the aggregates once every partner echoed them (or with whoever did once time is up). The tally fails if a
+// server signed two different aggregates, as its voters cannot be counted.
+func (server *Server) tryCrossCheck(timedOut bool) {
+	if !server.threshold.echoing || server.threshold.checked {
+		return
+	}
+	if len(server.threshold.echoed) < server.tallyServers-1 && !timedOut {
+		return
+	}
+	server.threshold.checked = true
+	if len(server.threshold.conflicts) > 0 {
+		fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_AGGREGATE_CONFLICT, "%v server(s) signed two different aggregates", len(server.threshold.conflicts)))
+		server.Tally <- protocol.Results{Error: true, Code: protocol.ERR_AGGREGATE_CONFLICT}
+		return
+	}
+	server.decryptProduct()
+}
+
+// Multiply the aggregates of every server and send our partial decryption of the product to the partners
+func (server *Server) decryptProduct() {
+
+	// Multiply in order of ServerID (every server gets the same product)
+	ids := make([]int, 0, len(server.threshold.aggregates))
+	for id := range server.threshold.aggregates {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	total := elgamal.Zero()
+	for _, id := range ids {
+		agg := server.threshold.aggregates[uint8(id)]
+		total = total.Mul(agg.ballots)
+		server.threshold.voters += agg.voters
+	}
+	server.threshold.total = &total
+	for _, p := range server.threshold.pending {
+		server.checkPartial(p.partner, p.partial, p.proof)
+	}
+	server.threshold.pending = nil
+
+	// Send our partial decryption (proving it was done with our key share)
+	partial, proof, err := server.threshold.key.ProvePartial(rand.Reader, server.proofContext(), total)
+	if err != nil {
+		server.Tally <- protocol.Results{Error: true, Code: protocol.ERR_DECRYPTION_FAILED}
+		return
+	}
+	server.storePartial(server.ServerID, partial)
+	fmt.Printf("[%s] Decrypting the product of %v ballot(s) from %v server(s).\n", server.ID, server.threshold.voters, len(ids))
+	msg := protocol.PartialMessage{ServerID: server.ServerID, Partial: elgamal.Encode(partial), Proof: proof.Encode()}
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, msg.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to send partial decryption to %s: %v\n", server.ID, partner.Id, e)
+		}
+	}
+
+	// Decrypt with who answered in time, if not everyone does
+	time.AfterFunc(server.PhaseTimeout, func() {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		server.tryDecrypt(true)
+	})
+	server.tryDecrypt(false)
+}
+
+// Keep the partial decryption of a server (the first one it sent)
+func (server *Server) storePartial(id uint8, partial *big.Int) {
+	if server.threshold.partials == nil {
+		server.threshold.partials = make(map[uint8]*big.Int)
+	}
+	if _, exists := server.threshold.partials[id]; !exists {
+		server.threshold.partials[id] = partial
+	}
+}
+
+// Handle the partial decryption of a partner, which must be proven against its verification key
+func (server *Server) receivePartial(partner *PartnerServer, msg protocol.PartialMessage) {
+	if server.threshold.key == nil || server.threshold.rejected[partner.ServerID] {
+		return
+	}
+	partial, err := elgamal.Decode(msg.Partial)
+	if err != nil {
+		fmt.Printf("[%s] \033[31mIgnoring malformed partial decryption from %s: %v.\033[0m\n", server.ID, partner.Id, err)
+		return
+	}
+	proof, err := elgamal.DecodeEqualityProof(msg.Proof)
+	if err != nil {
+		fmt.Printf("[%s] \033[31mIgnoring malformed partial decryption from %s: %v.\033[0m\n", server.ID, partner.Id, err)
+		return
+	}
+
+	// The partials come after we tallied (so we know the product), as a partner only decrypts the same product
+	if server.threshold.total == nil {
+		server.threshold.pending = append(server.threshold.pending, pendingPartial{partner: partner.ServerID, partial: partial, proof: proof})
+		return
+	}
+	server.checkPartial(partner.ServerID, partial, proof)
+	server.tryDecrypt(false)
+}
+
+// A partial decryption that came before we had the product to check it against
+type pendingPartial struct {
+	partner uint8
+	partial *big.Int
+	proof   elgamal.EqualityProof
+}
+
+// The verification key of the server with the ServerID, from the commitments of the key generation
+func (server *Server) verificationKey(id uint8) *big.Int {
+	commitments := map[int][]*big.Int{}
+	for dealer, c := range server.threshold.commitments {
+		commitments[int(dealer)] = c
+	}
+	return elgamal.VerificationKey(commitments, int(id))
+}
+
+// What the proofs of the election are made for (its ID and public key), so they hold in no other election
+func (server *Server) proofContext() elgamal.Context {
+	ctx := elgamal.Context{Election: server.Election}
+	if server.threshold.key != nil {
+		ctx.Key = server.threshold.key.H
+	}
+	return ctx
+}
+
+// Keep the partial decryption of a partner if its proof holds, or blame the partner
+func (server *Server) checkPartial(id uint8, partial *big.Int, proof elgamal.EqualityProof) {
+	vk := server.verificationKey(id)
+	if elgamal.VerifyPartial(server.proofContext(), vk, *server.threshold.total, partial, proof) {
+		server.storePartial(id, partial)
+		return
+	}
+	if server.threshold.rejected == nil {
+		server.threshold.rejected = make(map[uint8]bool)
+	}
+	server.threshold.rejected[id] = true
+	server.blame(sharing.Point{X: int(id), Y: -1}, -1, protocol.BLAME_DECRYPTION)
+}
+
+// Decrypt the sum once every server sent its partial decryption (or with whoever did once time is up).
+// Every group of K+1 servers decrypts, and the tally strategy settles on the sum of the groups.
+func (server *Server) tryDecrypt(timedOut bool) {
+	if server.threshold.total == nil || server.threshold.done {
+		return
+	}
+	if len(server.threshold.partials)+len(server.threshold.rejected) < server.tallyServers && !timedOut {
+		return
+	}
+	server.threshold.done = true
+	if len(server.threshold.partials) < server.K+1 {
+		fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_TOO_FEW_SERVERS, "only got %v partial decryption(s) in time", len(server.threshold.partials)))
+		server.Tally <- protocol.Results{Error: true, Code: protocol.ERR_TOO_FEW_SERVERS}
+		return
+	}
+
+	// Decrypt with every group of K+1 servers
+	ids := make([]int, 0, len(server.threshold.partials))
+	for id := range server.threshold.partials {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	decryptions := make([]tally.Decryption, 0)
+	for _, group := range groupsOf(ids, server.K+1) {
+		partials := map[int]*big.Int{}
+		for _, id := range group {
+			partials[id] = server.threshold.partials[uint8(id)]
+		}
+		sum := -1
+		if gm, err := elgamal.Combine(*server.threshold.total, partials, server.K); err == nil {
+			if m, err := elgamal.DiscreteLog(gm, server.threshold.voters); err == nil {
+				sum = m
+			}
+		}
+		decryptions = append(decryptions, tally.Decryption{Group: group, Sum: sum})
+	}
+
+	// Settle on the sum (Variability point), and blame the servers caught lying
+	decrypter, ok := server.Strategy.(tally.Decrypter)
+	if !ok {
+		fmt.Printf("[%s] \033[31mThe %s strategy cannot tally decryptions.\033[0m\n", server.ID, server.Strategy.Name())
+		server.Tally <- protocol.Results{Error: true, Code: protocol.ERR_DECRYPTION_FAILED}
+		return
+	}
+	sum, diag := decrypter.TallyDecryptions(decryptions, tally.Params{Name: server.ID, ServerID: server.ServerID, P: server.P, K: server.K, Servers: server.Scheme.Servers()})
+	fmt.Printf("[%s] Tallied with %v.\n", server.ID, diag)
+	for _, b := range sum.Blames {
+		server.blame(b.Point, b.Expected, b.Method)
+	}
+	if sum.Code != protocol.ERR_NONE {
+		server.Tally <- protocol.Results{Error: true, Code: sum.Code}
+		return
+	}
+	server.Tally <- protocol.Results{Yes: sum.Yes, No: server.threshold.voters - sum.Yes}
+}
+
+// Every group of n of the ids (in order)
+func groupsOf(ids []int, n int) [][]int {
+	if n == 0 {
+		return [][]int{{}}
+	}
+	groups := make([][]int, 0)
+	for i := 0; i+n <= len(ids); i++ {
+		for _, rest := range groupsOf(ids[i+1:], n-1) {
+			groups = append(groups, append([]int{ids[i]}, rest...))
+		}
+	}
+	return groups
+}
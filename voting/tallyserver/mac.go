@@ -0,0 +1,221 @@
+package tallyserver
+
+import (
+	"fmt"
+	"time"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/spdz"
+)
+
+// State of the MAC check of the opened sum. Every server commits to its share of the check, and opens it once all
+// partners committed, so no server can pick its share after seeing the others.
+type macCheck struct {
+	pending *protocol.Results // The results waiting on the check (nil if not checking)
+	points  []sharing.Point   // The R-sums the sum was opened from
+
+	// Our share of the check, and our commitment to it
+	sigma  int
+	nonce  string
+	commit string
+	opened bool
+
+	// Commitments and openings of the partners (by ServerID, they may arrive before we tallied)
+	commits map[uint8]string
+	opens   map[uint8]protocol.MACOpenMessage
+}
+
+// Take the share of a voter (kept with the shares it cast before). With MACs the voter sends its masked vote, which
+// we turn into our share and its MAC. The share is stored unchecked, as shared ballots carry no proof that they
+// share 0 or 1 (only encrypted ballots are proven, see elgamal.ProveBit).
+func (server *Server) takeShare(voter *Voter, val, seq int) bool {
+	if scheme.Encrypts(server.Scheme) {
+		fmt.Printf("[%s] \033[31mRefusing share of %s, the ballots are encrypted.\033[0m\n", server.ID, voter.Id)
+		return false
+	}
+	share, mac := val, 0
+	if server.MACKey != nil && voter.Voted {
+		// Two votes masked with the same mask would show the servers if the vote changed
+		fmt.Printf("[%s] \033[31mRefusing recast of %s, its mask was already used.\033[0m\n", server.ID, voter.Id)
+		return false
+	}
+	if server.MACKey != nil {
+		var dealt bool
+		if share, mac, dealt = server.MACKey.Input(voter.Id, val); !dealt {
+			fmt.Printf("[%s] \033[31mRefusing vote of %s, no mask was dealt for the voter.\033[0m\n", server.ID, voter.Id)
+			return false
+		}
+	}
+	if voter.shares == nil {
+		voter.shares = map[int]castShare{}
+	}
+	voter.shares[seq] = castShare{RVal: share, MAC: mac, Masked: val}
+	voter.RVal, voter.MAC = share, mac
+	return true
+}
+
+// The value a client list entry is intersected as. With MACs every server is sent the same masked vote, so it goes
+// into the intersection along with the ballot: a voter sending the servers different masked votes is left out of the
+// intersection, instead of failing the MAC check (so a failed check means a server altered its share of the sum).
+func (server *Server) intersectedEntry(voters map[string]*Voter, entry string) string {
+	if server.MACKey == nil {
+		return entry
+	}
+	id, seq := splitBallot(entry)
+	if voter, exists := voters[id]; exists {
+		if share, exists := voter.shares[seq]; exists {
+			return fmt.Sprintf("%s=%d", entry, share.Masked)
+		}
+	}
+	return entry
+}
+
+// Start the MAC check of the opened sum, holding back the results until it passes
+func (server *Server) startMACCheck(results protocol.Results, opened int) {
+	server.mac.pending = &results
+	server.mac.points = results.Points
+	server.mac.sigma = server.MACKey.Sigma(server.SelfMSum, opened)
+	server.mac.commit, server.mac.nonce = spdz.Commit(server.mac.sigma)
+	fmt.Printf("[%s] Checking the MACs of the opened sum %v.\n", server.ID, opened)
+
+	// Commit to our share of the check
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, protocol.MACCommitMessage{ServerID: server.ServerID, Commitment: server.mac.commit}.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to send MAC commitment to %s: %v\n", server.ID, partner.Id, e)
+		}
+	}
+
+	// Give up on partners who never finish the check
+	time.AfterFunc(server.PhaseTimeout, server.macTimedOut)
+
+	// Commitments of partners may already have arrived
+	server.tryOpenMAC()
+}
+
+// Handle the commitment of a partner to its share of the check
+func (server *Server) receiveMACCommit(partner *PartnerServer, msg protocol.MACCommitMessage) {
+	if server.mac.commits == nil {
+		server.mac.commits = make(map[uint8]string)
+	}
+	if _, exists := server.mac.commits[partner.ServerID]; exists {
+		return
+	}
+	server.mac.commits[partner.ServerID] = msg.Commitment
+	server.tryOpenMAC()
+}
+
+// Handle the opening of a partner of its share of the check
+func (server *Server) receiveMACOpen(partner *PartnerServer, msg protocol.MACOpenMessage) {
+	if server.mac.opens == nil {
+		server.mac.opens = make(map[uint8]protocol.MACOpenMessage)
+	}
+	if _, exists := server.mac.opens[partner.ServerID]; exists {
+		return
+	}
+	server.mac.opens[partner.ServerID] = msg
+	server.tryCheckMAC()
+}
+
+// Get the ServerIDs of the partners whose R-sums the sum was opened from
+func (server *Server) macPartners() []uint8 {
+	ids := make([]uint8, 0, len(server.mac.points))
+	for _, pt := range server.mac.points {
+		if pt.X != int(server.ServerID) {
+			ids = append(ids, uint8(pt.X))
+		}
+	}
+	return ids
+}
+
+// Open our share of the check, once every partner committed to theirs
+func (server *Server) tryOpenMAC() {
+	if server.mac.pending == nil || server.mac.opened {
+		return
+	}
+	for _, id := range server.macPartners() {
+		if _, exists := server.mac.commits[id]; !exists {
+			return
+		}
+	}
+	server.mac.opened = true
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, protocol.MACOpenMessage{ServerID: server.ServerID, Sigma: server.mac.sigma, Nonce: server.mac.nonce}.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to send MAC opening to %s: %v\n", server.ID, partner.Id, e)
+		}
+	}
+	server.tryCheckMAC()
+}
+
+// Check the shares of the check add up to 0 once every partner opened its share, and publish the results if they do
+func (server *Server) tryCheckMAC() {
+	if server.mac.pending == nil || !server.mac.opened {
+		return
+	}
+	partners := server.macPartners()
+	for _, id := range partners {
+		if _, exists := server.mac.opens[id]; !exists {
+			return
+		}
+	}
+
+	// Every opening must match the commitment made before
+	sigmas := []int{server.mac.sigma}
+	for _, id := range partners {
+		open := server.mac.opens[id]
+		if !spdz.Opens(server.mac.commits[id], open.Sigma, open.Nonce) {
+			fmt.Printf("[%s] \033[31mServer %v opened a MAC share that does not match its commitment.\033[0m\n", server.ID, id)
+			server.blame(server.macPoint(id), -1, protocol.BLAME_MAC_COMMITMENT)
+			server.failMACCheck(fmt.Sprintf("server %v broke its MAC commitment", id))
+			return
+		}
+		sigmas = append(sigmas, open.Sigma)
+	}
+
+	// A share of the sum was altered (the servers tallied the same masked votes, see intersectedEntry)
+	if !spdz.Check(sigmas, server.P) {
+		if len(partners) == 1 {
+			server.blame(server.macPoint(partners[0]), -1, protocol.BLAME_MAC_CHECK)
+		} else {
+			fmt.Printf("[%s] \033[33mThe MAC check cannot tell which of %v partners altered its R-sum.\033[0m\n", server.ID, len(partners))
+		}
+		server.failMACCheck(fmt.Sprintf("the MAC shares %v do not add up to 0", sigmas))
+		return
+	}
+
+	// Publish the results
+	fmt.Printf("[%s] \033[32mThe MAC check of the opened sum passed.\033[0m\n", server.ID)
+	results := *server.mac.pending
+	server.mac.pending = nil
+	server.Tally <- results
+}
+
+// Get the R-sum point of the partner with the ServerID
+func (server *Server) macPoint(id uint8) sharing.Point {
+	for _, pt := range server.mac.points {
+		if pt.X == int(id) {
+			return pt
+		}
+	}
+	return sharing.Point{X: int(id)}
+}
+
+// Give up on the results held back by the MAC check
+func (server *Server) failMACCheck(detail string) {
+	fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_MAC_CHECK_FAILED, detail))
+	server.mac.pending = nil
+	server.Tally <- protocol.Results{Yes: 0, No: 0, Error: true, Code: protocol.ERR_MAC_CHECK_FAILED, Points: server.mac.points}
+}
+
+// Give up on the MAC check if partners did not finish it in time
+func (server *Server) macTimedOut() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.mac.pending == nil {
+		return
+	}
+	fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_TOO_FEW_SERVERS, "partners did not finish the MAC check in time"))
+	server.mac.pending = nil
+	server.Tally <- protocol.Results{Yes: 0, No: 0, Error: true, Code: protocol.ERR_TOO_FEW_SERVERS}
+}
@@ -0,0 +1,180 @@
+package tallyserver
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// How often we tell partners we are alive (a partner silent for HEARTBEAT_MISSES intervals is dropped)
+const HEARTBEAT_INTERVAL = 1 * time.Second
+const HEARTBEAT_MISSES = 4
+
+// Backoff between attempts to (re)connect to a partner
+const DIAL_BACKOFF_MIN = 100 * time.Millisecond
+const DIAL_BACKOFF_MAX = 5 * time.Second
+
+// Start the mesh of partner links. PartnerPorts lists the partner port of every server in order of ServerID,
+// so we listen on the port at our own ServerID (the last server may have none) and dial all the others.
+func (server *Server) startMesh() {
+	for i, port := range server.PartnerPorts {
+		if i == int(server.ServerID)-1 {
+			go server.InitServerSocket(server.ServerListener)
+		} else {
+			go server.maintainLink(server.PartnerIPs[i], port, uint8(i+1))
+		}
+	}
+}
+
+// Keep a link to the partner with the given ServerID, redialing with exponential backoff while it is down.
+// We stop once the voting is closed, as a partner can no longer join then.
+func (server *Server) maintainLink(ip, port string, serverID uint8) {
+	wait := time.Duration(0)
+	for {
+
+		// Wait (or stop if halted)
+		select {
+		case <-server.stop:
+			return
+		case <-time.After(wait):
+		}
+
+		// Check if we (still) need the link
+		server.mutex.Lock()
+		closed := server.votingClosed
+		linked := server.linkedTo(serverID)
+		server.mutex.Unlock()
+		if closed {
+			return
+		}
+		if linked {
+			wait = DIAL_BACKOFF_MIN
+			continue
+		}
+
+		// Dial, and handle the link until it drops (then redial right away)
+		if conn := server.ConnectToServer(ip, port); conn != nil && server.HandleServerPartnerConnect(conn, true) {
+			wait = DIAL_BACKOFF_MIN
+			continue
+		}
+
+		// Back off
+		wait *= 2
+		if wait < DIAL_BACKOFF_MIN {
+			wait = DIAL_BACKOFF_MIN
+		} else if wait > DIAL_BACKOFF_MAX {
+			wait = DIAL_BACKOFF_MAX
+		}
+
+	}
+}
+
+// Check if we have a link to the partner with the given ServerID
+func (server *Server) linkedTo(serverID uint8) bool {
+	for _, p := range server.PartnerConns {
+		if p.ServerID == serverID {
+			return true
+		}
+	}
+	return false
+}
+
+// Keep a single link to each partner. A new link dialed from the same side as the old one replaces it (the partner
+// reconnected), of two links dialed from each side we keep the one dialed by the lower ServerID (so both ends agree).
+func (server *Server) keepLink(partner *PartnerServer) bool {
+	old, exists := server.PartnerConns[partner.Id]
+	if !exists {
+		return true
+	}
+	if old.dialed != partner.dialed && server.dialerOf(old) < server.dialerOf(partner) {
+		fmt.Printf("[%s] Dropping duplicate link to partner %s.\n", server.ID, partner.Id)
+		return false
+	}
+	fmt.Printf("[%s] Replacing link to partner %s.\n", server.ID, partner.Id)
+	old.Connection.Close()
+	return true
+}
+
+// Check if a partner joins again over a link it already joined (a replayed join), which is ignored
+func (server *Server) rejoined(partner *PartnerServer) bool {
+	if partner.Id == "" {
+		return false
+	}
+	fmt.Printf("[%s] \033[33mIgnoring repeated join from partner %s.\033[0m\n", server.ID, partner.Id)
+	return true
+}
+
+// ServerID of the server who dialed a partner link
+func (server *Server) dialerOf(partner *PartnerServer) uint8 {
+	if partner.dialed {
+		return server.ServerID
+	}
+	return partner.ServerID
+}
+
+// Send heartbeats over a partner link until it is done, and close it if the partner was silent for too long
+func (server *Server) keepAlive(conn protocol.Conn, lastSeen *int64, done chan struct{}) {
+	ticker := time.NewTicker(server.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-server.stop:
+			return
+		case <-ticker.C:
+		}
+		if time.Since(time.Unix(0, atomic.LoadInt64(lastSeen))) > HEARTBEAT_MISSES*server.HeartbeatInterval {
+			fmt.Printf("[%s] \033[33mNo heartbeat from partner at %s, dropping the link.\033[0m\n", server.ID, conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+		conn.Send(protocol.Request{RequestType: protocol.HEARTBEAT})
+	}
+}
+
+// Milliseconds left of our voting period (-1 if it is not started)
+func (server *Server) voteLeft() int {
+	if !server.votePeriodStarted {
+		return -1
+	}
+	if left := time.Until(server.voteDeadline); left > 0 {
+		return int(left / time.Millisecond)
+	}
+	return 0
+}
+
+// Tell partners when our voting period ends (they move their deadline to ours if it is earlier)
+func (server *Server) announceVotePeriod() {
+	for _, p := range server.PartnerConns {
+		e := p.Connection.Send(protocol.Request{RequestType: protocol.VOTEPERIOD, Val1: server.voteLeft()})
+		if e != nil {
+			fmt.Printf("[%s] Failed to tell %s when the voting period ends: %v\n", server.ID, p.Id, e)
+		}
+	}
+}
+
+// Agree on the end of the voting period with a partner. We join its period if we did not start one (we were restarted,
+// or joined late), and move our deadline to the partner's if it ends first, so every server closes the voting at the
+// earliest deadline (there is no main server to close it for the others).
+func (server *Server) syncVotePeriod(partner string, left int) {
+	if left < 0 || server.votingClosed {
+		return
+	}
+	deadline := time.Now().Add(time.Duration(left) * time.Millisecond)
+	if !server.votePeriodStarted {
+		server.voteDeadline = deadline
+		fmt.Printf("[%s] Joining the voting period of %s, which ends in %v.\n", server.ID, partner, time.Until(server.voteDeadline).Round(time.Millisecond))
+		server.startVotePeriod()
+		return
+	}
+
+	// Ignore differences below the millisecond (voteLeft is rounded down)
+	if deadline.Before(server.voteDeadline.Add(-time.Millisecond)) {
+		fmt.Printf("[%s] Moving the end of the voting period %v earlier, to agree with %s.\n", server.ID, server.voteDeadline.Sub(deadline).Round(time.Millisecond), partner)
+		server.voteDeadline = deadline
+		go server.waitTime()
+	}
+}
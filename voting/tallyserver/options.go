@@ -0,0 +1,144 @@
+package tallyserver
+
+import (
+	"crypto/ed25519"
+	"time"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/spdz"
+	"cs.au.dk/voting/tally"
+)
+
+// Option configuring a server (see New)
+type Option func(*Server)
+
+// Set the ServerID (1 up to the amount of servers of the scheme) and the name of the server
+func WithID(serverID int, name string) Option {
+	return func(server *Server) {
+		server.ServerID = uint8(serverID)
+		server.ID = name
+	}
+}
+
+// Set our IP and the port voters connect to
+func WithListen(selfIP, listenPort string) Option {
+	return func(server *Server) {
+		server.SelfIP = selfIP
+		server.ListenPort = listenPort
+	}
+}
+
+// Set the partner ports of every server in order of ServerID, and their IPs (missing IPs are the same as the first)
+func WithPartners(partnerIPs, partnerPorts []string) Option {
+	return func(server *Server) {
+		server.PartnerIPs = partnerIPs
+		server.PartnerPorts = partnerPorts
+	}
+}
+
+// Set the secret sharing scheme of the election
+func WithScheme(s scheme.Scheme) Option {
+	return func(server *Server) {
+		server.Scheme = s
+	}
+}
+
+// Set the tally strategy of the election (the default of the scheme if not set)
+func WithStrategy(s tally.Strategy) Option {
+	return func(server *Server) {
+		server.Strategy = s
+	}
+}
+
+// Set the degree of the polynomium the votes are shared with
+func WithDegree(k int) Option {
+	return func(server *Server) {
+		server.K = k
+	}
+}
+
+// Check the tally with SPDZ MACs, using our key from the dealer (additive shares only)
+func WithMACKey(key *spdz.ServerKey) Option {
+	return func(server *Server) {
+		server.MACKey = key
+	}
+}
+
+// Refuse encrypted ballots without a proof that they encrypt 0 or 1 (attached proofs are checked either way)
+func WithProofs(required bool) Option {
+	return func(server *Server) {
+		server.RequireProofs = required
+	}
+}
+
+// Let voters join with a credential from the registration authority instead of their ID (refusing voters without one)
+func WithAuthority(authority *credential.PublicKey) Option {
+	return func(server *Server) {
+		server.Authority = authority
+	}
+}
+
+// Set the ID of the election the receipts of the voters are made for
+func WithElection(election string) Option {
+	return func(server *Server) {
+		server.Election = election
+	}
+}
+
+// Sign the receipts of the voters with our published key (a key is drawn at start if not set, which voters cannot pin)
+func WithReceiptKey(key ed25519.PrivateKey) Option {
+	return func(server *Server) {
+		server.receiptKey = key
+	}
+}
+
+// Set the length of the voting period in seconds
+func WithVoteTime(seconds int) Option {
+	return func(server *Server) {
+		server.VoteTime = seconds
+	}
+}
+
+// Set the prime of the field
+func WithPrime(prime int) Option {
+	return func(server *Server) {
+		server.P = prime
+	}
+}
+
+// Set how long partners get to join, and to answer in each phase
+func WithPhaseTimeout(timeout time.Duration) Option {
+	return func(server *Server) {
+		server.PhaseTimeout = timeout
+	}
+}
+
+// Set how often heartbeats are sent to partners
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(server *Server) {
+		server.HeartbeatInterval = interval
+	}
+}
+
+// Set how the server reaches partners and is reached by voters
+func WithTransport(transport protocol.Transport) Option {
+	return func(server *Server) {
+		server.Transport = transport
+	}
+}
+
+// Keep blame reports in the store, refusing partners blamed in earlier elections
+func WithBlames(blames *BlameStore) Option {
+	return func(server *Server) {
+		server.Blames = blames
+	}
+}
+
+// Make the server misbehave (for testing and simulations)
+func WithBehaviours(behaviours ...Behaviour) Option {
+	return func(server *Server) {
+		server.Behaviours = append(server.Behaviours, behaviours...)
+	}
+}
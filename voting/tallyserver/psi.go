@@ -0,0 +1,82 @@
+package tallyserver
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// Private set intersection (PSI) of client lists, using commutative (Diffie-Hellman) blinding.
+// A voter ID x is hashed into the group and blinded by every server in turn, giving H(x)^(k1*k2*...*kn).
+// As exponentiation commutes, the fully blinded values of a voter are the same no matter whose list it came from,
+// while no server can tell which voters the blinded values of another server stand for.
+
+// Safe prime p = 2q+1 (RFC 3526, 2048-bit MODP group 14), we work in the subgroup of quadratic residues (order q)
+var psiP, _ = new(big.Int).SetString(
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1"+
+		"29024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245"+
+		"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D"+
+		"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F"+
+		"83655D23DCA3AD961C62F356208552BB9ED529077096966D"+
+		"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+		"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9"+
+		"DE2BCBF6955817183995497CEA956AE515D2261898FA0510"+
+		"15728E5A8AACAA68FFFFFFFFFFFFFFFF", 16)
+
+// Size of the secret blinding exponents in bits
+const PSI_KEY_BITS = 256
+
+// Secret blinding key of a server
+type PSIKey struct {
+	k *big.Int
+}
+
+// Create a new random blinding key
+func NewPSIKey() (*PSIKey, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), PSI_KEY_BITS))
+	if err != nil {
+		return nil, err
+	}
+	return &PSIKey{k: k.Add(k, big.NewInt(1))}, nil
+}
+
+// Hash a voter ID into the group (hash, then square to land among the quadratic residues)
+func PSIHash(id string) *big.Int {
+	digest := sha256.Sum256([]byte(id))
+	h := new(big.Int).SetBytes(digest[:])
+	return h.Exp(h, big.NewInt(2), psiP)
+}
+
+// Blind values with the key (v^k mod p)
+func (key *PSIKey) Blind(values []*big.Int) []*big.Int {
+	blinded := make([]*big.Int, len(values))
+	for i, v := range values {
+		blinded[i] = new(big.Int).Exp(v, key.k, psiP)
+	}
+	return blinded
+}
+
+// Encode group elements for sending
+func EncodePSI(values []*big.Int) []string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = v.Text(16)
+	}
+	return strs
+}
+
+// Decode group elements, failing on anything outside the group
+func DecodePSI(strs []string) ([]*big.Int, error) {
+	values := make([]*big.Int, len(strs))
+	for i, s := range strs {
+		v, ok := new(big.Int).SetString(s, 16)
+		if !ok || v.Cmp(big.NewInt(1)) <= 0 || v.Cmp(psiP) >= 0 {
+			return nil, fmt.Errorf("'%s' is not a blinded voter", s)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
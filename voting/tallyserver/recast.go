@@ -0,0 +1,130 @@
+package tallyserver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// A voter may recast its ballot any number of times during the voting period, and the last ballot counts. Every
+// ballot carries a sequence number, and a ballot is only taken if its number is higher than the last one we took.
+// With shared votes a recast may reach some servers but not others, so we keep every share a voter cast, and list
+// each of them (as ID#seq) in the blinded client list. The intersection then holds the ballots every server has, so
+// every server counts the same (the last ballot held by all). An encrypted ballot is cast at a single server, which
+// decides on its own.
+
+// A share the voter cast, and our share of its MAC and the masked vote it came from (when the tally is MAC checked)
+type castShare struct {
+	RVal   int
+	MAC    int
+	Masked int
+}
+
+// The client list entry of a ballot
+func ballotEntry(id string, seq int) string {
+	return fmt.Sprintf("%s#%d", id, seq)
+}
+
+// The voter and sequence number of a client list entry (an entry without a sequence number is ballot 0)
+func splitBallot(entry string) (string, int) {
+	if i := strings.LastIndex(entry, "#"); i >= 0 {
+		if seq, err := strconv.Atoi(entry[i+1:]); err == nil {
+			return entry[:i], seq
+		}
+	}
+	return entry, 0
+}
+
+// Take a rejoining voter over the new connection, if it shows the key it first joined with (true if it rejoined)
+func (server *Server) rejoin(conn protocol.Conn, addr, id, recastKey string) bool {
+
+	// Find the voter registered under the ID
+	for oldAddr, voter := range server.Clientsconnections {
+		if voter.Id != id {
+			continue
+		}
+		if recastKey == "" || recastKey != voter.recastKey || server.votingClosed || server.didTally {
+			return false
+		}
+
+		// Move the voter to the new connection (dropping the old one)
+		fmt.Printf("[%s] Voter %s rejoined.\n", server.ID, id)
+		if oldAddr != addr {
+			voter.Connection.Close()
+			delete(server.Clientsconnections, oldAddr)
+		}
+		voter.Connection = conn
+		voter.keySent = false
+		server.Clientsconnections[addr] = voter
+		server.sendToVoter(voter, protocol.Request{RequestType: protocol.ID, Val1: int(server.ServerID)})
+		server.sendKey(voter)
+		return true
+
+	}
+	return false
+
+}
+
+// Mark the ballot with the sequence number as the last ballot of the voter
+func (server *Server) castBallot(voter *Voter, seq int) {
+	if voter.Voted {
+		fmt.Printf("[%s] %s recast its ballot (ballot %v).\n", server.ID, voter.Id, seq)
+	}
+	voter.Voted = true
+	voter.Seq = seq
+}
+
+// Count the last ballot of every voter that is held by all servers (the intersection of the ballots of the client
+// lists), and exclude the voters none of whose ballots are.
+func (server *Server) agreeOnBallots(common []string, excluded map[string]string) ([]string, map[string]string) {
+
+	// The last ballot held by all
+	counted := map[string]int{}
+	for _, entry := range common {
+		id, seq := splitBallot(entry)
+		if last, exists := counted[id]; !exists || seq > last {
+			counted[id] = seq
+		}
+	}
+
+	// Take the share of the ballot we count
+	voters := map[string]*Voter{}
+	for _, v := range server.Clientsconnections {
+		voters[v.Id] = v
+	}
+	ids := make([]string, 0, len(counted))
+	for id, seq := range counted {
+		ids = append(ids, id)
+		voter, exists := voters[id]
+		if !exists {
+			continue
+		}
+		if seq != voter.Seq {
+			fmt.Printf("[%s] \033[33mCounting ballot %v of %s, as ballot %v did not reach every server.\033[0m\n", server.ID, seq, id, voter.Seq)
+		}
+		if share, exists := voter.shares[seq]; exists {
+			voter.RVal, voter.MAC, voter.Seq = share.RVal, share.MAC, seq
+		}
+	}
+	sort.Strings(ids)
+
+	// Voters with no ballot held by all are excluded (for why their last ballot is missing)
+	reasons := map[string]string{}
+	last := map[string]int{}
+	for entry, reason := range excluded {
+		id, seq := splitBallot(entry)
+		if _, exists := counted[id]; exists {
+			continue
+		}
+		if s, exists := last[id]; !exists || seq > s {
+			last[id] = seq
+			reasons[id] = reason
+		}
+	}
+
+	return ids, reasons
+
+}
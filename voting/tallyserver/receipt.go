@@ -0,0 +1,60 @@
+package tallyserver
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// Every share (or encrypted ballot) we take is answered with a receipt, signed with the key published for us before
+// the election (see WithReceiptKey), holding the hash of our ServerID and key, the election, the voter, the commitment
+// to the share and the time. The hashes of the receipts of the ballots we tallied are published along with the tally,
+// so a voter can check its ballot was tallied.
+
+// Give the voter a receipt of the share with the sequence number
+func (server *Server) sendReceipt(voter *Voter, seq int, commitment string) {
+	receipt := protocol.Receipt{
+		ServerID:   server.ServerID,
+		Seq:        seq,
+		Election:   server.Election,
+		Voter:      voter.Id,
+		Commitment: commitment,
+		Time:       time.Now().UTC().Format(time.RFC3339Nano),
+	}.Sign(server.receiptKey)
+	if voter.receipts == nil {
+		voter.receipts = map[int]string{}
+	}
+	voter.receipts[seq] = receipt.Hash()
+	if e := server.sendToVoter(voter, receipt.ToRequest()); e != nil {
+		fmt.Printf("[%s] Failed to send the receipt to %s.\n", server.ID, voter.Id)
+	}
+}
+
+// The public key our receipts are signed with (hex)
+func (server *Server) ReceiptKey() string {
+	if server.receiptKey == nil {
+		return ""
+	}
+	return hex.EncodeToString(server.receiptKey.Public().(ed25519.PublicKey))
+}
+
+// Hashes of the receipts of the ballots we tallied (in order)
+func (server *Server) talliedReceipts() []string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	receipts := make([]string, 0)
+	for _, v := range server.Clientsconnections {
+		if _, exists := server.VoterIntersection[v.Id]; !exists {
+			continue
+		}
+		if receipt, exists := v.receipts[v.Seq]; exists {
+			receipts = append(receipts, receipt)
+		}
+	}
+	sort.Strings(receipts)
+	return receipts
+}
@@ -0,0 +1,50 @@
+package tallyserver
+
+import (
+	"fmt"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// Voters need not stay connected until the tally. A voter may hang up once it has the receipts of its ballots, and
+// ask every server for the results later (over a new connection, without joining). We answer with the blame reports
+// and the results we published (ERR_TALLY_PENDING until we tallied), so the voter can verify them against each other.
+
+// Note the voter hung up (its ballot is still tallied, but the results are not pushed to it)
+func (server *Server) voterHungUp(addr string, conn protocol.Conn) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if voter, exists := server.Clientsconnections[addr]; exists && voter.Connection == conn {
+		voter.hungUp = true
+	}
+}
+
+// Answer a query for the results of the election
+func (server *Server) answerResultQuery(conn protocol.Conn) {
+
+	// The querier is not a voter we know (anyone may ask), but a bad server lies to it all the same
+	querier := &Voter{Id: "querier@" + conn.RemoteAddr(), Connection: conn}
+
+	// Not tallied yet
+	server.resultsMutex.Lock()
+	results := server.results
+	server.resultsMutex.Unlock()
+	if results == nil {
+		fmt.Printf("[%s] Asked for the results before the tally.\n", server.ID)
+		server.sendToVoter(querier, protocol.Results{Error: true, Code: protocol.ERR_TALLY_PENDING}.ToRequest())
+		return
+	}
+
+	// The servers we caught lying, then the results
+	fmt.Printf("[%s] Answering a query for the results.\n", server.ID)
+	for _, report := range server.BlameReports() {
+		if e := server.sendToVoter(querier, report.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to send blame report to %s.\n", server.ID, querier.Id)
+			return
+		}
+	}
+	if e := server.sendToVoter(querier, results.ToRequest()); e != nil {
+		fmt.Printf("[%s] Failed to send the results to %s.\n", server.ID, querier.Id)
+	}
+
+}
@@ -0,0 +1,1561 @@
+package tallyserver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/elgamal"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/spdz"
+	"cs.au.dk/voting/tally"
+)
+
+// Struct for a voter instance
+type Voter struct {
+
+	// Connection to voter
+	Connection protocol.Conn
+
+	// ID
+	Id string
+
+	// The secret share
+	RVal int
+
+	// Our share of the MAC of the secret share (when the tally is MAC checked)
+	MAC int
+
+	// The encrypted ballot (when the ballots are encrypted), and whether the voter was sent the public key
+	Ballot  *elgamal.Ciphertext
+	keySent bool
+
+	// Flag marking if the voter sent its share
+	Voted bool
+
+	// Sequence number of the last ballot of the voter (the one counted, once the servers agreed), every share it cast
+	// by sequence number (the servers agree on which one counts), and the key the voter must show to rejoin
+	Seq       int
+	shares    map[int]castShare
+	recastKey string
+
+	// Hashes of the receipts we gave the voter, by sequence number
+	receipts map[int]string
+
+	// Flag marking if the voter hung up (to fetch the results later)
+	hungUp bool
+}
+
+//Struct for a partner instance
+type PartnerServer struct {
+	// Connection to Server
+	Connection protocol.Conn
+
+	// ID
+	Id       string
+	ServerID uint8
+
+	// The secret share  // Is this needed?
+	//RVal int
+
+	// Our voters who also voted at the partner (found by private set intersection)
+	clientList []string
+
+	//Checked ClientList
+	comparedClients bool
+
+	// Flag marking we dialed the link (rather than the partner)
+	dialed bool
+}
+
+type ConnectionMap map[string]*Voter
+type ServerConnectionMap map[string]*PartnerServer
+
+// How long partners get to answer in each phase of the protocol, before we go ahead without them
+const PHASE_TIMEOUT = 10 * time.Second
+
+// Defaults of the voting period (in seconds) and the prime
+const VOTE_TIME = 15
+const PRIME = 1997
+
+// Function pointers for variability points
+type RSumPtr func(*Server) int
+type IntersectPtr func(*Server, map[string][]string) ([]string, map[string]string)
+
+// Struct for server instance
+type Server struct {
+
+	// Connection to the Pertner Servers
+	PartnerConns ServerConnectionMap
+
+	// (Global) map of all Clients connections
+	Clientsconnections ConnectionMap
+
+	// Mutex.locks
+	mutex *sync.Mutex
+
+	// Name of server (For debugging identification)
+	ID       string
+	ServerID uint8
+
+	// Self ip
+	SelfIP     string   // self IP
+	PartnerIPs []string // IP of partner address
+
+	// Port vals
+	ListenPort   string   // Port to listen for client/voter input
+	PartnerPorts []string // Port to listen and connect to on partners end
+
+	// The time in seconds to vote
+	VoteTime int
+
+	// How long partners get to join, and to answer in each phase, before we go ahead without them
+	PhaseTimeout time.Duration
+
+	// How often we send heartbeats to partners
+	HeartbeatInterval time.Duration
+
+	// Create channel for tally
+	Tally chan protocol.Results
+
+	// Self R-value sum
+	SelfRSum int
+
+	// Channel for all points (alpha_i, r_i)
+	RPoints chan sharing.Point
+
+	// The R-sum each partner sent us (a partner only has one, so repeats are not counted), and the name of who sent it
+	rsums       map[uint8]int
+	rsumSenders map[uint8]string
+
+	// The P value
+	P int
+
+	VoterIntersection protocol.StringHashSet
+
+	// Voters left out of the tally (voter ID -> reason)
+	ExcludedVoters map[string]string
+
+	ClientListener protocol.Listener
+	ServerListener protocol.Listener
+
+	// How the server reaches partners and is reached by voters
+	Transport protocol.Transport
+
+	serverThresshold int
+
+	// Least amount of live servers needed to tally (points to interpolate)
+	minServers int
+
+	// Flag marking the voting period was started (once all partners joined, or the join timed out)
+	votePeriodStarted bool
+	voteDeadline      time.Time
+
+	// Closed once the server is halted (stops reconnecting to partners)
+	stop     chan struct{}
+	haltOnce sync.Once
+
+	// Amount of servers who shared their R-sum (the servers online once the voters were agreed on)
+	tallyServers int
+
+	// Current phase of the protocol (a phase timer only fires for the phase it was started in)
+	phase int
+
+	//Summed the Votes
+	didSum bool
+
+	// Flag marking the voting period is over (no more registrations or votes)
+	votingClosed bool
+
+	// Our own client list (once sent to partners)
+	ownClients  []string
+	sentClients bool
+
+	// Private set intersection of client lists (our blinding key, and the fully blinded list of each server)
+	psiKey   *PSIKey
+	psiLists map[uint8][]string
+
+	// How far the list of each server got along its route (the amount of servers known to have blinded it)
+	psiProgress map[uint8]int
+
+	// Our own blinded list (resent if the intersection restarts), and lists of partners
+	// who noticed a server went offline before we did
+	psiOwn     []string
+	psiPending []pendingPSI
+
+	// Flag marking the tally was done
+	didTally bool
+
+	// The secret sharing scheme of the election (decides the amount of servers), and how the R-sums are tallied
+	Scheme   scheme.Scheme
+	Strategy tally.Strategy
+
+	// Degree of the polynomium the votes are shared with
+	K int
+
+	// Our key from the dealer if the tally is MAC checked (nil if not), and the state of the MAC check
+	MACKey   *spdz.ServerKey
+	SelfMSum int
+	mac      macCheck
+
+	// State of the key generation and decryption (when the ballots are encrypted), and whether ballots must come
+	// with a proof that they encrypt 0 or 1
+	threshold     thresholdTally
+	RequireProofs bool
+
+	// Public key of the registration authority if voters join with a credential instead of their ID (nil if not)
+	Authority *credential.PublicKey
+
+	// ID of the election the receipts are made for, and the key we sign them with
+	Election   string
+	receiptKey ed25519.PrivateKey
+
+	//Variable points
+	SumCalculation RSumPtr
+	IntersectFunc  IntersectPtr
+
+	// Byzantine behaviours (none for an honest server)
+	Behaviours []Behaviour
+
+	// Blame reports of earlier elections (nil if not kept), and the reports we made in this election
+	Blames       *BlameStore
+	blameReports []protocol.BlameReport
+
+	// The results once published, and who waits for them
+	resultsMutex sync.Mutex
+	results      *protocol.Results
+	subscribers  []chan protocol.Results
+}
+
+// Blinded list (or notice of its progress) kept until we notice the same servers went offline as its sender did
+type pendingPSI struct {
+	partner  *PartnerServer
+	msg      protocol.PSIMessage
+	progress *protocol.PSIProgressMessage
+}
+
+// Accept voter connections on the listener until it is closed
+func (server *Server) InitClientSocket(ln protocol.Listener) {
+
+	// Close connection
+	defer ln.Close()
+
+	// Log we're listening
+	fmt.Printf("[%s] Listening on IP and Port: %s\n", server.ID, ln.Addr())
+
+	// While running - accept incoming client/voter connections
+	for {
+
+		// Accept
+		conn, err := ln.Accept()
+		if err != nil { // error checking
+			return
+		}
+
+		// Handle connection
+		go server.HandleVoterConnection(conn)
+
+	}
+
+}
+
+// Accept partner connections on the listener until it is closed
+func (server *Server) InitServerSocket(ln protocol.Listener) {
+
+	// Close connection
+	defer ln.Close()
+
+	// Log we're listening
+	fmt.Printf("[%s] Listening on IP and Port: %s for other server.\n\n", server.ID, ln.Addr())
+
+	// While running - accept incoming partner server connections
+	for {
+
+		// Accept
+		conn, err := ln.Accept()
+		if err != nil { // error checking
+			return
+		}
+
+		// Handle connection
+		go server.HandleServerPartnerConnect(conn, false)
+
+	}
+}
+
+func (server *Server) HandleVoterConnection(conn protocol.Conn) {
+
+	//Cleans up after connection finish
+	defer conn.Close()
+
+	// Grab key
+	voterAddr := conn.RemoteAddr()
+
+	// Handle voter/client stuff
+	for {
+		newRequest, e := conn.Receive()
+		if e != nil {
+			// The voter hung up, or we closed the connection (a rejoin, or we stopped), nothing more will arrive
+			if !errors.Is(e, io.EOF) {
+				fmt.Printf("[%s] Connection to voter at %s failed: %v\n", server.ID, voterAddr, e)
+			}
+			server.voterHungUp(voterAddr, conn)
+			return
+		} else {
+			switch newRequest.RequestType {
+			case protocol.CLIENTJOIN:
+				server.mutex.Lock()
+				join := newRequest.ToClientJoinMsg()
+				id, ok := server.joiningVoter(conn, join)
+				if ok && server.rejoin(conn, voterAddr, id, join.Recast) {
+					server.mutex.Unlock()
+					continue
+				}
+				if !ok || !server.acceptsRegistration(id) {
+					server.mutex.Unlock()
+					return
+				}
+				voter := Voter{
+					Id:         id,
+					Connection: conn,
+					recastKey:  join.Recast,
+				}
+				server.Clientsconnections[voterAddr] = &voter
+				fmt.Printf("[%s] Registered new voter.\n", server.ID)
+				server.sendToVoter(&voter, protocol.Request{RequestType: protocol.ID, Val1: int(server.ServerID)})
+				server.sendKey(&voter)
+				server.mutex.Unlock()
+				// Would be here where more stuff would be handled like identification, some exchange of keys etc.
+			case protocol.RNUMBER:
+				// As r message
+				rm := newRequest.ToRMsg()
+				server.mutex.Lock()
+				if voter, exists := server.Clientsconnections[voterAddr]; exists {
+					if server.acceptsVote(voter, rm.Seq) && server.takeShare(voter, rm.Vote, rm.Seq) {
+						server.castBallot(voter, rm.Seq)
+						server.sendReceipt(voter, rm.Seq, protocol.CommitShare(rm.Vote, rm.Seq, rm.Salt))
+					}
+				} else {
+					fmt.Printf("[%s] Unregistered voter attempted to vote!\n", server.ID)
+				}
+				server.mutex.Unlock()
+			case protocol.BALLOT:
+				server.mutex.Lock()
+				if voter, exists := server.Clientsconnections[voterAddr]; exists {
+					if ballot := newRequest.ToBallotMsg(); server.acceptsVote(voter, ballot.Seq) && server.takeBallot(voter, ballot) {
+						server.castBallot(voter, ballot.Seq)
+						server.sendReceipt(voter, ballot.Seq, protocol.CommitBallot(ballot.Ciphertext, ballot.Seq))
+					}
+				} else {
+					fmt.Printf("[%s] Unregistered voter attempted to vote!\n", server.ID)
+				}
+				server.mutex.Unlock()
+			case protocol.RESULT:
+				server.answerResultQuery(conn)
+			}
+		}
+	}
+}
+
+// Check if a voter may register (must be during the voting period, before the tally, and the ID must be unused)
+func (server *Server) acceptsRegistration(id string) bool {
+	if server.votingClosed {
+		fmt.Printf("[%s] \033[31mRefusing registration of %s after the voting period.\033[0m\n", server.ID, id)
+		return false
+	}
+	if server.didTally {
+		fmt.Printf("[%s] \033[31mRefusing registration of %s, the tally already failed.\033[0m\n", server.ID, id)
+		return false
+	}
+	for _, v := range server.Clientsconnections {
+		if v.Id == id {
+			fmt.Printf("[%s] \033[31mRefusing registration of %s, the ID is already registered.\033[0m\n", server.ID, id)
+			return false
+		}
+	}
+	return true
+}
+
+// Check if a voter may vote (must be during the voting period, and a recast must come after the ballots before it)
+func (server *Server) acceptsVote(voter *Voter, seq int) bool {
+	if server.votingClosed {
+		fmt.Printf("[%s] \033[31mRefusing vote of %s after the voting period.\033[0m\n", server.ID, voter.Id)
+		return false
+	}
+	if voter.Voted && seq <= voter.Seq {
+		fmt.Printf("[%s] \033[31mRefusing ballot %v of %s, it already cast ballot %v.\033[0m\n", server.ID, seq, voter.Id, voter.Seq)
+		return false
+	}
+	return true
+}
+
+// Handle a partner link until it drops, returning true if the partner joined over it
+func (server *Server) HandleServerPartnerConnect(conn protocol.Conn, dialed bool) (joined bool) {
+
+	var Pserver PartnerServer
+
+	//Cleans up after connection finish (going ahead without the partner)
+	defer conn.Close()
+	defer server.partnerLost(&Pserver)
+
+	// Keep the link alive (anything received counts as a heartbeat)
+	lastSeen := time.Now().UnixNano()
+	done := make(chan struct{})
+	defer close(done)
+	go server.keepAlive(conn, &lastSeen, done)
+
+	// Handle incoming from partner connection
+	for {
+
+		newRequest, e := conn.Receive()
+		atomic.StoreInt64(&lastSeen, time.Now().UnixNano())
+		if e != nil {
+			if errors.Is(e, io.EOF) {
+				fmt.Printf("[%s] Connection closed to partner [%s] (EOF).\n", server.ID, Pserver.Id)
+			} else {
+				fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_PARTNER_LOST, "failed to read from partner [%s]: %v", Pserver.Id, e))
+			}
+			return
+		}
+
+		switch newRequest.RequestType {
+		case protocol.SERVERJOIN:
+			server.mutex.Lock()
+			sID := newRequest.ToServerJoinMsg().ID
+			if server.rejoined(&Pserver) {
+				server.mutex.Unlock()
+				continue
+			}
+			if server.serverIDTaken(sID, uint8(newRequest.Val1)) || server.refusedPeer(sID, uint8(newRequest.Val1)) || server.joinedLate(sID) {
+				server.mutex.Unlock()
+				return
+			}
+			fmt.Printf("[%s] Connected with partner server with ID: %s.\n", server.ID, sID)
+			Pserver = PartnerServer{
+				Id:         newRequest.Strs[0],
+				ServerID:   uint8(newRequest.Val1),
+				Connection: conn,
+				dialed:     dialed,
+			}
+			if !server.keepLink(&Pserver) {
+				server.mutex.Unlock()
+				return
+			}
+			server.PartnerConns[sID] = &Pserver
+			joined = true
+			e := server.sendToPartner(&Pserver, server.joinMessage().ToResponse())
+			if e != nil {
+				fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_PARTNER_LOST, "failed to answer join of %s: %v", sID, e))
+				delete(server.PartnerConns, sID)
+				server.mutex.Unlock()
+				return
+			}
+			server.dealTo(&Pserver)
+			server.syncVotePeriod(sID, newRequest.ToServerJoinMsg().VoteLeft)
+			if server.serverThresshold <= len(server.PartnerConns) {
+				server.startVotePeriod()
+			}
+			server.mutex.Unlock()
+		case protocol.RNUMBER:
+			// We get r-value from partner, and "terminate"
+			rm := newRequest.ToRMsg()
+			server.mutex.Lock()
+			if server.didTally {
+				fmt.Printf("[%s] Ignoring R-tally number from [%s], which came after the tally.\n", server.ID, Pserver.Id)
+				server.mutex.Unlock()
+				continue
+			}
+			if rsum, exists := server.rsums[Pserver.ServerID]; exists {
+				if rsum != rm.Vote {
+					fmt.Printf("[%s] \033[31mIgnoring another R-tally number from [%s]: %v, after %v.\033[0m\n", server.ID, Pserver.Id, rm.Vote, rsum)
+				} else {
+					fmt.Printf("[%s] \033[33mIgnoring repeated R-tally number from [%s].\033[0m\n", server.ID, Pserver.Id)
+				}
+				server.mutex.Unlock()
+				continue
+			}
+			fmt.Printf("[%s] Got a R-tally number from [%s]: %v.\n", server.ID, Pserver.Id, rm.Vote)
+			server.rsums[Pserver.ServerID] = rm.Vote
+			server.rsumSenders[Pserver.ServerID] = Pserver.Id
+			server.RPoints <- sharing.Point{X: int(Pserver.ServerID), Y: rm.Vote}
+			fmt.Printf("[%v] Amount of Points gathered: %v\n", server.ID, len(server.RPoints))
+			server.tryTally()
+			server.mutex.Unlock()
+		case protocol.INTERSECTION:
+			server.mutex.Lock()
+			// Share our own list (if not already), and help blinding the list
+			server.shareClientList()
+			server.receivePSI(&Pserver, newRequest.ToPSIMsg())
+			// Agree on the voters once we have all lists
+			server.reconcileClients()
+			server.mutex.Unlock()
+		case protocol.PSIPROGRESS:
+			server.mutex.Lock()
+			server.receivePSIProgress(&Pserver, newRequest.ToPSIProgressMsg())
+			server.mutex.Unlock()
+
+		case protocol.SERVERRESPONCE:
+			server.mutex.Lock()
+			sID := newRequest.ToServerJoinMsg()
+			if server.rejoined(&Pserver) {
+				server.mutex.Unlock()
+				continue
+			}
+			fmt.Printf("[%s] Got Responce from partner server with ID: %s: %d.\n", server.ID, sID.ID, sID.ServerID)
+			if server.serverIDTaken(sID.ID, sID.ServerID) || server.refusedPeer(sID.ID, sID.ServerID) || server.joinedLate(sID.ID) {
+				server.mutex.Unlock()
+				return
+			}
+			Pserver = PartnerServer{
+				Id:         sID.ID,
+				ServerID:   sID.ServerID,
+				Connection: conn,
+				dialed:     dialed,
+			}
+			if !server.keepLink(&Pserver) {
+				server.mutex.Unlock()
+				return
+			}
+			server.PartnerConns[sID.ID] = &Pserver
+			joined = true
+			server.dealTo(&Pserver)
+			server.syncVotePeriod(sID.ID, sID.VoteLeft)
+			if server.serverThresshold <= len(server.PartnerConns) {
+				server.startVotePeriod()
+			}
+			server.mutex.Unlock()
+		case protocol.HEARTBEAT:
+			// Nothing to do, the partner is alive
+		case protocol.VOTEPERIOD:
+			server.mutex.Lock()
+			server.syncVotePeriod(Pserver.Id, newRequest.Val1)
+			server.mutex.Unlock()
+		case protocol.ABORT:
+			server.mutex.Lock()
+			sID := newRequest.ToABMsg()
+			if sID.Code == protocol.ERR_NONE {
+				sID.Code = protocol.ERR_ABORTED
+			}
+
+			fmt.Printf("[%v] An ABORT was recieved from %s. Reason %v (%s)\n", server.ID, Pserver.Id, sID.Code, sID.Message)
+			if server.didTally {
+				server.mutex.Unlock()
+				continue
+			}
+			// Inform clients of an error occured (and never tally)
+			tally := protocol.Results{
+				Yes:   0,
+				No:    0,
+				Error: true,
+				Code:  sID.Code,
+			}
+			server.didSum = true
+			server.didTally = true
+			server.Tally <- tally
+			server.mutex.Unlock()
+		case protocol.BLAME:
+			server.mutex.Lock()
+			// Keep the report of the partner as evidence (the reporter is who sent it, whatever the report says)
+			report := newRequest.ToBlameMsg()
+			report.Reporter = Pserver.Id
+			fmt.Printf("[%s] \033[33mGot blame report: %s.\033[0m\n", server.ID, report)
+			server.storeBlame(report)
+			server.mutex.Unlock()
+		case protocol.MACCOMMIT:
+			server.mutex.Lock()
+			server.receiveMACCommit(&Pserver, newRequest.ToMACCommitMsg())
+			server.mutex.Unlock()
+		case protocol.MACOPEN:
+			server.mutex.Lock()
+			server.receiveMACOpen(&Pserver, newRequest.ToMACOpenMsg())
+			server.mutex.Unlock()
+		case protocol.DKGDEAL:
+			server.mutex.Lock()
+			server.receiveDealing(&Pserver, newRequest.ToDKGMsg())
+			server.mutex.Unlock()
+		case protocol.PUBLICKEY:
+			server.mutex.Lock()
+			server.receiveKey(&Pserver, newRequest.ToKeyMsg())
+			server.mutex.Unlock()
+		case protocol.AGGREGATE:
+			server.mutex.Lock()
+			server.receiveAggregate(&Pserver, newRequest.ToAggregateMsg())
+			server.mutex.Unlock()
+		case protocol.AGGREGATEECHO:
+			server.mutex.Lock()
+			server.receiveEcho(&Pserver, newRequest.ToAggregateEchoMsg())
+			server.mutex.Unlock()
+		case protocol.PARTIALDECRYPT:
+			server.mutex.Lock()
+			server.receivePartial(&Pserver, newRequest.ToPartialMsg())
+			server.mutex.Unlock()
+		}
+
+	}
+
+}
+
+// Check if a joining partner was blamed by us in an earlier election (and not re-admitted by an operator), by its
+// ServerID and the address we have for it (whatever name it joins under)
+func (server *Server) refusedPeer(name string, serverID uint8) bool {
+	addr := server.partnerAddr(serverID)
+	if server.Blames == nil || !server.Blames.Refused(serverID, addr, server.ID) {
+		return false
+	}
+	fmt.Printf("[%s] \033[31mRefusing partner: %v.\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_PARTNER_REFUSED, "%s (ServerID %v at %s) was blamed in an earlier election (re-admit with -readmit)", name, serverID, addr))
+	return true
+}
+
+// The address we are configured with for the server with the ServerID (its IP, and the partner port if it has one)
+func (server *Server) partnerAddr(serverID uint8) string {
+	i := int(serverID) - 1
+	if i < 0 || len(server.PartnerIPs) == 0 {
+		return ""
+	}
+	ip := server.PartnerIPs[0]
+	if i < len(server.PartnerIPs) {
+		ip = server.PartnerIPs[i]
+	}
+	if i < len(server.PartnerPorts) {
+		return net.JoinHostPort(ip, server.PartnerPorts[i])
+	}
+	return ip
+}
+
+// Blame the server with the point for lying, informing partners (and later voters) of the evidence
+func (server *Server) blame(point sharing.Point, expected int, method string) {
+
+	// Find the name of the blamed server (a partner that dropped after sending its R-sum is known from it)
+	name, known := server.rsumSenders[uint8(point.X)]
+	if point.X == int(server.ServerID) {
+		name, known = server.ID, true
+	}
+	for _, p := range server.PartnerConns {
+		if int(p.ServerID) == point.X {
+			name, known = p.Id, true
+		}
+	}
+	if !known {
+		fmt.Printf("[%s] \033[33mNot blaming ServerID %v (%s), we do not know the server.\033[0m\n", server.ID, point.X, method)
+		return
+	}
+
+	// Make report
+	report := protocol.BlameReport{
+		Blamed:     uint8(point.X),
+		BlamedName: name,
+		BlamedAddr: server.partnerAddr(uint8(point.X)),
+		Reporter:   server.ID,
+		Point:      point,
+		Expected:   expected,
+		Method:     method,
+		Time:       time.Now().UTC().Format(time.RFC3339),
+	}
+	fmt.Printf("[%s] \033[31mBlaming: %s.\033[0m\n", server.ID, report)
+	server.blameReports = append(server.blameReports, report)
+	server.storeBlame(report)
+
+	// Inform partners
+	for _, partner := range server.PartnerConns {
+		if e := server.sendToPartner(partner, report.ToRequest()); e != nil {
+			fmt.Printf("[%s] Failed to send blame report to %s: %e\n", server.ID, partner.Id, e)
+		}
+	}
+
+}
+
+// Store a blame report on disk (if we keep them)
+func (server *Server) storeBlame(report protocol.BlameReport) {
+	if server.Blames == nil {
+		return
+	}
+	if e := server.Blames.Add(report); e != nil {
+		fmt.Printf("[%s] Failed to store blame report: %v\n", server.ID, e)
+	}
+}
+
+// Check if a joining partner claims a ServerID that is already in use (by us or another partner)
+func (server *Server) serverIDTaken(name string, serverID uint8) bool {
+	if serverID == server.ServerID {
+		fmt.Printf("[%s] \033[31mRefusing partner: %v.\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_PARTNER_ID_TAKEN, "%s claims our own ServerID %v", name, serverID))
+		return true
+	}
+	for _, p := range server.PartnerConns {
+		if p.ServerID == serverID && p.Id != name {
+			fmt.Printf("[%s] \033[31mRefusing partner: %v.\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_PARTNER_ID_TAKEN, "%s claims ServerID %v of %s", name, serverID, p.Id))
+			return true
+		}
+	}
+	return false
+}
+
+// Check if a partner joins after the voting period (too late to take part in the intersection)
+func (server *Server) joinedLate(name string) bool {
+	if server.votingClosed {
+		fmt.Printf("[%s] \033[31mRefusing partner %s, which joined after the voting period.\033[0m\n", server.ID, name)
+		return true
+	}
+	return false
+}
+
+// Count the servers taking part in the protocol (us and our partners)
+func (server *Server) liveServers() int {
+	return len(server.PartnerConns) + 1
+}
+
+// Start the voting period (once). Every server closes it at the deadline, which we tell our partners to agree on.
+func (server *Server) startVotePeriod() {
+	if server.votePeriodStarted {
+		return
+	}
+	server.votePeriodStarted = true
+	if server.voteDeadline.IsZero() {
+		server.voteDeadline = time.Now().Add(time.Duration(server.VoteTime) * time.Second)
+	}
+	go server.waitTime()
+	server.announceVotePeriod()
+
+	// Generate the election key with the partners (if the ballots are encrypted)
+	if scheme.Encrypts(server.Scheme) {
+		server.startKeyGeneration()
+	}
+}
+
+// Start the voting period without the partners that did not join in time
+func (server *Server) joinTimedOut() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.votePeriodStarted || server.votingClosed {
+		return
+	}
+	if server.liveServers() < server.minServers {
+		fmt.Printf("[%s] \033[33mOnly %v of %v server(s) joined, waiting for more.\033[0m\n", server.ID, server.liveServers(), server.serverThresshold+1)
+		time.AfterFunc(server.PhaseTimeout, server.joinTimedOut)
+		return
+	}
+	fmt.Printf("[%s] \033[33mStarting the vote with %v of %v server(s), the rest did not join in time.\033[0m\n", server.ID, server.liveServers(), server.serverThresshold+1)
+	server.startVotePeriod()
+}
+
+// Start the next phase of the protocol, giving partners PhaseTimeout to answer
+func (server *Server) nextPhase() {
+	server.phase++
+	phase := server.phase
+	time.AfterFunc(server.PhaseTimeout, func() { server.phaseTimedOut(phase) })
+}
+
+// Go ahead without the partners that did not answer in time
+func (server *Server) phaseTimedOut(phase int) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if phase != server.phase || server.didTally {
+		return
+	}
+
+	// Tally with the R-sums we got (if enough to interpolate). Encrypted ballots are only counted at the server
+	// they were sent to, so the tally cannot do without the ballots of any server.
+	if server.didSum {
+		if scheme.Encrypts(server.Scheme) {
+			server.failTally(protocol.ERR_TOO_FEW_SERVERS, fmt.Sprintf("only got the ballots of %v of %v server(s) in time", server.tallyParts(), server.tallyServers))
+			return
+		}
+		if len(server.RPoints) < server.minServers {
+			server.failTally(protocol.ERR_TOO_FEW_SERVERS, fmt.Sprintf("only got %v R-sum(s) in time", len(server.RPoints)))
+			return
+		}
+		fmt.Printf("[%s] \033[33mGot %v of %v R-sum(s) in time, tallying without the rest.\033[0m\n", server.ID, len(server.RPoints), server.tallyServers)
+		server.didTally = true
+		server.DoTally()
+		return
+	}
+
+	// Drop the partners who stalled a client list on its route (the lists they held never arrived)
+	stalled := server.stalledPSI()
+	if len(stalled) == 0 {
+		server.failTally(protocol.ERR_TOO_FEW_SERVERS, "our client list was not blinded by the partners in time")
+		return
+	}
+	for _, p := range stalled {
+		fmt.Printf("[%s] \033[33mPartner %s did not pass on the client lists it held in time, going ahead without it.\033[0m\n", server.ID, p.Id)
+		delete(server.PartnerConns, p.Id)
+		p.Connection.Close()
+	}
+	server.goOnWithout()
+
+}
+
+// Go ahead without a partner whose connection was lost
+func (server *Server) partnerLost(partner *PartnerServer) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if current, exists := server.PartnerConns[partner.Id]; !exists || current != partner || server.didTally {
+		return
+	}
+	delete(server.PartnerConns, partner.Id)
+	fmt.Printf("[%s] \033[33mPartner %s went offline, %v server(s) left.\033[0m\n", server.ID, partner.Id, server.liveServers())
+	server.goOnWithout()
+}
+
+// Redo the intersection with the servers still online.
+// Once the R-sums are shared, the R-sum of a lost partner may already have arrived, so we wait for the phase to time out instead.
+func (server *Server) goOnWithout() {
+	if !server.sentClients || server.didSum {
+		return
+	}
+	if server.liveServers() < server.minServers {
+		server.failTally(protocol.ERR_TOO_FEW_SERVERS, fmt.Sprintf("only %v of %v server(s) online", server.liveServers(), server.serverThresshold+1))
+		return
+	}
+	server.restartPSI()
+	server.reconcileClients()
+}
+
+// Give up on the tally, informing voters why
+func (server *Server) failTally(code protocol.ErrorCode, detail string) {
+	fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(code, detail))
+	server.didSum = true
+	server.didTally = true
+	server.Tally <- protocol.Results{Yes: 0, No: 0, Error: true, Code: code}
+}
+
+// Dial a partner and ask to join it, returning the link (nil if the partner could not be reached)
+func (server *Server) ConnectToServer(ip, port string) protocol.Conn {
+
+	// Define address
+	fmt.Printf("[%s] Connecting to : %v:%v \n", server.ID, ip, port)
+	conn, err := server.Transport.Dial(ip, port)
+	if err != nil {
+		fmt.Printf("[%s] Couldn't reach partner server at %v:%v, retrying later.\n", server.ID, ip, port)
+		return nil
+	}
+
+	// Send join message (the partner is yet unknown, so use the address as name)
+	server.mutex.Lock()
+	join := server.joinMessage()
+	server.mutex.Unlock()
+	e := server.sendToPartner(&PartnerServer{Id: fmt.Sprintf("%v:%v", ip, port), Connection: conn}, join.ToRequest())
+	if e != nil {
+		fmt.Printf("[%s] \033[31m%v\033[0m\n", server.ID, protocol.Errorf(protocol.ERR_PARTNER_LOST, "failed to send join to %s:%s: %v", ip, port, e))
+		conn.Close()
+		return nil
+	}
+
+	// Return the link
+	return conn
+
+}
+
+// Message joining a partner (telling it how far our voting period is)
+func (server *Server) joinMessage() protocol.ServerJoinIDMessage {
+	return protocol.ServerJoinIDMessage{ID: server.ID, ServerID: server.ServerID, VoteLeft: server.voteLeft()}
+}
+
+// Create a server from options (nothing happens before it is started)
+func New(opts ...Option) *Server {
+
+	// Init vals
+	server := new(Server)
+	server.mutex = &sync.Mutex{}
+	server.Clientsconnections = ConnectionMap{}
+	server.PartnerConns = ServerConnectionMap{}
+	server.VoteTime = VOTE_TIME
+	server.P = PRIME
+	server.Scheme = scheme.Default()
+	server.K = 1
+	server.Tally = make(chan protocol.Results, 1)
+	server.SumCalculation = HonestRSum
+	server.IntersectFunc = HonestIntersection
+	server.psiLists = make(map[uint8][]string)
+	server.psiProgress = make(map[uint8]int)
+	server.Transport = protocol.DefaultTransport
+	server.PhaseTimeout = PHASE_TIMEOUT
+	server.HeartbeatInterval = HEARTBEAT_INTERVAL
+	server.stop = make(chan struct{})
+	server.Election = protocol.DEFAULT_ELECTION
+
+	// Apply options
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	// Size up for the servers of the scheme
+	server.serverThresshold = server.Scheme.Servers() - 1
+	server.RPoints = make(chan sharing.Point, server.serverThresshold+1)
+	server.rsums = make(map[uint8]int)
+	server.rsumSenders = make(map[uint8]string)
+	server.minServers = server.Scheme.MinServers()
+	if server.Strategy == nil {
+		server.Strategy, _ = scheme.StrategyOf(server.Scheme, "")
+	}
+
+	// Every voter reaches a single server if the ballots are encrypted, so the voters are kept rather than intersected
+	if scheme.Encrypts(server.Scheme) {
+		server.IntersectFunc = UniqueVoters
+	}
+
+	// If fewer IPs than ports, copy (Assumption is the IP is the same for the remaining servers)
+	if len(server.PartnerIPs) == 0 {
+		server.PartnerIPs = []string{server.SelfIP}
+	}
+	for len(server.PartnerIPs) < len(server.PartnerPorts) {
+		server.PartnerIPs = append(server.PartnerIPs, server.PartnerIPs[0])
+	}
+
+	// Install byzantine behaviours (if any)
+	for _, b := range server.Behaviours {
+		b.Install(server)
+	}
+
+	return server
+
+}
+
+// Start listening for voters and partners, and join the mesh of partner links.
+// Fails if the ports cannot be listened on (the server is not started then).
+func (server *Server) Start() error {
+
+	// Get our blinding key for the client list intersection
+	key, err := NewPSIKey()
+	if err != nil {
+		return err
+	}
+	server.psiKey = key
+
+	// Get the key we sign the receipts of the voters with (our published key, if we were given one)
+	if server.receiptKey == nil {
+		if _, server.receiptKey, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			return err
+		}
+		fmt.Printf("[%s] \033[33mNo receipt key was published, voters cannot pin the key we draw.\033[0m\n", server.ID)
+	}
+	fmt.Printf("[%s] Signing receipts of election '%s' with key %s.\n", server.ID, server.Election, server.ReceiptKey())
+
+	// Log what we're doing
+	fmt.Printf("[%s][Server Startup] Making server for vote-clients at port: %s\n", server.ID, server.ListenPort)
+	fmt.Printf("[%s][server Startup] Making connection to %s:%s.\n", server.ID, server.SelfIP, server.PartnerPorts)
+
+	// Listen for voters
+	if server.ClientListener, err = server.Transport.Listen(server.SelfIP, server.ListenPort); err != nil {
+		return protocol.Errorf(protocol.ERR_SERVER_CONFIG, "cannot listen for voters on port %s: %v", server.ListenPort, err)
+	}
+
+	// Listen for partners (the last server has no partner port, as it dials everyone)
+	if i := int(server.ServerID) - 1; i < len(server.PartnerPorts) {
+		if server.ServerListener, err = server.Transport.Listen(server.SelfIP, server.PartnerPorts[i]); err != nil {
+			server.ClientListener.Close()
+			return protocol.Errorf(protocol.ERR_SERVER_CONFIG, "cannot listen for partners on port %s: %v", server.PartnerPorts[i], err)
+		}
+	}
+
+	// Tell subscribers (and voters) once the tally is done
+	go server.publishResults()
+
+	// Listen for partners, and keep dialing the partners we know of
+	server.startMesh()
+
+	// Go init server sockets
+	go server.InitClientSocket(server.ClientListener) // socket for clients
+
+	// Start the vote without partners that never join
+	time.AfterFunc(server.PhaseTimeout, server.joinTimedOut)
+
+	return nil
+
+}
+
+// Subscribe to the results of the election. The channel gets the results once the tally is done
+// (right away if it is already done), but never gets anything if the server is stopped before.
+func (server *Server) Subscribe() <-chan protocol.Results {
+	ch := make(chan protocol.Results, 1)
+	server.resultsMutex.Lock()
+	defer server.resultsMutex.Unlock()
+	if server.results != nil {
+		ch <- *server.results
+	} else {
+		server.subscribers = append(server.subscribers, ch)
+	}
+	return ch
+}
+
+// Wait for the results of the election
+func (server *Server) WaitForResults() protocol.Results {
+	return <-server.Subscribe()
+}
+
+// Get the blame reports we made in this election
+func (server *Server) BlameReports() []protocol.BlameReport {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return append([]protocol.BlameReport(nil), server.blameReports...)
+}
+
+// Wait for the tally, inform the voters and hand the results to the subscribers
+func (server *Server) publishResults() {
+
+	// Get results (a stopped server only publishes the results it already had)
+	var results protocol.Results
+	select {
+	case results = <-server.Tally:
+	case <-server.stop:
+		select {
+		case results = <-server.Tally:
+		default:
+			return
+		}
+	}
+	if !results.Error {
+		results.Receipts = server.talliedReceipts()
+	}
+	resultReq := results.ToRequest()
+
+	// Log
+	fmt.Printf("[%s] Tally: %v yes vote(s), %v no vote(s), %v total vote(s), Error detected %v.\n", server.ID, results.Yes, results.No, results.Yes+results.No, results.Error)
+	if results.Error {
+		fmt.Printf("[%s] \033[31mThe tally failed: %v.\033[0m\n", server.ID, results.Code)
+	}
+
+	// Inform connected clients (of the servers we caught lying, then the results), the rest ask for them later (under
+	// the lock, as voters hang up and rejoin meanwhile)
+	server.mutex.Lock()
+	for ip, client := range server.Clientsconnections {
+		if client.hungUp {
+			continue
+		}
+		for _, report := range server.blameReports {
+			if e := server.sendToVoter(client, report.ToRequest()); e != nil {
+				fmt.Printf("[%s] Failed to send blame report to client @%s.\n", server.ID, ip)
+			}
+		}
+		e := server.sendToVoter(client, resultReq)
+		if e != nil {
+			fmt.Printf("[%s] Failed to inform client @%s of results.\n", server.ID, ip)
+		}
+	}
+
+	// terminate
+	for _, partner := range server.PartnerConns {
+		partner.Connection.Close()
+	}
+	server.mutex.Unlock()
+
+	// Hand the results to the subscribers
+	server.resultsMutex.Lock()
+	defer server.resultsMutex.Unlock()
+	server.results = &results
+	for _, ch := range server.subscribers {
+		ch <- results
+	}
+	server.subscribers = nil
+
+}
+
+func (server *Server) waitTime() {
+
+	// Calculate wait time (less than the full period if we joined the period of a partner)
+	server.mutex.Lock()
+	wait := time.Until(server.voteDeadline).Round(time.Millisecond)
+	server.mutex.Unlock()
+
+	// Log enter vote period
+	fmt.Printf("[%s] Entered voting period of %v.\n", server.ID, wait)
+
+	// Do wait
+	time.Sleep(wait)
+
+	// Close voting (unless a partner, or an earlier deadline, already did, or we were stopped)
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.sentClients || server.didTally {
+		return
+	}
+
+	// Log exit vote period
+	fmt.Printf("[%s] Voting period ended. Counting votes...\n", server.ID)
+
+	// Close voting and share our client list
+	server.shareClientList()
+	server.reconcileClients()
+}
+
+// Send our blinded client list to partners, which also closes the voting period (only done once)
+func (server *Server) shareClientList() {
+	if server.sentClients {
+		return
+	}
+	server.votingClosed = true
+	server.sentClients = true
+	clients := server.getClients(server.Clientsconnections)
+
+	// Blind our voters
+	voters := map[string]*Voter{}
+	for _, v := range server.Clientsconnections {
+		voters[v.Id] = v
+	}
+	hashes := make([]*big.Int, len(clients))
+	for i, id := range clients {
+		hashes[i] = PSIHash(server.intersectedEntry(voters, id))
+	}
+	blinded := server.psiKey.Blind(hashes)
+
+	// Order by blinded value, so the position in the list tells nothing about the voter
+	order := make([]int, len(clients))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return blinded[order[i]].Cmp(blinded[order[j]]) < 0 })
+	server.ownClients = make([]string, len(clients))
+	values := make([]*big.Int, len(clients))
+	for i, k := range order {
+		server.ownClients[i] = clients[k]
+		values[i] = blinded[k]
+	}
+	server.psiOwn = EncodePSI(values)
+
+	// Send the list on its round through the partners
+	fmt.Printf("[%s] Sending %v blinded voter(s) to partners.\n", server.ID, len(values))
+	server.routePSI(protocol.PSIMessage{Origin: server.ServerID, Hops: 0, Servers: server.psiServers(), Values: server.psiOwn})
+	server.nextPhase()
+}
+
+// Get the bit mask of the ServerIDs taking part in the intersection (us and our partners)
+func (server *Server) psiServers() int {
+	mask := 1 << server.ServerID
+	for _, p := range server.PartnerConns {
+		mask |= 1 << p.ServerID
+	}
+	return mask
+}
+
+// Restart the intersection with the servers still online.
+// Lists blinded by an offline server can never be completed, so every list is sent again.
+func (server *Server) restartPSI() {
+	fmt.Printf("[%s] \033[33mRestarting the client list intersection with %v server(s).\033[0m\n", server.ID, server.liveServers())
+	server.psiLists = make(map[uint8][]string)
+	server.psiProgress = make(map[uint8]int)
+	server.routePSI(protocol.PSIMessage{Origin: server.ServerID, Hops: 0, Servers: server.psiServers(), Values: server.psiOwn})
+	server.nextPhase()
+
+	// Handle lists of partners who noticed before us
+	pending := server.psiPending
+	server.psiPending = nil
+	for _, p := range pending {
+		if p.progress != nil {
+			server.receivePSIProgress(p.partner, *p.progress)
+		} else {
+			server.receivePSI(p.partner, p.msg)
+		}
+	}
+}
+
+// Handle a blinded list from a partner, if it was sent among the same servers as we know of
+func (server *Server) receivePSI(partner *PartnerServer, msg protocol.PSIMessage) {
+	if server.didTally {
+		return
+	}
+
+	// A partner who knows of fewer servers noticed a server went offline before us, so keep the list until we do.
+	// Lists among more servers than we know of are outdated, the partner sends them again once it notices.
+	if servers := server.psiServers(); msg.Servers != servers {
+		if msg.Servers&servers == msg.Servers {
+			server.psiPending = append(server.psiPending, pendingPSI{partner: partner, msg: msg})
+		}
+		return
+	}
+
+	// Blind or store the list
+	if reason, bad := server.handlePSI(partner, msg); bad {
+		fmt.Printf("[%v]\033[31m Bad client list from %v: %s\033[0m\n", server.ID, partner.Id, reason)
+		server.sendABORT(protocol.ERR_BAD_CLIENT_LIST, reason)
+		// Inform clients of an error occured (and never tally)
+		server.failTally(protocol.ERR_BAD_CLIENT_LIST, reason)
+	}
+}
+
+// Get the order in which the list of a server is blinded by its partners (all other servers, by ServerID)
+func (server *Server) psiRoute(origin uint8) []uint8 {
+	route := make([]uint8, 0)
+	if origin != server.ServerID {
+		route = append(route, server.ServerID)
+	}
+	for _, p := range server.PartnerConns {
+		if p.ServerID != origin {
+			route = append(route, p.ServerID)
+		}
+	}
+	sort.Slice(route, func(i, j int) bool { return route[i] < route[j] })
+	return route
+}
+
+// Send a blinded list to the next server on its route, or to everyone once blinded by all
+func (server *Server) routePSI(msg protocol.PSIMessage) {
+
+	// Blinded by all, so share the final list
+	route := server.psiRoute(msg.Origin)
+	if msg.Hops >= len(route) {
+		msg.Done = true
+		server.psiLists[msg.Origin] = msg.Values
+		for _, partner := range server.PartnerConns {
+			if e := server.sendToPartner(partner, msg.ToRequest()); e != nil {
+				fmt.Printf("[%s] Sending blinded clients %e to %s\n", server.ID, e, partner.Id)
+			}
+		}
+		return
+	}
+
+	// Send to the next server on the route, and tell everyone it is there (so they know who stalled it if it never arrives)
+	for _, partner := range server.PartnerConns {
+		if partner.ServerID == route[msg.Hops] {
+			if e := server.sendToPartner(partner, msg.ToRequest()); e != nil {
+				fmt.Printf("[%s] Sending blinded clients %e to %s\n", server.ID, e, partner.Id)
+			}
+			server.notePSIProgress(msg.Origin, msg.Hops)
+			progress := protocol.PSIProgressMessage{Origin: msg.Origin, Hops: msg.Hops, Servers: msg.Servers}
+			for _, p := range server.PartnerConns {
+				if e := server.sendToPartner(p, progress.ToRequest()); e != nil {
+					fmt.Printf("[%s] Sending progress of the list of %v %e to %s\n", server.ID, msg.Origin, e, p.Id)
+				}
+			}
+			return
+		}
+	}
+	fmt.Printf("[%s] No partner with ID %v to blind the list of %v.\n", server.ID, route[msg.Hops], msg.Origin)
+
+}
+
+// Handle a notice from a partner that it passed a list on, if sent among the same servers as we know of.
+// Only the server that just blinded the list (or its origin) passes it on, so notices from anyone else are ignored.
+func (server *Server) receivePSIProgress(partner *PartnerServer, msg protocol.PSIProgressMessage) {
+	if server.didTally || server.didSum {
+		return
+	}
+	if servers := server.psiServers(); msg.Servers != servers {
+		if msg.Servers&servers == msg.Servers {
+			server.psiPending = append(server.psiPending, pendingPSI{partner: partner, progress: &msg})
+		}
+		return
+	}
+	route := server.psiRoute(msg.Origin)
+	if msg.Hops < 0 || msg.Hops >= len(route) || (msg.Hops == 0 && partner.ServerID != msg.Origin) || (msg.Hops > 0 && partner.ServerID != route[msg.Hops-1]) {
+		fmt.Printf("[%s] \033[33mIgnoring progress of the list of %v from %s, who did not pass it on.\033[0m\n", server.ID, msg.Origin, partner.Id)
+		return
+	}
+	server.notePSIProgress(msg.Origin, msg.Hops)
+}
+
+// Note the list of the origin was blinded by the amount of servers (notices may arrive out of order)
+func (server *Server) notePSIProgress(origin uint8, hops int) {
+	if known, exists := server.psiProgress[origin]; !exists || hops > known {
+		server.psiProgress[origin] = hops
+	}
+}
+
+// Find the partners holding a list that never arrived: the next server on the route of the list, or the origin
+// if it never sent it. Every honest server hears the same notices, so they agree on whom to go ahead without.
+func (server *Server) stalledPSI() []*PartnerServer {
+	holders := map[uint8]bool{}
+	origins := []uint8{server.ServerID}
+	for _, p := range server.PartnerConns {
+		origins = append(origins, p.ServerID)
+	}
+	for _, origin := range origins {
+		if _, exists := server.psiLists[origin]; exists {
+			continue
+		}
+		hops, exists := server.psiProgress[origin]
+		if !exists {
+			holders[origin] = true
+		} else if route := server.psiRoute(origin); hops < len(route) {
+			holders[route[hops]] = true
+		}
+	}
+	stalled := make([]*PartnerServer, 0)
+	for _, p := range server.PartnerConns {
+		if holders[p.ServerID] {
+			stalled = append(stalled, p)
+		}
+	}
+	return stalled
+}
+
+// Handle a blinded client list from a partner, reports if the list is malformed (an honest server never sends one of these)
+func (server *Server) handlePSI(partner *PartnerServer, msg protocol.PSIMessage) (string, bool) {
+
+	// Check the values are in the group
+	values, err := DecodePSI(msg.Values)
+	if err != nil {
+		return fmt.Sprintf("Blinded client list of %v from %s is malformed: %v.", msg.Origin, partner.Id, err), true
+	}
+
+	// Blind it with our key (keeping the order) and pass it on
+	if !msg.Done {
+		route := server.psiRoute(msg.Origin)
+		if msg.Hops < 0 || msg.Hops >= len(route) || route[msg.Hops] != server.ServerID {
+			return fmt.Sprintf("%s sent the list of %v out of turn.", partner.Id, msg.Origin), true
+		}
+		msg.Values = EncodePSI(server.psiKey.Blind(values))
+		msg.Hops++
+		server.routePSI(msg)
+		return "", false
+	}
+
+	// A server only ever has one list, getting two different lists (or duplicate voters) is misbehaviour
+	seen := protocol.StringHashSet{}
+	for _, v := range msg.Values {
+		if _, exists := seen[v]; exists {
+			return fmt.Sprintf("Client list of %v has a voter twice.", msg.Origin), true
+		}
+		seen[v] = struct{}{}
+	}
+	if msg.Origin == server.ServerID && len(msg.Values) != len(server.ownClients) {
+		return fmt.Sprintf("%s altered our client list.", partner.Id), true
+	}
+	if list, exists := server.psiLists[msg.Origin]; exists {
+		if len(list) != len(msg.Values) {
+			return fmt.Sprintf("Got two different client lists of %v.", msg.Origin), true
+		}
+		for _, v := range list {
+			if _, exists := seen[v]; !exists {
+				return fmt.Sprintf("Got two different client lists of %v.", msg.Origin), true
+			}
+		}
+		return "", false
+	}
+	server.psiLists[msg.Origin] = msg.Values
+	return "", false
+
+}
+
+// Agree on which voters to tally, once we have the client list of every server.
+// Every server computes the same intersection, so voters missing at some server are dropped everywhere.
+func (server *Server) reconcileClients() {
+
+	// Wait for all lists (of the servers still online)
+	if server.didSum || !server.sentClients || server.liveServers() < server.minServers {
+		return
+	}
+	own, exists := server.psiLists[server.ServerID]
+	if !exists {
+		return
+	}
+	for _, p := range server.PartnerConns {
+		if _, exists := server.psiLists[p.ServerID]; !exists {
+			return
+		}
+	}
+
+	// Find which of our voters voted at each partner (fully blinded values match only for the same voter)
+	lists := map[string][]string{server.ID: server.ownClients}
+	for _, p := range server.PartnerConns {
+		theirs := protocol.CheckmapFromStringSlice(server.psiLists[p.ServerID])
+		p.clientList = make([]string, 0)
+		for i, v := range own {
+			if _, exists := theirs[v]; exists {
+				p.clientList = append(p.clientList, server.ownClients[i])
+			}
+		}
+		p.comparedClients = true
+		if unknown := len(theirs) - len(p.clientList); unknown > 0 {
+			fmt.Printf("[%s] %s has %v voter(s) who did not vote with us.\n", server.ID, p.Id, unknown)
+		}
+		lists[p.Id] = p.clientList
+	}
+
+	// Intersect (Variability point), and count the last ballot of every voter that all servers have
+	common, excluded := server.IntersectFunc(server, lists)
+	if !scheme.Encrypts(server.Scheme) {
+		common, excluded = server.agreeOnBallots(common, excluded)
+	}
+	server.VoterIntersection = protocol.CheckmapFromStringSlice(common)
+	server.ExcludedVoters = excluded
+
+	// Report excluded voters
+	fmt.Printf("[%s] Agreed on %v voter(s), excluding %v.\n", server.ID, len(common), len(excluded))
+	excludedIDs := make([]string, 0)
+	for id := range excluded {
+		excludedIDs = append(excludedIDs, id)
+	}
+	sort.Strings(excludedIDs)
+	for _, id := range excludedIDs {
+		fmt.Printf("[%s] \033[33mExcluded voter %s (%s).\033[0m\n", server.ID, id, excluded[id])
+	}
+
+	// goto next step in process
+	server.EndVotePeriod()
+	server.didSum = true
+	server.tallyServers = server.liveServers()
+	server.nextPhase()
+	server.tryTally()
+
+}
+
+// Do the tally once we have summed our own votes and got the R-sums of all partners (online when we summed)
+func (server *Server) tryTally() {
+	if server.didSum && !server.didTally && server.tallyParts() >= server.tallyServers {
+		server.didTally = true
+		server.DoTally()
+	}
+}
+
+func (server *Server) EndVotePeriod() {
+
+	fmt.Printf("[%s]: clients %s\n", server.ID, server.getClients(server.Clientsconnections))
+
+	// Multiply the ballots instead, if they are encrypted
+	if scheme.Encrypts(server.Scheme) {
+		server.shareAggregate()
+		return
+	}
+
+	// Calculate R sum using specified sum function (Variability point)
+	server.SelfRSum = server.SumCalculation(server)
+
+	// Sum the MACs of the shares along with them
+	if server.MACKey != nil {
+		server.SelfMSum = MACSum(server)
+	}
+
+	// Log exit vote period
+	fmt.Printf("[%s] Voting period ended. Got R-value of %v\n", server.ID, server.SelfRSum)
+
+	// Put our point into self R-point
+	server.RPoints <- sharing.Point{X: int(server.ServerID), Y: server.SelfRSum}
+
+	// Send new r-value to partner
+	for _, partner := range server.PartnerConns {
+		e := server.sendToPartner(partner, protocol.RMessage{Vote: server.rsumTo(partner, server.SelfRSum)}.ToRequest())
+		if e != nil {
+			fmt.Printf("[%s] Failed to send accumulated R-value to partner, %e\n", server.ID, e)
+		} else {
+			fmt.Printf("[%s] sent accumulated R-value(%d) to partner, %s\n", server.ID, server.SelfRSum, partner.Id)
+		}
+	}
+
+}
+
+// Tally the votes from the R-sum points of the servers, using the scheme of the election
+func (server *Server) DoTally() {
+	fmt.Printf("[%v] started Tally\n", server.ID)
+
+	// Decrypt the sum together, if the ballots are encrypted
+	if scheme.Encrypts(server.Scheme) {
+		server.decryptTally()
+		return
+	}
+
+	// Grab points (fewer than one per server if servers went offline) and order by X-coord
+	points := make([]sharing.Point, 0, server.serverThresshold+1)
+	for len(server.RPoints) > 0 {
+		points = append(points, <-server.RPoints)
+	}
+	sort.Sort(sharing.PointXSort(points))
+
+	// Reconstruct the sum of the votes (Variability point), and blame the servers caught lying
+	sum, diag := server.Strategy.Tally(points, tally.Params{Name: server.ID, ServerID: server.ServerID, P: server.P, K: server.K, Servers: server.Scheme.Servers()})
+	fmt.Printf("[%s] Tallied with %v.\n", server.ID, diag)
+	for _, b := range sum.Blames {
+		server.blame(b.Point, b.Expected, b.Method)
+	}
+	if sum.Code != protocol.ERR_NONE {
+		server.Tally <- protocol.Results{Yes: 0, No: 0, Error: true, Code: sum.Code, Points: points}
+		return
+	}
+
+	// Get nays
+	yes_vote := sum.Yes
+	no_vote := len(server.VoterIntersection) - yes_vote
+
+	// Log in struct
+	tally := protocol.Results{
+		Yes:    yes_vote,
+		No:     no_vote,
+		Error:  false,
+		Points: points,
+	}
+
+	// More yes votes than voters means someone shared a vote outside {0, 1}
+	if no_vote < 0 {
+		fmt.Printf("[%s] \033[31mError - Got %v yes vote(s) from %v voter(s), a voter voted outside {0, 1}.\033[0m\n", server.ID, yes_vote, len(server.VoterIntersection))
+		tally.Error = true
+		tally.Code = protocol.ERR_VOTE_OUT_OF_RANGE
+	}
+
+	// Check the MACs of the opened sum before publishing it
+	if server.MACKey != nil {
+		server.startMACCheck(tally, sum.Yes)
+		return
+	}
+
+	// Enter into channel
+	server.Tally <- tally
+
+}
+
+func (server *Server) Halt() {
+
+	// Close both listeners (the last server to join never listens for partners)
+	if server.ClientListener != nil {
+		server.ClientListener.Close()
+	}
+	if server.ServerListener != nil {
+		server.ServerListener.Close()
+	}
+
+	// Stop reconnecting to partners
+	server.haltOnce.Do(func() { close(server.stop) })
+
+}
+
+// Stop the server as if it crashed (it never tallies, and every connection is closed)
+func (server *Server) Stop() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.Halt()
+	server.didSum = true
+	server.didTally = true
+	for _, p := range server.PartnerConns {
+		p.Connection.Close()
+	}
+	for _, v := range server.Clientsconnections {
+		v.Connection.Close()
+	}
+}
+
+func (server *Server) getClients(voters ConnectionMap) (strs []string) {
+	keys := make([]string, 0)
+	for _, v := range voters {
+		// Registrations without a vote are not counted
+		if !v.Voted {
+			continue
+		}
+		// Every share a voter cast is listed, so the servers can agree on which of them to count
+		if scheme.Encrypts(server.Scheme) {
+			keys = append(keys, v.Id)
+			continue
+		}
+		for seq := range v.shares {
+			keys = append(keys, ballotEntry(v.Id, seq))
+		}
+	}
+	strs = keys
+	return
+}
+
+func (server *Server) sendABORT(code protocol.ErrorCode, reason string) {
+	for _, partner := range server.PartnerConns {
+		e := server.sendToPartner(partner, protocol.ABORTmessage{Message: reason, ServerID: server.ServerID, Code: code}.ToRequest())
+		if e == nil {
+			fmt.Printf("[%s] Sending Abort message to %s\n", server.ID, partner.Id)
+		}
+	}
+}
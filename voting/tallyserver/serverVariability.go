@@ -0,0 +1,162 @@
+package tallyserver
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// Code for malicious server behaviour
+const (
+	BEHAVIOUR_MODE_CLIENT_INTERSET = 0
+	BEHAVIOUR_MODE_WRONG_R_VALUE   = 1
+)
+
+// Sum Behaviours:
+
+// Do honest R-sum
+func HonestRSum(server *Server) int {
+	// Tally up R-values
+	RSum := 0
+	for _, v := range server.Clientsconnections {
+		if _, exists := server.VoterIntersection[v.Id]; exists {
+			fmt.Printf("[%s] Counting R-vote of %s\n", server.ID, v.Id)
+			//RSum = RSum + v.RVal
+			RSum = sharing.Pmod(RSum+v.RVal, server.P)
+		}
+	}
+	return RSum
+}
+
+// Sum our shares of the MACs of the counted votes (always honest, a server altering its R-sum is caught by the MAC check)
+func MACSum(server *Server) int {
+	MSum := 0
+	for _, v := range server.Clientsconnections {
+		if _, exists := server.VoterIntersection[v.Id]; exists {
+			MSum = sharing.Pmod(MSum+v.MAC, server.P)
+		}
+	}
+	return MSum
+}
+
+// Do corrupt R-sum based on mode
+func CorruptRSumDet(server *Server, mode int) int {
+	if mode == 0 {
+		fmt.Printf("[BadServer] \033[31mCorrupting sum to P-value: %v.\033[0m\n", server.P)
+		return server.P // simply return p
+	} else if mode == 1 {
+		fmt.Printf("[BadServer] \033[31mCorrupting sum to honest sum +- random offset: %v.\033[0m\n", server.P)
+		return HonestRSum(server) + (rand.Intn(server.P*2) - server.P) // Some random offset from honest r-sum (this may be an OK)
+	} else if mode == 2 {
+		fmt.Printf("[BadServer] \033[31mCorrupting sum to random upper-bounded P-value: %v.\033[0m\n", server.P)
+		return rand.Intn(server.P) // random number in field (this may be an OK)
+	} else {
+		fmt.Printf("[BadServer] \033[31mCorrupting sum to random negative bounded P-value: %v.\033[0m\n", server.P)
+		return -rand.Intn(server.P) // Outside of field
+	}
+}
+
+// Do corrupt R-sum -> pick one of four options
+func CorruptRSum(server *Server) int {
+	return CorruptRSumDet(server, rand.Intn(4))
+}
+
+// Intersection behaviours
+
+// Honest behaviour, agrees on the voters found in the client list of every server.
+// Voters missing from some lists are excluded, with the servers lacking them as reason.
+func HonestIntersection(server *Server, lists map[string][]string) ([]string, map[string]string) {
+
+	// Find the servers listing each voter
+	names := make([]string, 0)
+	listedAt := map[string][]string{}
+	for name, list := range lists {
+		names = append(names, name)
+		for _, id := range list {
+			listedAt[id] = append(listedAt[id], name)
+		}
+	}
+	sort.Strings(names)
+
+	// Keep voters listed everywhere
+	common := make([]string, 0)
+	excluded := map[string]string{}
+	for id, at := range listedAt {
+		if len(at) == len(lists) {
+			common = append(common, id)
+			continue
+		}
+		listed := protocol.CheckmapFromStringSlice(at)
+		missing := make([]string, 0)
+		for _, name := range names {
+			if _, exists := listed[name]; !exists {
+				missing = append(missing, name)
+			}
+		}
+		excluded[id] = fmt.Sprintf("no vote at %s", strings.Join(missing, ", "))
+	}
+	sort.Strings(common)
+
+	return common, excluded
+}
+
+// Honest behaviour when every voter reaches a single server (encrypted ballots), keeps our voters who voted at no
+// partner. A voter who voted at several servers is dropped by all of them, as no server can tell which vote counts.
+func UniqueVoters(server *Server, lists map[string][]string) ([]string, map[string]string) {
+
+	// Find the partners listing each of our voters
+	at := map[string][]string{}
+	for name, list := range lists {
+		if name == server.ID {
+			continue
+		}
+		for _, id := range list {
+			at[id] = append(at[id], name)
+		}
+	}
+
+	// Keep the voters only we have
+	unique := make([]string, 0)
+	excluded := map[string]string{}
+	for _, id := range lists[server.ID] {
+		if len(at[id]) == 0 {
+			unique = append(unique, id)
+			continue
+		}
+		sort.Strings(at[id])
+		excluded[id] = fmt.Sprintf("voted at %s as well", strings.Join(at[id], ", "))
+	}
+	sort.Strings(unique)
+
+	return unique, excluded
+}
+
+// Corrupt behaviour
+func CorruptIntersection(server *Server, lists map[string][]string) ([]string, map[string]string) {
+
+	mode := rand.Intn(2)
+	if mode == 0 {
+		fmt.Println("[BadServer] Returned an empty List")
+		return make([]string, 0), map[string]string{}
+	} else if mode == 1 {
+		common, excluded := HonestIntersection(server, lists)
+		size := rand.Intn(len(common) + 1)
+
+		common = common[:size]
+		fmt.Printf("[BadServer] reduced the ClientList by %v to %v\n", size, common)
+		return common, excluded
+	}
+
+	common, excluded := HonestIntersection(server, lists)
+	size := rand.Intn(len(common) + 1)
+	fmt.Printf("[BadServer] increased the ClientList by %v to %v\n", size, common)
+	for i := 0; i < size; i++ {
+		common = append(common, fmt.Sprintf("Bogus%v", i))
+	}
+
+	return common, excluded
+}
@@ -0,0 +1,3051 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/elgamal"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/spdz"
+	"cs.au.dk/voting/tally"
+	"cs.au.dk/voting/tallyserver"
+	"cs.au.dk/voting/voteclient"
+)
+
+// Struct for taking optional arguments to client spanwer
+type clientVote struct {
+	id            string
+	name          string
+	ports         []string
+	Vote          int
+	Seed          int
+	P             int
+	DoSeed        bool
+	SwapPorts     []int
+	IgnoreResults bool
+	BadMode       int
+	Scheme        string
+	Keys          string
+	Authority     string
+	APort         string
+	ClientMode    string
+}
+
+// Self IP address for testing
+var localIP = protocol.GetSelfIP()
+
+// Slice of spawned proceeses
+var db_spawnedProcceses []*os.Process
+
+// Slice of test cases
+var testCases = []func() bool{
+	RunTest01,
+	RunTest02,
+	RunTest03,
+	RunTest04,
+	RunTest05,
+	RunTest06,
+	RunTest07,
+	RunTest08,
+	RunTest09,
+	RunTest10,
+	RunTest11,
+	RunTest12,
+	RunTest13,
+	RunTest14,
+	RunTest15,
+	RunTest16,
+	RunTest17,
+	RunTest18,
+	RunTest19,
+	RunTest20,
+	RunTest21,
+	RunTest22,
+	RunTest23,
+	RunTest24,
+	RunTest25,
+	RunTest26,
+	RunTest27,
+	RunTest28,
+	RunTest29,
+	RunTest30,
+	RunTest31,
+	RunTest32,
+	RunTest33,
+	RunTest34,
+	RunTest35,
+	RunTest36,
+	RunTest37,
+	RunTest38,
+	RunTest39,
+	RunTest40,
+	RunTest41,
+	RunTest42,
+	RunTest43,
+	RunTest44,
+	RunTest45,
+	RunTest46,
+	RunTest47,
+	RunTest48,
+	RunTest49,
+	RunTest50,
+	RunTest51,
+	RunTest52,
+	RunTest53,
+	RunTest54,
+	RunTest55,
+	RunTest56,
+	RunTest57,
+	RunTest58,
+	RunTest59,
+	RunTest60,
+}
+
+// Dispatches calls
+func DispatchTestCall(testID int) {
+	if testID-1 < 0 {
+		passes := 0
+		for k, v := range testCases {
+			result := v()
+			CheckTest(k+1, result)
+			if result {
+				passes++
+			}
+			time.Sleep(time.Millisecond * 500)
+		}
+		fmt.Println()
+		fmt.Printf("--- Test Results: %v/%v passes ---", passes, len(testCases))
+		fmt.Println()
+		return
+	}
+	if testID-1 >= len(testCases) {
+		fmt.Printf("Unknown test '%v'.\n", testID)
+	} else {
+		CheckTest(testID, testCases[testID-1]())
+	}
+}
+
+func CheckTest(id int, res bool) {
+	if res {
+		fmt.Printf("\033[32m--- TEST %v PASS ---\033[0m\n", id)
+	} else {
+		fmt.Printf("\033[31m--- TEST %v FAIL ---\033[0m\n", id)
+	}
+}
+
+func PrintResult(testID int, res protocol.Results) {
+	fmt.Println()
+	fmt.Printf("\033[33m@@@ TEST %v: Got results:\n\t%+v\n\033[0m", testID, res)
+	fmt.Println()
+}
+
+func RunTest01() bool {
+	// Init rand
+	rand.Seed(1)
+
+	// Log test
+	fmt.Println("--- Running test 1 ---")
+	fmt.Println()
+
+	// Log what we're testing
+	fmt.Println("Starting test-server")
+	fmt.Println()
+
+	// Create test server
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP, localIP}, 15, 10, 1997, 1, scheme.Default(), nil, nil, nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-name", "otherServer", "-port", "10002", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "3", "-mode", "server", "-name", "ThirdServer", "-port", "10003", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("third server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "4", "-mode", "server", "-name", "fourthServer", "-port", "10004", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("fourth server failed Error was %v.\n", e)
+		return false
+	}
+
+	fmt.Println()
+	fmt.Printf("@@@ TEST  1: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Spawn voters
+	TestUtil_ClientVoteInstance(clientVote{id: "1", name: "yay1", Vote: 1, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "2", name: "yay2", Vote: 1, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "3", name: "yay3", Vote: 1, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "4", name: "nay4", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "5", name: "nay5", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "6", name: "nay6", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "7", name: "nay7", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "8", name: "nay8", Vote: 0, DoSeed: true, Seed: 1})
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 1: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(1, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	return res.No == 5 && res.Yes == 3 && !res.Error
+}
+
+func RunTest02() bool {
+	// Init rand
+	rand.Seed(1)
+
+	// Log test
+	fmt.Println("--- Running test 2 ---")
+	fmt.Println("--- With Dishonest R-value ---")
+	fmt.Println()
+
+	// Log what we're testing
+	fmt.Println("Starting test-server")
+	fmt.Println()
+
+	// Create test server
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP, localIP}, 15, 10, 1997, 1, scheme.Default(), nil, nil, nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-name", "otherServer", "-port", "10002", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "3", "-mode", "server", "-name", "ThirdServer", "-port", "10003", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("third server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	// Spawn server with dishonest R values.
+	if _, e := TestUtil_SpawnTestProcess("-id", "4", "-mode", "server", "-name", "fourthServer-Baddie", "-port", "10004", "-pport", "11001,11002,11003", "-t", "15", "-s", "1", "-b", "1"); e != nil {
+		fmt.Printf("fourth server failed Error was %v.\n", e)
+		return false
+	}
+
+	fmt.Println()
+	fmt.Printf("@@@ TEST  2: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Spawn voters
+	TestUtil_ClientVoteInstance(clientVote{id: "1", name: "yay1", Vote: 1, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "2", name: "yay2", Vote: 1, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "3", name: "yay3", Vote: 1, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "4", name: "nay4", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "5", name: "nay5", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "6", name: "nay6", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "7", name: "nay7", Vote: 0, DoSeed: true, Seed: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "8", name: "nay8", Vote: 0, DoSeed: true, Seed: 1})
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 2: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(2, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	return res.No == 5 && res.Yes == 3 && !res.Error
+}
+
+func RunTest03() bool {
+	// Init rand
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	// Log test
+	fmt.Println("--- Running test 3 ---")
+	fmt.Println()
+
+	// Log what we're testing
+	fmt.Println("Starting test-server")
+	fmt.Println()
+
+	// Create test server
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP, localIP}, 15, 10, 1997, 1, scheme.Default(), nil, nil, nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-name", "otherServer", "-port", "10002", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("third server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "3", "-mode", "server", "-name", "ThirdServer", "-port", "10003", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("fourth server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	serverCorrupted := rand.Intn(2)
+	// Spawn server.
+	if serverCorrupted == 0 {
+		if _, e := TestUtil_SpawnTestProcess("-id", "4", "-mode", "server", "-name", "fourthServer", "-port", "10004", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+			fmt.Printf("second server failed Error was %v.\n", e)
+			return false
+		}
+	} else {
+		if _, e := TestUtil_SpawnTestProcess("-id", "4", "-mode", "server", "-name", "fourthServer-Baddie", "-port", "10004", "-pport", "11001,11002,11003", "-t", "15", "-s", "1", "-b", "1"); e != nil {
+			fmt.Printf("second server failed Error was %v.\n", e)
+			return false
+		}
+	}
+
+	// Wait 2s
+	fmt.Println()
+	fmt.Printf("@@@ TEST  3: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Spawn voters
+	TestUtil_ClientVoteInstance(clientVote{id: "1", name: "yay1", Vote: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "2", name: "yay2", Vote: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "3", name: "yay3", Vote: 1})
+	TestUtil_ClientVoteInstance(clientVote{id: "4", name: "nay4", Vote: 0})
+	TestUtil_ClientVoteInstance(clientVote{id: "5", name: "nay5", Vote: 0})
+	TestUtil_ClientVoteInstance(clientVote{id: "6", name: "nay6", Vote: 0})
+	TestUtil_ClientVoteInstance(clientVote{id: "7", name: "nay7", Vote: 0})
+	TestUtil_ClientVoteInstance(clientVote{id: "8", name: "nay8", Vote: 0})
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 3: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(3, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	if serverCorrupted == 0 {
+		return res.No == 5 && res.Yes == 3 && !res.Error
+	} else {
+		return !res.Error
+	}
+
+}
+
+func RunTest04() bool {
+	// Init rand
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	// Log test
+	fmt.Println("--- Running test 4 ---")
+	fmt.Println()
+
+	// Log what we're testing
+	fmt.Println("Starting test-server")
+	fmt.Println()
+
+	// Create test server
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP, localIP}, 40, 10, 1997, 1, scheme.Default(), nil, nil, nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-name", "otherServer", "-port", "10002", "-pport", "11001,11002,11003", "-t", "40"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "3", "-mode", "server", "-name", "ThirdServer", "-port", "10003", "-pport", "11001,11002,11003", "-t", "40"); e != nil {
+		fmt.Printf("third server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	serverCorrupted := rand.Intn(2)
+	// Spawn server.
+	if serverCorrupted == 0 {
+		if _, e := TestUtil_SpawnTestProcess("-id", "4", "-mode", "server", "-name", "fourthServer", "-port", "10004", "-pport", "11001,11002,11003", "-t", "40"); e != nil {
+			fmt.Printf("fourth server failed Error was %v.\n", e)
+			return false
+		}
+	} else {
+		if _, e := TestUtil_SpawnTestProcess("-id", "4", "-mode", "server", "-name", "fourthServer-Baddie", "-port", "10004", "-pport", "11001,11002,11003", "-t", "40", "-b", "1"); e != nil {
+			fmt.Printf("fourth server failed Error was %v.\n", e)
+			return false
+		}
+	}
+
+	// Wait 2s
+	fmt.Println()
+	fmt.Printf("@@@ TEST  4: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Amount to test
+	lowestThresshold := 101
+	clients := lowestThresshold + rand.Intn(150)
+
+	// Spawn voters
+	yesVoters := 0
+	for i := 0; i < clients; i++ {
+		vote := rand.Intn(2)
+		name := "nay" + fmt.Sprint(i-yesVoters)
+		if vote == 1 {
+			yesVoters++
+			name = "yay" + fmt.Sprint(yesVoters)
+		}
+		TestUtil_ClientVoteInstance(clientVote{id: fmt.Sprint(i), name: name, Vote: vote})
+
+	}
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 4: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(4, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	if serverCorrupted == 1 && !res.Error {
+		fmt.Printf("\033[32mError was Encountered, but corrected\033[0m\n")
+	}
+	return res.No == clients-yesVoters && res.Yes == yesVoters && !res.Error
+
+}
+
+func RunTest05() bool {
+	// Init rand
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	// Log test
+	fmt.Println("--- Running test 5 ---")
+	fmt.Println()
+
+	// Log what we're testing
+	fmt.Println("Starting test-server")
+	fmt.Println()
+
+	// Create test server
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP, localIP}, 40, 10, 1997, 1, scheme.Default(), nil, nil, nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-name", "otherServer", "-port", "10002", "-pport", "11001,11002,11003", "-t", "40"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "3", "-mode", "server", "-name", "ThirdServer", "-port", "10003", "-pport", "11001,11002,11003", "-t", "40"); e != nil {
+		fmt.Printf("third server failed Error was %v.\n", e)
+		return false
+	}
+
+	time.Sleep(2 * time.Second)
+	serverCorrupted := 1
+	// Spawn server.
+
+	serverRandomCorruptionMode := 3
+	if _, e := TestUtil_SpawnTestProcess("-id", "4", "-mode", "server", "-name", "fourthServer-Baddie", "-port", "10004", "-pport", "11001,11002,11003", "-t", "40", "-b", "1", "-bb", fmt.Sprintf("%v", serverRandomCorruptionMode)); e != nil {
+		fmt.Printf("fourth server failed Error was %v.\n", e)
+		return false
+	}
+
+	// Wait 2s
+	fmt.Println()
+	fmt.Printf("@@@ TEST  5: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Amount to test
+	lowestThresshold := 101
+	clients := lowestThresshold + rand.Intn(150)
+
+	// Spawn voters
+	yesVoters := 0
+	for i := 0; i < clients; i++ {
+		vote := rand.Intn(2)
+		name := "nay" + fmt.Sprint(i-yesVoters)
+		if vote == 1 {
+			yesVoters++
+			name = "yay" + fmt.Sprint(yesVoters)
+		}
+		TestUtil_ClientVoteInstance(clientVote{id: fmt.Sprint(i), name: name, Vote: vote})
+
+	}
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 5: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(5, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	if serverCorrupted == 1 && !res.Error {
+		fmt.Printf("\033[32mError was Encountered, but corrected\033[0m\n")
+	}
+	return res.No == clients-yesVoters && res.Yes == yesVoters && !res.Error
+
+}
+
+func RunTest06() bool {
+
+	// Log test
+	fmt.Println("--- Running test 6 ---")
+	fmt.Println("--- Simulated network with delays ---")
+	fmt.Println()
+
+	// Run with random delays on all links
+	return RunSimulation(SimElection{
+		Seed:     6,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Links:    "*>*:delay=1ms-40ms",
+	})
+
+}
+
+func RunTest07() bool {
+
+	// Log test
+	fmt.Println("--- Running test 7 ---")
+	fmt.Println("--- Simulated bad server sending a different R-sum to each partner ---")
+	fmt.Println()
+
+	// Run with S4 splitting its R-sum
+	return RunSimulation(SimElection{
+		Seed:       7,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{4: {&tallyserver.SplitRSumBehaviour{Offset: 3}}},
+	})
+
+}
+
+func RunTest08() bool {
+
+	// Log test
+	fmt.Println("--- Running test 8 ---")
+	fmt.Println("--- Simulated bad voters flooding registrations and voting late ---")
+	fmt.Println()
+
+	// Run with C3 flooding the servers, and C6 voting after the deadline (which must not be counted)
+	return RunSimulation(SimElection{
+		Seed:      8,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{3: {Mode: voteclient.CLIENT_MODE_FLOOD, Arg: 20}, 6: {Mode: voteclient.CLIENT_MODE_LATE, Arg: 8}},
+	})
+
+}
+
+func RunTest09() bool {
+
+	// Log test
+	fmt.Println("--- Running test 9 ---")
+	fmt.Println("--- Simulated voters only reaching some of the servers ---")
+	fmt.Println()
+
+	// Run with C2 and C5 withholding shares from some servers (which must be dropped instead of aborting)
+	return RunSimulation(SimElection{
+		Seed:      9,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{2: {Mode: voteclient.CLIENT_MODE_PARTIAL, Arg: 3}, 5: {Mode: voteclient.CLIENT_MODE_PARTIAL, Arg: 1}},
+	})
+
+}
+
+func RunTest10() bool {
+
+	// Log test
+	fmt.Println("--- Running test 10 ---")
+	fmt.Println("--- Simulated bad server lying about its R-sum and the tally, caught by the voters ---")
+	fmt.Println()
+
+	// Run with S2 sending a wrong R-sum to partners and a made up tally to voters
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       10,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{2: {&tallyserver.ForgeTallyBehaviour{Yes: 100}, &tallyserver.WrongRSumBehaviour{Mode: 0}}},
+	})
+
+	// Every voter must verify the right tally and blame S2 (only)
+	if !outcome.Passed() || len(outcome.Verified) != 8 {
+		return false
+	}
+	for _, v := range outcome.Verified {
+		if len(v.Liars) != 1 || v.Liars[0] != 2 {
+			fmt.Printf("\033[31m%s blamed %v\033[0m\n", v.Name, v.Liars)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest11() bool {
+
+	// Log test
+	fmt.Println("--- Running test 11 ---")
+	fmt.Println("--- Simulated bad server blamed, stored and refused in later elections ---")
+	fmt.Println()
+
+	// Keep the blame reports in a temporary folder
+	dir, err := os.MkdirTemp("", "blames")
+	if err != nil {
+		fmt.Printf("Failed to create blame folder: %v\n", err)
+		return false
+	}
+	defer os.RemoveAll(dir)
+
+	// Run with S3 sharing an R-sum outside the field
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       11,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{3: {&tallyserver.WrongRSumBehaviour{Mode: 0}}},
+		BlameDir:   dir,
+	})
+	if !outcome.Passed() {
+		return false
+	}
+
+	// Every honest server must blame S3 (and only S3)
+	for i, reports := range outcome.Blames {
+		if !outcome.Honest[i] {
+			continue
+		}
+		if len(reports) != 1 || reports[0].BlamedName != "S3" || reports[0].Blamed != 3 {
+			fmt.Printf("\033[31mS%v made the blame reports %+v\033[0m\n", i+1, reports)
+			return false
+		}
+	}
+
+	// The report must be on disk, so S1 refuses ServerID 3 at the address of S3 (under any name) until it is re-admitted
+	store, err := tallyserver.LoadBlameStore(filepath.Join(dir, "S1.json"))
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	addr := SIM_IP // S1 is only configured with its own partner port, so it has the IP of S3
+	own := 0
+	for _, r := range store.Reports {
+		if r.Reporter == "S1" && r.BlamedAddr == addr {
+			own++
+		}
+	}
+	if own != 1 {
+		fmt.Printf("\033[31mS1 did not keep the address of S3: %+v\033[0m\n", store.Reports)
+		return false
+	}
+	if !store.Refused(3, addr, "S1") || store.Refused(2, addr, "S1") || store.Refused(3, "elsewhere", "S1") {
+		fmt.Printf("\033[31mS1 does not refuse S3 (only) after the election\033[0m\n")
+		return false
+	}
+	if _, err := store.Readmit("S3"); err != nil || store.Refused(3, addr, "S1") {
+		fmt.Printf("\033[31mS1 still refuses S3 after re-admitting it\033[0m\n")
+		return false
+	}
+	return true
+
+}
+
+func RunTest12() bool {
+
+	// Log test
+	fmt.Println("--- Running test 12 ---")
+	fmt.Println("--- Simulated election with a server offline ---")
+	fmt.Println()
+
+	// Run without S4 (the tally of S1-S3 must still be correct, and verified by the voters)
+	return RunSimulation(SimElection{
+		Seed:     12,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Links:    "*>*:delay=1ms-40ms",
+		Offline:  []int{4},
+	})
+
+}
+
+func RunTest13() bool {
+
+	// Log test
+	fmt.Println("--- Running test 13 ---")
+	fmt.Println("--- Simulated election with server 1 offline ---")
+	fmt.Println()
+
+	// Run without S1 (S2-S4 must close the voting themselves)
+	return RunSimulation(SimElection{
+		Seed:     13,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Links:    "*>*:delay=1ms-40ms",
+		Offline:  []int{1},
+	})
+
+}
+
+func RunTest14() bool {
+
+	// Log test
+	fmt.Println("--- Running test 14 ---")
+	fmt.Println("--- Simulated server crashing when the voting closes ---")
+	fmt.Println()
+
+	// Run with S3 crashing instead of sending its client list (the others must restart without it)
+	return RunSimulation(SimElection{
+		Seed:       14,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{3: {&tallyserver.CrashBehaviour{}}},
+	})
+
+}
+
+func RunTest15() bool {
+
+	// Log test
+	fmt.Println("--- Running test 15 ---")
+	fmt.Println("--- Simulated server 1 restarting before the votes ---")
+	fmt.Println()
+
+	// Run with S1 crashing and restarting (it must rejoin, and close the voting period the others started)
+	return RunSimulation(SimElection{
+		Seed:     15,
+		Voters:   8,
+		VoteTime: 6,
+		P:        1997,
+		K:        1,
+		Links:    "*>*:delay=1ms-40ms",
+		Restarts: []int{1},
+	})
+
+}
+
+func RunTest16() bool {
+
+	// Log test
+	fmt.Println("--- Running test 16 ---")
+	fmt.Println("--- Simulated outage cutting off a server while voting ---")
+	fmt.Println()
+
+	// Run with S4 cut off from its partners for a while (the links must be found dead and reconnected)
+	return RunSimulation(SimElection{
+		Seed:     16,
+		Voters:   8,
+		VoteTime: 8,
+		P:        1997,
+		K:        1,
+		Links:    "*>*:delay=1ms-40ms",
+		Outage:   "S4|S1,S2,S3",
+	})
+
+}
+
+func RunTest17() bool {
+
+	// Log test
+	fmt.Println("--- Running test 17 ---")
+	fmt.Println("--- Simulated server 1 crashing before the voting period ends ---")
+	fmt.Println()
+
+	// Run with S1 crashing once the votes are cast (S2-S4 must still close the voting at the deadline, and tally)
+	return RunSimulation(SimElection{
+		Seed:     17,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Links:    "*>*:delay=1ms-40ms",
+		Crashes:  []int{1},
+	})
+
+}
+
+func RunTest18() bool {
+
+	// Log test
+	fmt.Println("--- Running test 18 ---")
+	fmt.Println("--- Election run in-process through the tallyserver and voteclient packages ---")
+	fmt.Println()
+
+	// Start the servers on a simulated network (as a service embedding them would)
+	rand.Seed(18)
+	network := protocol.NewSimNetwork(18)
+	clientPorts := []string{"10001", "10002", "10003", "10004"}
+	servers := make([]*tallyserver.Server, len(clientPorts))
+	for i := range servers {
+		partnerPorts := []string{"11001", "11002", "11003"}
+		if i == 0 {
+			partnerPorts = partnerPorts[:1]
+		}
+		servers[i] = tallyserver.New(
+			tallyserver.WithID(i+1, fmt.Sprintf("S%v", i+1)),
+			tallyserver.WithListen(SIM_IP, clientPorts[i]),
+			tallyserver.WithPartners([]string{SIM_IP}, partnerPorts),
+			tallyserver.WithVoteTime(4),
+			tallyserver.WithTransport(network.Endpoint(fmt.Sprintf("S%v", i+1))),
+			tallyserver.WithPhaseTimeout(SIM_PHASE_TIMEOUT),
+		)
+		if err := servers[i].Start(); err != nil {
+			fmt.Printf("S%v failed to start: %v\n", i+1, err)
+			return false
+		}
+		defer servers[i].Stop()
+	}
+	subscription := servers[1].Subscribe()
+	time.Sleep(500 * time.Millisecond)
+
+	// A ballot cast with a cancelled context must give up
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	quitter := voteclient.New("quitter", []string{SIM_IP}, clientPorts, 1997, 1)
+	quitter.Transport = network.Endpoint("quitter")
+	if _, err := quitter.Cast(cancelled, 1); err != context.Canceled {
+		fmt.Printf("\033[31mCast with a cancelled context returned %v\033[0m\n", err)
+		return false
+	}
+
+	// Cast 3 yes and 2 no votes, each voter waiting for the verified tally
+	type cast struct {
+		res protocol.Results
+		err error
+	}
+	casts := make(chan cast, 5)
+	ctx, stop := context.WithTimeout(context.Background(), 30*time.Second)
+	defer stop()
+	for i, vote := range []int{1, 0, 1, 1, 0} {
+		voter := voteclient.New(fmt.Sprintf("C%v", i+1), []string{SIM_IP}, clientPorts, 1997, 1)
+		voter.Transport = network.Endpoint(voter.Id)
+		go func(vote int) {
+			res, err := voter.Cast(ctx, vote)
+			casts <- cast{res, err}
+		}(vote)
+	}
+
+	// Every voter and the subscriber must get the right tally
+	res := <-subscription
+	PrintResult(18, res)
+	if res.Yes != 3 || res.No != 2 || res.Error {
+		return false
+	}
+	for i := 0; i < 5; i++ {
+		c := <-casts
+		if c.err != nil || !c.res.SameTally(res) {
+			fmt.Printf("\033[31mVoter got %+v (%v)\033[0m\n", c.res, c.err)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest19() bool {
+	// Init rand
+	rand.Seed(19)
+
+	// Log test
+	fmt.Println("--- Running test 19 ---")
+	fmt.Println("--- Additive sharing between two servers ---")
+	fmt.Println()
+
+	// Create test server (S2 dials it on the partner port)
+	additive, _ := scheme.ByName("additive")
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP}, 15, 10, 1997, 1, additive, nil, nil, nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-scheme", "additive", "-name", "otherServer", "-port", "10002", "-pport", "11001", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+
+	fmt.Println()
+	fmt.Printf("@@@ TEST 19: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Spawn voters
+	TestUtil_ClientVoteInstance(clientVote{id: "1", name: "yay1", Vote: 1, DoSeed: true, Seed: 1, Scheme: "additive"})
+	TestUtil_ClientVoteInstance(clientVote{id: "2", name: "yay2", Vote: 1, DoSeed: true, Seed: 2, Scheme: "additive"})
+	TestUtil_ClientVoteInstance(clientVote{id: "3", name: "yay3", Vote: 1, DoSeed: true, Seed: 3, Scheme: "additive"})
+	TestUtil_ClientVoteInstance(clientVote{id: "4", name: "nay4", Vote: 0, DoSeed: true, Seed: 4, Scheme: "additive"})
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 19: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(19, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	return res.No == 1 && res.Yes == 3 && !res.Error
+}
+
+func RunTest20() bool {
+
+	// Log test
+	fmt.Println("--- Running test 20 ---")
+	fmt.Println("--- Simulated additive election with delays ---")
+	fmt.Println()
+
+	// Run the two additive servers with random delays on all links
+	additive, _ := scheme.ByName("additive")
+	return RunSimulation(SimElection{
+		Seed:     20,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Scheme:   additive,
+		Links:    "*>*:delay=1ms-40ms",
+	})
+
+}
+
+func RunTest21() bool {
+
+	// Log test
+	fmt.Println("--- Running test 21 ---")
+	fmt.Println("--- Simulated plain Shamir election with server 3 offline ---")
+	fmt.Println()
+
+	// Run the three Shamir servers without S3 (S1 and S2 interpolate their two points)
+	shamir, _ := scheme.ByName("shamir")
+	return RunSimulation(SimElection{
+		Seed:     21,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Scheme:   shamir,
+		Links:    "*>*:delay=1ms-40ms",
+		Offline:  []int{3},
+	})
+
+}
+
+func RunTest22() bool {
+
+	// Log test
+	fmt.Println("--- Running test 22 ---")
+	fmt.Println("--- Simulated error detection of a bad server sending a different R-sum to each partner ---")
+	fmt.Println()
+
+	// Run the three detecting servers with S3 splitting its R-sum
+	detect, _ := scheme.ByName("shamir-detect")
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       22,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Scheme:     detect,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{3: {&tallyserver.SplitRSumBehaviour{Offset: 3}}},
+	})
+
+	// The honest servers must not publish a wrong tally, and must detect the lie (it cannot be corrected)
+	if !outcome.Passed() {
+		return false
+	}
+	for i, r := range outcome.Results[:2] {
+		if !r.Error || r.Code != protocol.ERR_NOT_ON_POLYNOMIUM {
+			fmt.Printf("\033[31mS%v did not detect the split R-sum: %+v\033[0m\n", i+1, r)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest23() bool {
+
+	// Log test
+	fmt.Println("--- Running test 23 ---")
+	fmt.Println("--- Tally strategies compared on the same points, with a lying server ---")
+	fmt.Println()
+
+	// The R-sums of f(x) = 5 + 3x, where S3 lies
+	rand.Seed(23)
+	p := 1997
+	honest := []sharing.Point{{X: 1, Y: 8}, {X: 2, Y: 11}, {X: 3, Y: 14}, {X: 4, Y: 17}}
+	lying := append([]sharing.Point{}, honest...)
+	lying[2].Y = 100
+
+	// What each strategy must make of the four points, and of the first three
+	type expect struct {
+		code  protocol.ErrorCode
+		yes   int
+		blame int // X of the blamed point (0 if none)
+	}
+	cases := []struct {
+		strategy string
+		four     expect
+		three    expect
+	}{
+		{tally.INTERPOLATE, expect{protocol.ERR_NONE, sharing.Lagrange(0, p, lying), 0}, expect{protocol.ERR_NONE, sharing.Lagrange(0, p, lying[:3]), 0}},
+		{tally.REEVALUATE, expect{protocol.ERR_NOT_ON_POLYNOMIUM, 0, 0}, expect{protocol.ERR_NOT_ON_POLYNOMIUM, 0, 0}},
+		{tally.MAJORITY, expect{protocol.ERR_NONE, 5, 3}, expect{protocol.ERR_NOT_ON_POLYNOMIUM, 0, 0}},
+		{tally.BERLEKAMP_WELCH, expect{protocol.ERR_NONE, 5, 3}, expect{protocol.ERR_NOT_ON_POLYNOMIUM, 0, 0}},
+	}
+
+	// Run every strategy on the same points
+	passed := true
+	check := func(name string, points []sharing.Point, want expect) {
+		strategy, _ := tally.ByName(name)
+		result, diag := strategy.Tally(points, tally.Params{Name: name, ServerID: 1, P: p, K: 1, Servers: len(points)})
+		blamed := 0
+		if len(result.Blames) == 1 {
+			blamed = result.Blames[0].Point.X
+		}
+		ok := result.Code == want.code && (want.code != protocol.ERR_NONE || result.Yes == want.yes) && blamed == want.blame && len(result.Blames) <= 1
+		if !ok {
+			passed = false
+			fmt.Printf("\033[31m%s on %v points gave %+v, expected %+v\033[0m\n", name, len(points), result, want)
+		}
+		fmt.Printf("\033[33m@@@ TEST 23: %v points: %v\033[0m\n", len(points), diag)
+	}
+	for _, c := range cases {
+		check(c.strategy, lying, c.four)
+		check(c.strategy, lying[:3], c.three)
+	}
+
+	// Every strategy must agree on the honest points
+	for _, c := range cases {
+		check(c.strategy, honest, expect{protocol.ERR_NONE, 5, 0})
+	}
+	return passed
+
+}
+
+func RunTest24() bool {
+
+	// Log test
+	fmt.Println("--- Running test 24 ---")
+	fmt.Println("--- Simulated bad server sending a different R-sum to each partner, tallied by majority ---")
+	fmt.Println()
+
+	// Run with S4 splitting its R-sum, where the servers and voters take the sum most subsets of points agree on
+	majority, _ := tally.ByName(tally.MAJORITY)
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       24,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Strategy:   majority,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{4: {&tallyserver.SplitRSumBehaviour{Offset: 3}}},
+	})
+
+	// Every honest server must correct the tally and blame S4
+	if !outcome.Passed() {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		blamedS4 := len(outcome.Blames[i]) > 0
+		for _, report := range outcome.Blames[i] {
+			blamedS4 = blamedS4 && report.Blamed == 4
+		}
+		if !blamedS4 {
+			fmt.Printf("\033[31mS%v blamed %+v\033[0m\n", i+1, outcome.Blames[i])
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest25() bool {
+
+	// Log test
+	fmt.Println("--- Running test 25 ---")
+	fmt.Println("--- Simulated additive election among five servers ---")
+	fmt.Println()
+
+	// Run the five additive servers with random delays on all links
+	return RunSimulation(SimElection{
+		Seed:     25,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Scheme:   scheme.Additive{N: 5},
+		Links:    "*>*:delay=1ms-40ms",
+	})
+
+}
+
+func RunTest26() bool {
+
+	// Log test
+	fmt.Println("--- Running test 26 ---")
+	fmt.Println("--- Simulated additive election among five servers with server 5 crashing ---")
+	fmt.Println()
+
+	// Run the five additive servers with S5 crashing once the votes are cast (its shares are needed to add up the votes)
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:     26,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Scheme:   scheme.Additive{N: 5},
+		Links:    "*>*:delay=1ms-40ms",
+		Crashes:  []int{5},
+	})
+
+	// Every server online must give up on the tally, instead of adding up the R-sums it has
+	if outcome.Hung {
+		return false
+	}
+	for i, r := range outcome.Results[:4] {
+		if !r.Error || r.Code != protocol.ERR_TOO_FEW_SERVERS {
+			fmt.Printf("\033[31mS%v did not give up: %+v\033[0m\n", i+1, r)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest27() bool {
+
+	// Log test
+	fmt.Println("--- Running test 27 ---")
+	fmt.Println("--- Simulated additive election among three servers, MAC checked ---")
+	fmt.Println()
+
+	// Run the three additive servers with MACs dealt to every server and voter
+	return RunSimulation(SimElection{
+		Seed:     27,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Scheme:   scheme.Additive{N: 3},
+		MAC:      true,
+		Links:    "*>*:delay=1ms-40ms",
+	})
+
+}
+
+func RunTest28() bool {
+
+	// Log test
+	fmt.Println("--- Running test 28 ---")
+	fmt.Println("--- Simulated MAC check catching server 2 altering its R-sum ---")
+	fmt.Println()
+
+	// Run the two additive servers with S2 sending a random R-sum (undetectable without MACs)
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       28,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Scheme:     scheme.Additive{},
+		MAC:        true,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{2: {&tallyserver.WrongRSumBehaviour{Mode: 2}}},
+	})
+
+	// S1 must abort the tally and blame S2 (the only partner who could have altered its R-sum)
+	if outcome.Hung {
+		return false
+	}
+	if r := outcome.Results[0]; !r.Error || r.Code != protocol.ERR_MAC_CHECK_FAILED {
+		fmt.Printf("\033[31mS1 did not abort on the MAC check: %+v\033[0m\n", r)
+		return false
+	}
+	blames := outcome.Blames[0]
+	if len(blames) != 1 || blames[0].Blamed != 2 || blames[0].Method != protocol.BLAME_MAC_CHECK {
+		fmt.Printf("\033[31mS1 did not blame S2 for the MAC check: %v\033[0m\n", blames)
+		return false
+	}
+	return true
+
+}
+
+func RunTest29() bool {
+	// Init rand
+	rand.Seed(29)
+
+	// Log test
+	fmt.Println("--- Running test 29 ---")
+	fmt.Println("--- MAC checked additive sharing between two servers, with keys from the dealer ---")
+	fmt.Println()
+
+	// Deal the keys to a folder (as -mode deal does)
+	dir, e := os.MkdirTemp("", "voting-keys")
+	if e != nil {
+		fmt.Printf("could not make key folder: %v.\n", e)
+		return false
+	}
+	defer os.RemoveAll(dir)
+	keys, masks := spdz.Deal(sharing.Deterministic(29), 2, 1997, []string{"yay1", "yay2", "yay3", "nay4"})
+	if e := spdz.Save(dir, keys, masks); e != nil {
+		fmt.Printf("could not deal keys: %v.\n", e)
+		return false
+	}
+
+	// Create test server (S2 dials it on the partner port)
+	additive, _ := scheme.ByName("additive")
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP}, 15, 10, 1997, 1, additive, nil, &keys[0], nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-scheme", "additive", "-keys", dir, "-name", "otherServer", "-port", "10002", "-pport", "11001", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+
+	fmt.Println()
+	fmt.Printf("@@@ TEST 29: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Spawn voters (sending their masked votes)
+	TestUtil_ClientVoteInstance(clientVote{id: "1", name: "yay1", Vote: 1, DoSeed: true, Seed: 1, Scheme: "additive", Keys: dir})
+	TestUtil_ClientVoteInstance(clientVote{id: "2", name: "yay2", Vote: 1, DoSeed: true, Seed: 2, Scheme: "additive", Keys: dir})
+	TestUtil_ClientVoteInstance(clientVote{id: "3", name: "yay3", Vote: 1, DoSeed: true, Seed: 3, Scheme: "additive", Keys: dir})
+	TestUtil_ClientVoteInstance(clientVote{id: "4", name: "nay4", Vote: 0, DoSeed: true, Seed: 4, Scheme: "additive", Keys: dir})
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 29: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(29, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	return res.No == 1 && res.Yes == 3 && !res.Error
+}
+
+func RunTest30() bool {
+
+	// Log test
+	fmt.Println("--- Running test 30 ---")
+	fmt.Println("--- Share randomness covering the whole field, and reproducible only when injected ---")
+	fmt.Println()
+
+	// Every scheme must share a vote with secure randomness, and the shares must add up (or interpolate) to the vote
+	// (the schemes encrypting the vote share nothing)
+	p := 5
+	passed := true
+	for _, name := range scheme.Names {
+		sch, _ := scheme.ByName(name)
+		if scheme.Encrypts(sch) {
+			continue
+		}
+		shares := sch.Share(sharing.Secure, 1, p, 1)
+		points := make([]sharing.Point, len(shares))
+		for i, v := range shares {
+			points[i] = sharing.Point{X: i + 1, Y: v}
+		}
+		got := sharing.Lagrange(0, p, points)
+		if _, ok := sch.(scheme.Additive); ok {
+			got = sharing.SumField(p, shares...)
+		}
+		if got != 1 {
+			fmt.Printf("\033[31m%s shares %v do not give the vote back (got %v)\033[0m\n", name, shares, got)
+			passed = false
+		}
+	}
+
+	// The shares must cover the whole field, p-1 included
+	seen := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		for _, v := range sharing.AdditiveSecrify(sharing.Secure, 0, p, 2) {
+			seen[v]++
+		}
+	}
+	fmt.Printf("\033[33m@@@ TEST 30: Additive shares of 0 in Z_%v: %v\033[0m\n", p, seen)
+	for v := 0; v < p; v++ {
+		if seen[v] == 0 {
+			fmt.Printf("\033[31mShare %v never drawn\033[0m\n", v)
+			passed = false
+		}
+	}
+
+	// The same seed must give the same shares, while secure shares are not reproduced
+	a := sharing.Secrify(sharing.Deterministic(30), 1, 1997, 3, 4)
+	b := sharing.Secrify(sharing.Deterministic(30), 1, 1997, 3, 4)
+	fmt.Printf("\033[33m@@@ TEST 30: Seeded shares %v and %v\033[0m\n", a, b)
+	for i := range a {
+		if a[i] != b[i] {
+			fmt.Printf("\033[31mThe same seed gave shares %v and %v\033[0m\n", a, b)
+			passed = false
+		}
+	}
+	same := 0
+	for i := 0; i < 10; i++ {
+		c, d := sharing.Secrify(sharing.Secure, 1, 1997, 3, 4), sharing.Secrify(sharing.Secure, 1, 1997, 3, 4)
+		if c[0] == d[0] && c[1] == d[1] && c[2] == d[2] && c[3] == d[3] {
+			same++
+		}
+	}
+	if same > 0 {
+		fmt.Printf("\033[31mSecure randomness gave the same shares %v time(s)\033[0m\n", same)
+		passed = false
+	}
+	return passed
+
+}
+
+func RunTest31() bool {
+
+	// Log test
+	fmt.Println("--- Running test 31 ---")
+	fmt.Println("--- Polynomials and matrices over the field ---")
+	fmt.Println()
+
+	p := 1997
+	rng := sharing.Deterministic(31)
+	passed := true
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			passed = false
+			fmt.Printf("\033[31m"+format+"\033[0m\n", args...)
+		}
+	}
+
+	// Random polynomials must divide back (f*g + r) / g = f rem r, and interpolate back from their points
+	for i := 0; i < 20; i++ {
+		f := sharing.NewPolynomial(p, rng.Intn(p), rng.Intn(p), rng.Intn(p), 1+rng.Intn(p-1))
+		g := sharing.NewPolynomial(p, rng.Intn(p), 1+rng.Intn(p-1))
+		r := sharing.NewPolynomial(p, rng.Intn(p))
+		q, rem, err := f.Mul(g).Add(r).DivMod(g)
+		check(err == nil && q.Equal(f) && rem.Equal(r), "(%v)(%v) + %v divided by %v gave %v rem %v (%v)", f, g, r, g, q, rem, err)
+		points := make([]sharing.Point, 4)
+		for j := range points {
+			points[j] = sharing.Point{X: j + 1, Y: f.Eval(j + 1)}
+		}
+		h, err := sharing.Interpolate(p, points)
+		check(err == nil && h.Equal(f), "interpolating %v gave %v (%v)", f, h, err)
+		check(f.Sub(f).IsZero(), "%v - itself is not zero", f)
+	}
+
+	// Horner evaluation of 2 + 3x + x^2 = (x+1)(x+2), whose roots are -1 and -2
+	f := sharing.NewPolynomial(p, 2, 3, 1)
+	check(f.Eval(10) == 132 && f.Eval(p) == 2, "%v at 10 and p gave %v and %v", f, f.Eval(10), f.Eval(p))
+	roots := f.Roots()
+	check(len(roots) == 2 && roots[0] == p-2 && roots[1] == p-1, "roots of %v gave %v", f, roots)
+	_, _, err := f.DivMod(sharing.NewPolynomial(p))
+	check(err == sharing.ErrDivisionByZero, "dividing by zero gave %v", err)
+	_, err = sharing.Interpolate(p, []sharing.Point{{X: 1, Y: 2}, {X: 1, Y: 3}})
+	check(err == sharing.ErrDuplicateX, "interpolating the same x twice gave %v", err)
+
+	// A random system must be solved, and its inverse must give the identity
+	rows := make([][]int, 3)
+	for i := range rows {
+		rows[i] = []int{rng.Intn(p), rng.Intn(p), rng.Intn(p)}
+	}
+	m := sharing.NewMatrix(p, rows)
+	x := []int{rng.Intn(p), rng.Intn(p), rng.Intn(p)}
+	b, _ := m.MulVec(x)
+	sol, err := m.Solve(b)
+	check(err == nil && sol[0] == x[0] && sol[1] == x[1] && sol[2] == x[2], "solving %v x = %v gave %v, not %v (%v)", rows, b, sol, x, err)
+	inv, err := m.Inverse()
+	id, _ := m.Mul(inv)
+	check(err == nil && fmt.Sprint(id.Rows) == fmt.Sprint(sharing.IdentityMatrix(p, 3).Rows), "%v times its inverse gave %v (%v)", rows, id.Rows, err)
+	det, _ := sharing.NewMatrix(p, [][]int{{1, 2}, {3, 4}}).Det()
+	check(det == p-2, "determinant of [[1 2] [3 4]] gave %v", det)
+
+	// Singular matrices must be told apart from inconsistent systems
+	singular := sharing.NewMatrix(p, [][]int{{1, 2}, {2, 4}})
+	_, err = singular.Solve([]int{3, 6})
+	check(err == sharing.ErrSingular && singular.Singular() && singular.Rank() == 1, "singular matrix gave %v, rank %v", err, singular.Rank())
+	_, err = singular.Solve([]int{3, 7})
+	check(err == sharing.ErrInconsistent, "inconsistent system gave %v", err)
+
+	// Berlekamp-Welch must correct two wrong points among seven of a polynomium of degree 2, and give up with three
+	f = sharing.NewPolynomial(p, 5, 3, 7)
+	points := make([]sharing.Point, 7)
+	for j := range points {
+		points[j] = sharing.Point{X: j + 1, Y: f.Eval(j + 1)}
+	}
+	points[1].Y, points[5].Y = 100, 200
+	g, E, err := sharing.BerlekampWelch(points, 2, 2, p)
+	fmt.Printf("\033[33m@@@ TEST 31: Decoded f(x) = %v with error locator E(x) = %v (%v)\033[0m\n", g, E, err)
+	check(err == nil && g.Equal(f) && fmt.Sprint(E.Roots()) == "[2 6]", "decoding gave %v and %v (%v)", g, E, err)
+	points[3].Y = 300
+	_, _, err = sharing.BerlekampWelch(points, 2, 2, p)
+	check(err != nil, "decoding three wrong points did not fail")
+
+	return passed
+
+}
+
+func RunTest32() bool {
+
+	// Log test
+	fmt.Println("--- Running test 32 ---")
+	fmt.Println("--- Precomputed Lagrange weights ---")
+	fmt.Println()
+
+	p := 1997
+	rng := sharing.Deterministic(32)
+	passed := true
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			passed = false
+			fmt.Printf("\033[31m"+format+"\033[0m\n", args...)
+		}
+	}
+
+	// Batched inverses must be the inverses
+	as := make([]int, 50)
+	for i := range as {
+		as[i] = 1 + rng.Intn(p-1)
+	}
+	for i, inv := range sharing.BatchInverse(as, p) {
+		check(sharing.MulField(as[i], inv, p) == 1, "batched inverse of %v gave %v", as[i], inv)
+	}
+
+	// The weights must evaluate like the interpolated polynomium, at 0 and anywhere else
+	for n := 2; n <= 10; n++ {
+		xs := make([]int, 0, n)
+		for len(xs) < n {
+			x := 1 + rng.Intn(p-1)
+			fresh := true
+			for _, y := range xs {
+				fresh = fresh && x != y
+			}
+			if fresh {
+				xs = append(xs, x)
+			}
+		}
+		points := make([]sharing.Point, n)
+		ys := make([]int, n)
+		for i := range points {
+			ys[i] = rng.Intn(p)
+			points[i] = sharing.Point{X: xs[i], Y: ys[i]}
+		}
+		f, _ := sharing.Interpolate(p, points)
+		for _, at := range []int{0, rng.Intn(p), xs[0]} {
+			w, err := sharing.NewWeights(p, at, xs)
+			check(err == nil && w.Combine(ys) == f.Eval(at), "weights at %v of %v gave %v, not %v (%v)", at, xs, w.Combine(ys), f.Eval(at), err)
+			check(sharing.Lagrange(at, p, points) == f.Eval(at), "Lagrange at %v of %v gave %v, not %v", at, points, sharing.Lagrange(at, p, points), f.Eval(at))
+		}
+	}
+
+	// Many secrets shared among the same servers must all be opened by the same weights
+	xs := []int{1, 2, 3, 4, 5}
+	w, _ := sharing.WeightsFor(p, 0, xs)
+	secrets := make([]int, 100)
+	shares := make([][]int, len(secrets))
+	for s := range secrets {
+		secrets[s] = rng.Intn(p)
+		shares[s] = sharing.Secrify(rng, secrets[s], p, 4, 5)
+	}
+	opened := w.CombineAll(shares)
+	for s := range secrets {
+		check(opened[s] == secrets[s], "secret %v opened as %v, not %v", s, opened[s], secrets[s])
+	}
+	again, _ := sharing.WeightsFor(p, 0, xs)
+	check(fmt.Sprint(again.W) == fmt.Sprint(w.W), "cached weights %v differ from %v", again.W, w.W)
+	fmt.Printf("\033[33m@@@ TEST 32: Weights at 0 of %v are %v\033[0m\n", xs, w.W)
+
+	// The same x twice cannot be interpolated
+	_, err := sharing.NewWeights(p, 0, []int{1, 2, 1})
+	check(err == sharing.ErrDuplicateX, "weights of the same x twice gave %v", err)
+
+	return passed
+
+}
+
+func AssertIsTrue(condition bool, msg string) {
+	if !condition {
+		panic(fmt.Errorf("assert condition failed: %s", msg))
+	}
+}
+
+func RunTest33() bool {
+
+	// Log test
+	fmt.Println("--- Running test 33 ---")
+	fmt.Println("--- Threshold ElGamal key generation and decryption among three servers ---")
+	fmt.Println()
+
+	random := rand.New(rand.NewSource(33))
+	passed := true
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			passed = false
+			fmt.Printf("\033[31m"+format+"\033[0m\n", args...)
+		}
+	}
+
+	// Every server deals a polynomium of degree 1, and checks the shares dealt to it
+	n, t := 3, 1
+	dealings := make([]*elgamal.Dealing, n)
+	commitments := map[int][]*big.Int{}
+	for i := range dealings {
+		dealings[i], _ = elgamal.NewDealing(random, t)
+		commitments[i+1] = dealings[i].Commitments
+	}
+	keys := make([]elgamal.KeyShare, n)
+	for j := 1; j <= n; j++ {
+		shares := map[int]*big.Int{}
+		for i, d := range dealings {
+			shares[i+1] = d.Share(j)
+			check(elgamal.VerifyShare(d.Commitments, j, shares[i+1]), "share of S%v dealt by S%v does not match its commitments", j, i+1)
+		}
+		keys[j-1], _ = elgamal.CombineShares(j, shares, commitments)
+		check(keys[j-1].H.Cmp(keys[0].H) == 0, "S%v got another public key than S1", j)
+	}
+	wrong := new(big.Int).Add(dealings[0].Share(2), big.NewInt(1))
+	check(!elgamal.VerifyShare(dealings[0].Commitments, 2, wrong), "a wrong share matched the commitments")
+
+	// The product of the ballots must decrypt to the sum of the votes with every pair of servers (and all three)
+	votes := []int{1, 0, 1, 1, 0}
+	total := elgamal.Zero()
+	for _, v := range votes {
+		c, _ := elgamal.Encrypt(random, keys[0].H, v)
+		total = total.Mul(c)
+	}
+	partials := map[int]*big.Int{}
+	for _, key := range keys {
+		partials[key.ServerID] = key.PartialDecrypt(total)
+	}
+	for _, group := range [][]int{{1, 2}, {1, 3}, {2, 3}, {1, 2, 3}} {
+		some := map[int]*big.Int{}
+		for _, id := range group {
+			some[id] = partials[id]
+		}
+		gm, err := elgamal.Combine(total, some, t)
+		sum := -1
+		if err == nil {
+			sum, err = elgamal.DiscreteLog(gm, len(votes))
+		}
+		check(err == nil && sum == 3, "servers %v decrypted to %v, not 3 (%v)", group, sum, err)
+	}
+
+	// A single partial decryption is too few
+	_, err := elgamal.Combine(total, map[int]*big.Int{1: partials[1]}, t)
+	check(err == elgamal.ErrTooFewPartials, "decrypting with one server gave %v", err)
+
+	// A partial decryption must be proven against the verification key of its server, and a wrong one must not be
+	ctx := elgamal.Context{Election: "e", Key: keys[1].H}
+	partial, proof, _ := keys[1].ProvePartial(random, ctx, total)
+	vk := elgamal.VerificationKey(commitments, 2)
+	check(elgamal.VerifyPartial(ctx, vk, total, partial, proof), "the partial decryption of S2 was not proven")
+	bad := new(big.Int).Mul(partial, elgamal.G)
+	check(!elgamal.VerifyPartial(ctx, vk, total, bad.Mod(bad, elgamal.P), proof), "a wrong partial decryption of S2 was proven")
+	check(!elgamal.VerifyPartial(ctx, elgamal.VerificationKey(commitments, 3), total, partial, proof), "the partial decryption of S2 was proven as S3's")
+
+	// The proofs and signatures only hold in the election and under the key they were made for
+	check(!elgamal.VerifyPartial(elgamal.Context{Election: "other", Key: ctx.Key}, vk, total, partial, proof), "the partial decryption of S2 was proven in another election")
+	check(!elgamal.VerifyPartial(elgamal.Context{Election: ctx.Election, Key: elgamal.G}, vk, total, partial, proof), "the partial decryption of S2 was proven under another key")
+	sig, _ := keys[1].Sign(random, ctx, total.A, partial)
+	check(elgamal.VerifySignature(ctx, vk, sig, total.A, partial), "the signature of S2 did not hold")
+	check(!elgamal.VerifySignature(elgamal.Context{Election: "other", Key: ctx.Key}, vk, sig, total.A, partial), "the signature of S2 held in another election")
+
+	// Values outside the group must be refused
+	_, err = elgamal.Decode(elgamal.Encode(elgamal.P))
+	check(err != nil, "decoding P did not fail")
+	_, err = elgamal.Decode(elgamal.Encode(new(big.Int).Sub(elgamal.P, big.NewInt(1))))
+	check(err != nil, "decoding P-1 (not a square) did not fail")
+
+	return passed
+
+}
+
+func RunTest34() bool {
+
+	// Log test
+	fmt.Println("--- Running test 34 ---")
+	fmt.Println("--- Simulated threshold ElGamal election among three servers ---")
+	fmt.Println()
+
+	// Every voter casts an encrypted ballot at one of the servers, which decrypt the product together
+	return RunSimulation(SimElection{
+		Seed:     34,
+		Voters:   8,
+		VoteTime: 5,
+		P:        1997,
+		K:        1,
+		Scheme:   scheme.ElGamal{N: 3},
+		Links:    "*>*:delay=1ms-40ms",
+	})
+
+}
+
+func RunTest35() bool {
+
+	// Log test
+	fmt.Println("--- Running test 35 ---")
+	fmt.Println("--- Simulated threshold ElGamal election with server 3 sending a wrong partial decryption ---")
+	fmt.Println()
+
+	// Run with S3 lying about its partial decryption, which only the pair S1 and S2 decrypts without
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       35,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Scheme:     scheme.ElGamal{N: 3},
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{3: {&tallyserver.WrongPartialBehaviour{}}},
+	})
+
+	// The honest servers must still decrypt the tally, and blame only S3 for its decryption (a server done decrypting
+	// before the wrong partial decryption arrives has nobody to blame)
+	if !outcome.Passed() {
+		return false
+	}
+	blamed := false
+	for i := 0; i < 2; i++ {
+		for _, report := range outcome.Blames[i] {
+			if report.Blamed != 3 || report.Method != protocol.BLAME_DECRYPTION {
+				fmt.Printf("\033[31mS%v blamed %+v\033[0m\n", i+1, report)
+				return false
+			}
+			blamed = true
+		}
+	}
+	if !blamed {
+		fmt.Printf("\033[31mNo server blamed S3 for its decryption.\033[0m\n")
+	}
+	return blamed
+
+}
+
+func RunTest36() bool {
+	// Init rand
+	rand.Seed(36)
+
+	// Log test
+	fmt.Println("--- Running test 36 ---")
+	fmt.Println("--- Threshold ElGamal election among three servers, every voter casting its ballot at one server ---")
+	fmt.Println()
+
+	// Create test server (S2 and S3 dial it on the partner port)
+	encrypted, _ := scheme.ByName("elgamal")
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP}, 15, 10, 1997, 1, encrypted, nil, nil, nil, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn servers
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-scheme", "elgamal", "-name", "otherServer", "-port", "10002", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+	time.Sleep(2 * time.Second)
+	if _, e := TestUtil_SpawnTestProcess("-id", "3", "-mode", "server", "-scheme", "elgamal", "-name", "ThirdServer", "-port", "10003", "-pport", "11001,11002,11003", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("third server failed Error was %v.\n", e)
+		return false
+	}
+
+	fmt.Println()
+	fmt.Printf("@@@ TEST 36: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Spawn voters (the seed decides which server each of them votes at)
+	TestUtil_ClientVoteInstance(clientVote{id: "1", name: "yay1", Vote: 1, DoSeed: true, Seed: 1, Scheme: "elgamal"})
+	TestUtil_ClientVoteInstance(clientVote{id: "2", name: "yay2", Vote: 1, DoSeed: true, Seed: 2, Scheme: "elgamal"})
+	TestUtil_ClientVoteInstance(clientVote{id: "3", name: "yay3", Vote: 1, DoSeed: true, Seed: 3, Scheme: "elgamal"})
+	TestUtil_ClientVoteInstance(clientVote{id: "4", name: "nay4", Vote: 0, DoSeed: true, Seed: 4, Scheme: "elgamal"})
+	TestUtil_ClientVoteInstance(clientVote{id: "5", name: "nay5", Vote: 0, DoSeed: true, Seed: 5, Scheme: "elgamal"})
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 36: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(36, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	return res.No == 2 && res.Yes == 3 && !res.Error
+}
+
+func RunTest37() bool {
+
+	// Log test
+	fmt.Println("--- Running test 37 ---")
+	fmt.Println("--- Proofs that encrypted ballots are 0 or 1, and that multi-candidate ballots are one-hot ---")
+	fmt.Println()
+
+	random := rand.New(rand.NewSource(37))
+	passed := true
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			passed = false
+			fmt.Printf("\033[31m"+format+"\033[0m\n", args...)
+		}
+	}
+	x, _ := elgamal.RandomExponent(random)
+	h := new(big.Int).Exp(elgamal.G, x, elgamal.P)
+	ctx := elgamal.Context{Election: "e", Key: h}
+	encrypt := func(v int) (elgamal.Ciphertext, *big.Int) {
+		r, _ := elgamal.RandomExponent(random)
+		return elgamal.EncryptWith(h, v, r), r
+	}
+
+	// Ballots of 0 and 1 must be proven (also after sending the proof), but only for the voter that made the proof
+	for v := 0; v <= 1; v++ {
+		c, r := encrypt(v)
+		proof, _ := elgamal.ProveBit(random, ctx, c, v, r, "C1")
+		decoded, err := elgamal.DecodeBitProof(proof.Encode())
+		check(err == nil && elgamal.VerifyBit(ctx, c, decoded, "C1"), "the ballot of %v was not proven (%v)", v, err)
+		check(!elgamal.VerifyBit(ctx, c, proof, "C2"), "the proof of the ballot of %v held for another voter", v)
+		check(!elgamal.VerifyBit(ctx, c.Mul(c), proof, "C1"), "the proof of the ballot of %v held for another ballot", v)
+		check(!elgamal.VerifyBit(elgamal.Context{Election: "other", Key: h}, c, proof, "C1"), "the proof of the ballot of %v held in another election", v)
+	}
+
+	// A ballot of 7 (or -1) must not be proven, whichever bit the proof claims
+	for _, v := range []int{7, -1} {
+		c, r := encrypt(v)
+		for claim := 0; claim <= 1; claim++ {
+			proof, _ := elgamal.ProveBit(random, ctx, c, claim, r, "C1")
+			check(!elgamal.VerifyBit(ctx, c, proof, "C1"), "the ballot of %v was proven as %v", v, claim)
+		}
+	}
+
+	return passed
+
+}
+
+func RunTest38() bool {
+
+	// Log test
+	fmt.Println("--- Running test 38 ---")
+	fmt.Println("--- Simulated threshold ElGamal election with proven ballots, and a voter encrypting 7 ---")
+	fmt.Println()
+
+	// Run with every ballot proven, where C3 encrypts 7 (its proof cannot hold, so every server must reject it)
+	return RunSimulation(SimElection{
+		Seed:      38,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Scheme:    scheme.ElGamal{N: 3},
+		Proofs:    true,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{3: {Mode: voteclient.CLIENT_MODE_OUT_OF_RANGE, Arg: 7}},
+	})
+
+}
+
+func RunTest39() bool {
+
+	// Log test
+	fmt.Println("--- Running test 39 ---")
+	fmt.Println("--- Blind signature credentials from the registration authority ---")
+	fmt.Println()
+
+	random := rand.New(rand.NewSource(39))
+	passed := true
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			passed = false
+			fmt.Printf("\033[31m"+format+"\033[0m\n", args...)
+		}
+	}
+	key, err := credential.GenerateKey(random, 1024)
+	if err != nil {
+		fmt.Printf("could not generate the authority key: %v.\n", err)
+		return false
+	}
+	other, _ := credential.GenerateKey(random, 1024)
+	authority := credential.NewAuthority("RA", key, []string{"C1", "C2"})
+	issue := func(voter string) (*credential.Token, *big.Int, error) {
+		serial, _ := credential.NewSerial(random)
+		blinded, b, _ := key.PublicKey.Blind(random, serial)
+		signed, err := authority.Issue(voter, blinded)
+		if err != nil {
+			return nil, blinded, err
+		}
+		token, err := key.PublicKey.Unblind(serial, signed, b)
+		return token, blinded, err
+	}
+
+	// An eligible voter gets a token signed by the authority (also after sending it), which the authority never saw
+	token, blinded, err := issue("C1")
+	check(err == nil && key.PublicKey.Verify(token), "C1 got no credential (%v)", err)
+	if token == nil {
+		return false
+	}
+	decoded, err := credential.DecodeToken(token.Encode())
+	check(err == nil && key.PublicKey.Verify(decoded) && decoded.Key() == token.Key(), "the credential did not survive encoding (%v)", err)
+	check(new(big.Int).Exp(token.Signature, key.E, key.N).Cmp(blinded) != 0, "the authority saw the hash of the serial")
+
+	// The token must not hold with another serial, or under the key of another authority
+	forged := &credential.Token{Serial: append([]byte{}, token.Serial...), Signature: token.Signature}
+	forged.Serial[0] ^= 1
+	check(!key.PublicKey.Verify(forged), "the credential held for another serial")
+	check(!other.PublicKey.Verify(token), "the credential held under another authority")
+
+	// A voter only gets one credential, and voters not on the list get none
+	_, _, err = issue("C1")
+	check(err != nil, "C1 got a second credential")
+	_, _, err = issue("C9")
+	check(err != nil, "C9 got a credential without being eligible")
+
+	// Over the (simulated) network, a voter gets its credential the same way, once
+	network := protocol.NewSimNetwork(39)
+	authority.Transport = network.Endpoint("RA")
+	if err := authority.Start(SIM_IP, SIM_AUTHORITY_PORT); err != nil {
+		fmt.Printf("could not start the authority: %v.\n", err)
+		return false
+	}
+	defer authority.Stop()
+	token2, err := credential.Obtain(network.Endpoint("C2"), SIM_IP, SIM_AUTHORITY_PORT, "C2", &key.PublicKey, random)
+	check(err == nil && key.PublicKey.Verify(token2) && token2.Key() != token.Key(), "C2 got no credential over the network (%v)", err)
+	_, err = credential.Obtain(network.Endpoint("C2"), SIM_IP, SIM_AUTHORITY_PORT, "C2", &key.PublicKey, random)
+	check(err != nil, "C2 got a second credential over the network")
+	check(authority.Issued() == 2, "the authority issued %v credential(s), not 2", authority.Issued())
+
+	return passed
+
+}
+
+func RunTest40() bool {
+
+	// Log test
+	fmt.Println("--- Running test 40 ---")
+	fmt.Println("--- Simulated election where voters join with credentials, and a voter votes again under another ID ---")
+	fmt.Println()
+
+	// C3 votes again as C3-1, which the authority does not issue a credential to (so only its first vote counts)
+	return RunSimulation(SimElection{
+		Seed:      40,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Anonymous: true,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{3: {Mode: voteclient.CLIENT_MODE_DOUBLE_VOTE}},
+	})
+
+}
+
+func RunTest41() bool {
+	// Init rand
+	rand.Seed(41)
+
+	// Log test
+	fmt.Println("--- Running test 41 ---")
+	fmt.Println("--- Voters joining two servers with credentials from the registration authority ---")
+	fmt.Println()
+
+	// Start the authority (as -mode authority does), writing its key for the servers and voters
+	dir, e := os.MkdirTemp("", "voting-authority")
+	if e != nil {
+		fmt.Printf("could not make authority folder: %v.\n", e)
+		return false
+	}
+	defer os.RemoveAll(dir)
+	key, e := credential.GenerateKey(nil, credential.KEY_BITS)
+	if e != nil {
+		fmt.Printf("could not generate the authority key: %v.\n", e)
+		return false
+	}
+	keyFile := filepath.Join(dir, "authority.json")
+	if e := key.PublicKey.Save(keyFile); e != nil {
+		fmt.Printf("could not write the authority key: %v.\n", e)
+		return false
+	}
+	authority := credential.NewAuthority("RA", key, []string{"yay1", "yay2", "nay3"})
+	if e := authority.Start(localIP, "9001"); e != nil {
+		fmt.Printf("could not start the authority: %v.\n", e)
+		return false
+	}
+	defer authority.Stop()
+
+	// Create test server (S2 dials it on the partner port)
+	additive, _ := scheme.ByName("additive")
+	localTestServer := CreateNewServer(1, "Main Server", protocol.DEFAULT_ELECTION, localIP, "10001", []string{"11001"}, []string{localIP}, 15, 10, 1997, 1, additive, nil, nil, &key.PublicKey, nil, nil, false)
+
+	time.Sleep(2 * time.Second)
+	// Spawn server
+	if _, e := TestUtil_SpawnTestProcess("-id", "2", "-mode", "server", "-scheme", "additive", "-authority", keyFile, "-name", "otherServer", "-port", "10002", "-pport", "11001", "-t", "15", "-s", "1"); e != nil {
+		fmt.Printf("second server failed Error was %v.\n", e)
+		return false
+	}
+
+	fmt.Println()
+	fmt.Printf("@@@ TEST 41: Waiting 5s before spawning clients\n")
+	fmt.Println()
+	time.Sleep(5 * time.Second)
+
+	// Spawn voters (yay2 also votes as yay2-1, and mallory is not eligible, so neither gets a credential)
+	TestUtil_ClientVoteInstance(clientVote{id: "1", name: "yay1", Vote: 1, DoSeed: true, Seed: 1, Scheme: "additive", Authority: keyFile, APort: "9001"})
+	TestUtil_ClientVoteInstance(clientVote{id: "2", name: "yay2", Vote: 1, DoSeed: true, Seed: 2, Scheme: "additive", Authority: keyFile, APort: "9001", ClientMode: voteclient.CLIENT_MODE_DOUBLE_VOTE})
+	TestUtil_ClientVoteInstance(clientVote{id: "3", name: "nay3", Vote: 0, DoSeed: true, Seed: 3, Scheme: "additive", Authority: keyFile, APort: "9001"})
+	TestUtil_ClientVoteInstance(clientVote{id: "4", name: "mallory", Vote: 1, DoSeed: true, Seed: 4, Scheme: "additive", Authority: keyFile, APort: "9001"})
+
+	// Wait for results
+	fmt.Println()
+	fmt.Printf("@@@ TEST 41: Waiting for results\n")
+	fmt.Println()
+
+	// Wait for local test server
+	res := localTestServer.WaitForResults()
+
+	PrintResult(41, res)
+
+	// Wait 1s before passing/failing
+	time.Sleep(1 * time.Second)
+
+	// Halt server
+	localTestServer.Halt()
+
+	// Do asserts
+	return res.No == 1 && res.Yes == 2 && !res.Error && authority.Issued() == 3
+}
+
+func TestUtil_ClientVoteInstance(data clientVote) {
+	if data.Scheme == "" {
+		data.Scheme = scheme.DEFAULT
+	}
+	sch, err := scheme.ByName(data.Scheme)
+	if err != nil {
+		panic(err)
+	}
+	if data.ports == nil {
+		data.ports = make([]string, sch.Servers())
+		for i := range data.ports {
+			data.ports[i] = fmt.Sprint(10001 + i)
+		}
+	}
+	if data.SwapPorts == nil {
+		data.SwapPorts = make([]int, len(data.ports))
+		for i := range data.SwapPorts {
+			data.SwapPorts[i] = i
+		}
+	}
+	ports := make([]string, len(data.SwapPorts))
+	for i, j := range data.SwapPorts {
+		ports[i] = data.ports[j]
+	}
+	args := []string{
+		"-mode", "client",
+		"-scheme", data.Scheme,
+		"-name", data.name,
+		"-port", strings.Join(ports, ","),
+		"-v", fmt.Sprint(data.Vote),
+	}
+	if data.DoSeed {
+		args = append(args, "-s", fmt.Sprint(data.Seed))
+	}
+	if !data.IgnoreResults {
+		args = append(args, "-w")
+	}
+	if data.P != 0 {
+		args = append(args, "-p", fmt.Sprint(data.P))
+	}
+	if data.Keys != "" {
+		args = append(args, "-keys", data.Keys)
+	}
+	if data.Authority != "" {
+		args = append(args, "-authority", data.Authority, "-aport", data.APort)
+	}
+	if data.ClientMode != "" {
+		args = append(args, "-cb", data.ClientMode)
+	}
+	/*if data.BadMode >= 0 {
+		args = append(args, "-b", fmt.Sprintf("%v", data.BadMode))
+	}*/
+	if _, e := TestUtil_SpawnTestProcess(args...); e != nil {
+		panic(fmt.Errorf("assert failed: Voter failed to spawn"))
+	}
+}
+
+func TestUtil_SpawnTestProcess(args ...string) (*exec.Cmd, error) {
+	fmt.Printf("[TestUtil] Spawning vote instance with args: %v\n", args)
+	proc := exec.Command("./voting", args...)
+	proc.Stdout = os.Stdout
+	proc.Stderr = os.Stderr
+	e := proc.Start()
+	if e == nil {
+		// Add to process list so we can ensure it is closed
+		db_spawnedProcceses = append(db_spawnedProcceses, proc.Process)
+	}
+	return proc, e
+}
+
+func RunTest42() bool {
+
+	// Log test
+	fmt.Println("--- Running test 42 ---")
+	fmt.Println("--- Simulated election where a voter recasts its ballot, and a voter tries to rejoin without its recast keys ---")
+	fmt.Println()
+
+	// C2 casts the other vote twice before rejoining to recast, C5 recasts as an impostor (refused, as it has no key)
+	return RunSimulation(SimElection{
+		Seed:      42,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{2: {Mode: voteclient.CLIENT_MODE_RECAST, Arg: 2}, 5: {Mode: voteclient.CLIENT_MODE_IMPOSTOR}},
+	})
+
+}
+
+func RunTest43() bool {
+
+	// Log test
+	fmt.Println("--- Running test 43 ---")
+	fmt.Println("--- Simulated election where a recast only reaches some servers ---")
+	fmt.Println()
+
+	// C3 recasts at S1 only and C6 at S1-S3, so every server must count their first ballots
+	return RunSimulation(SimElection{
+		Seed:      43,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{3: {Mode: voteclient.CLIENT_MODE_PART_RECAST}, 6: {Mode: voteclient.CLIENT_MODE_PART_RECAST, Arg: 3}},
+	})
+
+}
+
+func RunTest44() bool {
+
+	// Log test
+	fmt.Println("--- Running test 44 ---")
+	fmt.Println("--- Simulated threshold ElGamal election where voters recast their proven ballots ---")
+	fmt.Println()
+
+	// C1 and C4 rejoin the server they voted at to recast, which must count their last ballots
+	return RunSimulation(SimElection{
+		Seed:      44,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Scheme:    scheme.ElGamal{N: 3},
+		Proofs:    true,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{1: {Mode: voteclient.CLIENT_MODE_RECAST}, 4: {Mode: voteclient.CLIENT_MODE_RECAST}},
+	})
+
+}
+
+func RunTest45() bool {
+
+	// Log test
+	fmt.Println("--- Running test 45 ---")
+	fmt.Println("--- Receipts of shares and encrypted ballots ---")
+	fmt.Println()
+
+	// A signed receipt must hold, also after encoding it
+	pub, key, _ := ed25519.GenerateKey(rand.New(rand.NewSource(45)))
+	commitment := protocol.CommitShare(1234, 1, "salt")
+	receipt := protocol.Receipt{ServerID: 2, Seq: 1, Election: "e", Voter: "C1", Commitment: commitment, Time: "t"}.Sign(key)
+	if !receipt.Verify(pub) || !receipt.ToRequest().ToReceiptMsg().Verify(pub) || receipt.ToRequest().ToReceiptMsg() != receipt {
+		fmt.Printf("\033[31mThe receipt does not hold.\033[0m\n")
+		return false
+	}
+
+	// The receipt must not hold for another server, election, voter, commitment, time or key
+	for _, what := range []string{"server", "election", "voter", "commitment", "time", "key"} {
+		forged := receipt
+		switch what {
+		case "server":
+			forged.ServerID = 3
+		case "election":
+			forged.Election = "other"
+		case "voter":
+			forged.Voter = "C2"
+		case "commitment":
+			forged.Commitment = protocol.CommitShare(1235, 1, "salt")
+		case "time":
+			forged.Time = "u"
+		case "key":
+			_, other, _ := ed25519.GenerateKey(rand.New(rand.NewSource(46)))
+			forged.Key = protocol.Receipt{}.Sign(other).Key
+		}
+		if forged.Verify(pub) {
+			fmt.Printf("\033[31mThe receipt holds for another %s.\033[0m\n", what)
+			return false
+		}
+	}
+
+	// The commitment must bind the share, the sequence number and the salt
+	if protocol.CommitShare(1234, 2, "salt") == commitment || protocol.CommitShare(1234, 1, "pepper") == commitment || protocol.CommitBallot([]string{"a", "b"}, 1) == protocol.CommitBallot([]string{"a", "b"}, 2) {
+		fmt.Printf("\033[31mThe commitment does not bind the share.\033[0m\n")
+		return false
+	}
+	return true
+
+}
+
+func RunTest46() bool {
+
+	// Log test
+	fmt.Println("--- Running test 46 ---")
+	fmt.Println("--- Simulated election where server 2 withholds receipts and server 3 leaves them out of its tallied set ---")
+	fmt.Println()
+
+	// The tally is not touched, but every voter must hold both servers to account
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       46,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{2: {&tallyserver.NoReceiptBehaviour{}}, 3: {&tallyserver.HideReceiptBehaviour{}}},
+	})
+	if !outcome.Passed() || len(outcome.Verified) != 8 {
+		return false
+	}
+	for _, v := range outcome.Verified {
+		if len(v.Drops) != 2 || v.Drops[0] != 2 || v.Drops[1] != 3 {
+			fmt.Printf("\033[31m%s found %v dropped its ballot\033[0m\n", v.Name, v.Drops)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest47() bool {
+
+	// Log test
+	fmt.Println("--- Running test 47 ---")
+	fmt.Println("--- Simulated threshold ElGamal election where server 1 leaves receipts out of its tallied set ---")
+	fmt.Println()
+
+	// The voters at S1 must find it did not tally their ballot, while the voters at S2 and S3 find nothing
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       47,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Scheme:     scheme.ElGamal{N: 3},
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{1: {&tallyserver.HideReceiptBehaviour{}}},
+	})
+	if !outcome.Passed() || len(outcome.Verified) != 8 {
+		return false
+	}
+	caught := 0
+	for _, v := range outcome.Verified {
+		if len(v.Drops) > 1 || (len(v.Drops) == 1 && v.Drops[0] != 1) {
+			fmt.Printf("\033[31m%s found %v dropped its ballot\033[0m\n", v.Name, v.Drops)
+			return false
+		}
+		caught += len(v.Drops)
+	}
+	if caught == 0 {
+		fmt.Printf("\033[31mNo voter found S1 dropped its ballot.\033[0m\n")
+	}
+	return caught > 0
+
+}
+
+func RunTest48() bool {
+
+	// Log test
+	fmt.Println("--- Running test 48 ---")
+	fmt.Println("--- Simulated election where voters hang up after voting and server 2 forges the results it is asked for ---")
+	fmt.Println()
+
+	// The voters fetch the results from every server once tallied, and must verify the tally from the points (the
+	// forged results hold no receipts, so every voter must also find S2 did not tally its ballot)
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       48,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Detach:     true,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{2: {&tallyserver.ForgeTallyBehaviour{Yes: 100}}},
+	})
+	if !outcome.Passed() || len(outcome.Verified) != 8 {
+		return false
+	}
+	for _, v := range outcome.Verified {
+		if len(v.Drops) != 1 || v.Drops[0] != 2 {
+			fmt.Printf("\033[31m%s found %v dropped its ballot\033[0m\n", v.Name, v.Drops)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest49() bool {
+
+	// Log test
+	fmt.Println("--- Running test 49 ---")
+	fmt.Println("--- Simulated threshold ElGamal election where voters hang up after voting and server 1 forges the results ---")
+	fmt.Println()
+
+	// The decrypted tallies are cross-checked, so every voter must take the tally of S2 and S3, and catch S1 lying
+	// (the voters at S1 also find it did not tally their ballot, as the forged results hold no receipts)
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:       49,
+		Voters:     8,
+		VoteTime:   5,
+		P:          1997,
+		K:          1,
+		Scheme:     scheme.ElGamal{N: 3},
+		Detach:     true,
+		Links:      "*>*:delay=1ms-40ms",
+		Behaviours: map[int][]tallyserver.Behaviour{1: {&tallyserver.ForgeTallyBehaviour{Yes: 100}}},
+	})
+	if !outcome.Passed() || len(outcome.Verified) != 8 {
+		return false
+	}
+	for _, v := range outcome.Verified {
+		if len(v.Liars) != 1 || v.Liars[0] != 1 || len(v.Drops) > 1 || (len(v.Drops) == 1 && v.Drops[0] != 1) {
+			fmt.Printf("\033[31m%s caught %v lying, and found %v dropped its ballot\033[0m\n", v.Name, v.Liars, v.Drops)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest50() bool {
+
+	// Log test
+	fmt.Println("--- Running test 50 ---")
+	fmt.Println("--- Voters hanging up after voting, keeping their receipts in files and fetching the results later ---")
+	fmt.Println()
+
+	// Keep the receipts in a temporary folder
+	dir, e := os.MkdirTemp("", "voting-receipts")
+	if e != nil {
+		fmt.Println(e)
+		return false
+	}
+	defer os.RemoveAll(dir)
+
+	// Start the servers on a simulated network
+	rand.Seed(50)
+	network := protocol.NewSimNetwork(50)
+	clientPorts := []string{"10001", "10002", "10003", "10004"}
+	servers := make([]*tallyserver.Server, len(clientPorts))
+	for i := range servers {
+		partnerPorts := []string{"11001", "11002", "11003"}
+		if i == 0 {
+			partnerPorts = partnerPorts[:1]
+		}
+		servers[i] = tallyserver.New(
+			tallyserver.WithID(i+1, fmt.Sprintf("S%v", i+1)),
+			tallyserver.WithListen(SIM_IP, clientPorts[i]),
+			tallyserver.WithPartners([]string{SIM_IP}, partnerPorts),
+			tallyserver.WithVoteTime(4),
+			tallyserver.WithTransport(network.Endpoint(fmt.Sprintf("S%v", i+1))),
+			tallyserver.WithPhaseTimeout(SIM_PHASE_TIMEOUT),
+		)
+		if err := servers[i].Start(); err != nil {
+			fmt.Printf("S%v failed to start: %v\n", i+1, err)
+			return false
+		}
+		defer servers[i].Stop()
+	}
+	subscription := servers[0].Subscribe()
+	time.Sleep(500 * time.Millisecond)
+
+	// Asking before the tally must tell us to ask again later
+	early := voteclient.New("early", []string{SIM_IP}, clientPorts, 1997, 1)
+	early.Transport = network.Endpoint("early")
+	if early.FetchResults(); early.Code != protocol.ERR_TALLY_PENDING || early.Verified != nil {
+		fmt.Printf("\033[31mAsking before the tally gave %v (%+v)\033[0m\n", early.Code, early.Verified)
+		return false
+	}
+
+	// Cast 2 yes and 2 no votes, each voter hanging up once it has its receipts (and writing them to a file)
+	votes := []int{1, 0, 0, 1}
+	for i, vote := range votes {
+		voter := voteclient.New(fmt.Sprintf("C%v", i+1), []string{SIM_IP}, clientPorts, 1997, 1)
+		voter.Transport = network.Endpoint(voter.Id)
+		voter.RNG = sharing.Deterministic(int64(50 + i))
+		if voter.Init(voter.Id, []string{SIM_IP}, clientPorts, 1997, 1, false) != nil {
+			return false
+		}
+		voter.SendVote(vote)
+		voter.Shutdown(false)
+		if len(voter.Receipts) != len(clientPorts) || len(voter.Dropped) != 0 {
+			fmt.Printf("\033[31m%s hung up with %v receipt(s), dropped by %v\033[0m\n", voter.Id, len(voter.Receipts), voter.Dropped)
+			return false
+		}
+		if err := voter.SaveReceipts(filepath.Join(dir, voter.Id+".json")); err != nil {
+			fmt.Println(err)
+			return false
+		}
+	}
+
+	// The servers must tally without pushing the results to anyone
+	res := <-subscription
+	PrintResult(50, res)
+	if res.Yes != 2 || res.No != 2 || res.Error {
+		return false
+	}
+
+	// Every voter must fetch the verified tally once the servers tallied, and find its receipts in the tallied sets
+	for i := range votes {
+		voter := voteclient.New(fmt.Sprintf("C%v", i+1), []string{SIM_IP}, clientPorts, 1997, 1)
+		voter.Transport = network.Endpoint(voter.Id)
+		receipts, err := voteclient.LoadReceipts(filepath.Join(dir, voter.Id+".json"))
+		if err != nil {
+			fmt.Println(err)
+			return false
+		}
+		for deadline := time.Now().Add(20 * time.Second); voter.Verified == nil && time.Now().Before(deadline); {
+			voter.Receipts = receipts
+			if voter.FetchResults(); voter.Code == protocol.ERR_TALLY_PENDING {
+				time.Sleep(SIM_RESULT_INTERVAL)
+			}
+		}
+		if voter.Verified == nil || voter.Verified.Yes != 2 || voter.Verified.No != 2 || len(voter.Receipts) != len(clientPorts) || len(voter.Dropped) != 0 {
+			fmt.Printf("\033[31m%s fetched %+v (%v), with %v receipt(s) dropped by %v\033[0m\n", voter.Id, voter.Verified, voter.Code, len(voter.Receipts), voter.Dropped)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest51() bool {
+
+	// Log test
+	fmt.Println("--- Running test 51 ---")
+	fmt.Println("--- Simulated election of the silent scenario, where server 3 never passes on the client lists ---")
+	fmt.Println()
+
+	// Every client list is routed through S3, so the honest servers must trace the stalled lists to it, and redo the
+	// intersection without it (instead of going ahead without each other)
+	scenario, err := BuiltinScenario("silent.json")
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	election, err := scenario.Apply(SimElection{P: 1997, K: 1})
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	outcome := RunAndReportSimulation(election)
+	if !outcome.Passed() {
+		return false
+	}
+	for i, r := range outcome.Results {
+		if outcome.Honest[i] && r.Error {
+			fmt.Printf("\033[31mServer %v did not tally: %v\033[0m\n", i+1, r.Code)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest52() bool {
+
+	// Log test
+	fmt.Println("--- Running test 52 ---")
+	fmt.Println("--- Simulated election of the replay scenario, where every message of server 2 to its partners arrives twice ---")
+	fmt.Println()
+
+	// The repeated joins and R-sums of S2 must be ignored, so every honest server keeps its link to S2 and tallies
+	// with a single R-sum of every server
+	scenario, err := BuiltinScenario("replay.json")
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	election, err := scenario.Apply(SimElection{P: 1997, K: 1})
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	outcome := RunAndReportSimulation(election)
+	if !outcome.Passed() {
+		return false
+	}
+	for i, r := range outcome.Results {
+		if outcome.Honest[i] && (r.Error || len(r.Points) != election.SchemeOrDefault().Servers()) {
+			fmt.Printf("\033[31mServer %v tallied with %v R-sum(s): %v\033[0m\n", i+1, len(r.Points), r.Code)
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest53() bool {
+
+	// Log test
+	fmt.Println("--- Running test 53 ---")
+	fmt.Println("--- Simulated threshold ElGamal election where server 1 signs a different aggregate for server 2 ---")
+	fmt.Println()
+
+	// The echoed aggregates show S1 signed two, so the honest servers must fail the tally blaming only S1 (and none
+	// of them for its partial decryption)
+	scenario, err := BuiltinScenario("elgamal-split-aggregate.json")
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	election, err := scenario.Apply(SimElection{P: 1997, K: 1})
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	outcome := RunAndReportSimulation(election)
+	if outcome.Hung || len(outcome.Results) != 3 {
+		return false
+	}
+	for i := 1; i < 3; i++ {
+		if r := outcome.Results[i]; !r.Error || r.Code != protocol.ERR_AGGREGATE_CONFLICT {
+			fmt.Printf("\033[31mS%v ended with %+v\033[0m\n", i+1, r)
+			return false
+		}
+		if len(outcome.Blames[i]) == 0 {
+			fmt.Printf("\033[31mS%v blamed nobody\033[0m\n", i+1)
+			return false
+		}
+		for _, report := range outcome.Blames[i] {
+			if report.Blamed != 1 || report.Method != protocol.BLAME_AGGREGATE {
+				fmt.Printf("\033[31mS%v blamed %+v\033[0m\n", i+1, report)
+				return false
+			}
+		}
+	}
+	return true
+
+}
+
+func RunTest54() bool {
+
+	// Log test
+	fmt.Println("--- Running test 54 ---")
+	fmt.Println("--- Receipts forged with a fresh key are rejected against the published keys ---")
+	fmt.Println()
+
+	// Publish the receipt keys of the servers (the voters pin the public ones)
+	dir, e := os.MkdirTemp("", "voting-receipt-keys")
+	if e != nil {
+		fmt.Println(e)
+		return false
+	}
+	defer os.RemoveAll(dir)
+	clientPorts := []string{"10001", "10002", "10003", "10004"}
+	keys, e := protocol.GenerateReceiptKeys(rand.New(rand.NewSource(54)), len(clientPorts))
+	if e == nil {
+		e = protocol.SaveReceiptKeys(dir, keys)
+	}
+	if e != nil {
+		fmt.Println(e)
+		return false
+	}
+	pinned, e := protocol.LoadReceiptPublicKeys(dir, len(clientPorts))
+	if e != nil {
+		fmt.Println(e)
+		return false
+	}
+
+	// A receipt re-signed with a fresh key must not hold against the pinned key, though it holds against its own
+	_, fresh, _ := ed25519.GenerateKey(rand.New(rand.NewSource(55)))
+	receipt := protocol.Receipt{ServerID: 2, Seq: 1, Election: "e", Voter: "C1", Commitment: protocol.CommitShare(1234, 1, "salt"), Time: "t"}
+	forged := receipt.Sign(fresh)
+	if !receipt.Sign(keys[1]).Verify(pinned[1]) || forged.Verify(pinned[1]) || !forged.Verify(fresh.Public().(ed25519.PublicKey)) {
+		fmt.Printf("\033[31mThe forged receipt is not told from the one of the server.\033[0m\n")
+		return false
+	}
+
+	// Start the servers on a simulated network, each signing with its published key
+	rand.Seed(54)
+	network := protocol.NewSimNetwork(54)
+	servers := make([]*tallyserver.Server, len(clientPorts))
+	for i := range servers {
+		partnerPorts := []string{"11001", "11002", "11003"}
+		if i == 0 {
+			partnerPorts = partnerPorts[:1]
+		}
+		key, e := protocol.LoadReceiptKey(dir, i+1)
+		if e != nil {
+			fmt.Println(e)
+			return false
+		}
+		servers[i] = tallyserver.New(
+			tallyserver.WithID(i+1, fmt.Sprintf("S%v", i+1)),
+			tallyserver.WithListen(SIM_IP, clientPorts[i]),
+			tallyserver.WithPartners([]string{SIM_IP}, partnerPorts),
+			tallyserver.WithVoteTime(4),
+			tallyserver.WithReceiptKey(key),
+			tallyserver.WithTransport(network.Endpoint(fmt.Sprintf("S%v", i+1))),
+			tallyserver.WithPhaseTimeout(SIM_PHASE_TIMEOUT),
+		)
+		if err := servers[i].Start(); err != nil {
+			fmt.Printf("S%v failed to start: %v\n", i+1, err)
+			return false
+		}
+		defer servers[i].Stop()
+	}
+	subscription := servers[0].Subscribe()
+	time.Sleep(500 * time.Millisecond)
+
+	// Cast a yes vote, hanging up once we have the receipts
+	voter := voteclient.New("C1", []string{SIM_IP}, clientPorts, 1997, 1)
+	voter.Transport = network.Endpoint(voter.Id)
+	voter.RNG = sharing.Deterministic(54)
+	voter.ReceiptKeys = pinned
+	if voter.Init(voter.Id, []string{SIM_IP}, clientPorts, 1997, 1, false) != nil {
+		return false
+	}
+	voter.SendVote(1)
+	voter.Shutdown(false)
+	if len(voter.Receipts) != len(clientPorts) || len(voter.Dropped) != 0 {
+		fmt.Printf("\033[31m%s hung up with %v receipt(s), dropped by %v\033[0m\n", voter.Id, len(voter.Receipts), voter.Dropped)
+		return false
+	}
+	res := <-subscription
+	PrintResult(54, res)
+	if res.Yes != 1 || res.No != 0 || res.Error {
+		return false
+	}
+
+	// A receipt forged with a fresh key, for a ballot S2 never took, must not hold S2 to account
+	receipts := append([]protocol.Receipt{}, voter.Receipts...)
+	for _, r := range voter.Receipts {
+		if r.ServerID == 2 {
+			r.Seq++
+			r.Commitment = protocol.CommitShare(0, r.Seq, "salt")
+			receipts = append(receipts, r.Sign(fresh))
+		}
+	}
+	fetcher := voteclient.New("C1", []string{SIM_IP}, clientPorts, 1997, 1)
+	fetcher.Transport = network.Endpoint("C1-fetch")
+	fetcher.ReceiptKeys = pinned
+	for deadline := time.Now().Add(20 * time.Second); fetcher.Verified == nil && time.Now().Before(deadline); {
+		fetcher.Receipts = receipts
+		if fetcher.FetchResults(); fetcher.Code == protocol.ERR_TALLY_PENDING {
+			time.Sleep(SIM_RESULT_INTERVAL)
+		}
+	}
+	if fetcher.Verified == nil || fetcher.Verified.Yes != 1 || len(fetcher.Receipts) != len(clientPorts) || len(fetcher.Dropped) != 0 {
+		fmt.Printf("\033[31mThe forged receipt was held against S2: fetched %+v (%v), with %v receipt(s) dropped by %v\033[0m\n", fetcher.Verified, fetcher.Code, len(fetcher.Receipts), fetcher.Dropped)
+		return false
+	}
+	return true
+
+}
+
+func RunTest55() bool {
+
+	// Log test
+	fmt.Println("--- Running test 55 ---")
+	fmt.Println("--- Private set intersection of the client lists of three servers ---")
+	fmt.Println()
+	passed := true
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			fmt.Printf("\033[31m"+format+"\033[0m\n", args...)
+			passed = false
+		}
+	}
+
+	// Three servers with their own voters (C3 and C4 voted at all of them)
+	lists := [][]string{{"C1", "C2", "C3", "C4"}, {"C2", "C3", "C4", "C5"}, {"C3", "C4", "C6"}}
+	keys := make([]*tallyserver.PSIKey, len(lists))
+	for i := range keys {
+		var err error
+		if keys[i], err = tallyserver.NewPSIKey(); err != nil {
+			fmt.Println(err)
+			return false
+		}
+	}
+
+	// Every list is blinded by its server, then passed through the others (encoded as it is sent), and what a
+	// server is asked to blind must not match any voter it knows, hashed or blinded with its own key
+	full := make([]protocol.StringHashSet, len(lists))
+	own := make([][]string, len(lists))
+	for origin, list := range lists {
+		values := make([]*big.Int, len(list))
+		for i, id := range list {
+			values[i] = tallyserver.PSIHash(id)
+		}
+		values = keys[origin].Blind(values)
+		for hop := 1; hop < len(lists); hop++ {
+			j := (origin + hop) % len(lists)
+			decoded, err := tallyserver.DecodePSI(tallyserver.EncodePSI(values))
+			check(err == nil, "the blinded list of %v did not decode: %v", origin+1, err)
+			seen := protocol.CheckmapFromStringSlice(tallyserver.EncodePSI(decoded))
+			for _, id := range lists[j] {
+				hashed := []*big.Int{tallyserver.PSIHash(id)}
+				for _, v := range [][]*big.Int{hashed, keys[j].Blind(hashed)} {
+					_, exists := seen[tallyserver.EncodePSI(v)[0]]
+					check(!exists, "server %v linked %s on the list of %v", j+1, id, origin+1)
+				}
+			}
+			values = keys[j].Blind(decoded)
+		}
+		own[origin] = tallyserver.EncodePSI(values)
+		full[origin] = protocol.CheckmapFromStringSlice(own[origin])
+	}
+
+	// Every server must find the same intersection from the fully blinded lists
+	for i, list := range lists {
+		common := make([]string, 0)
+		for n, v := range own[i] {
+			inAll := true
+			for j := range lists {
+				if _, exists := full[j][v]; !exists {
+					inAll = false
+				}
+			}
+			if inAll {
+				common = append(common, list[n])
+			}
+		}
+		check(fmt.Sprint(common) == "[C3 C4]", "server %v found the intersection %v", i+1, common)
+	}
+
+	// What leaks beyond the intersection: the size of every list, and which of its own voters are on each list
+	// (server 1 finds C2 voted at server 2 too, though C2 is not in the intersection)
+	for j := range lists {
+		matched := make([]string, 0)
+		for n, v := range own[0] {
+			if _, exists := full[j][v]; exists {
+				matched = append(matched, lists[0][n])
+			}
+		}
+		check(len(full[j]) == len(lists[j]), "server 1 did not learn the size of the list of %v", j+1)
+		if j == 1 {
+			check(fmt.Sprint(matched) == "[C2 C3 C4]", "server 1 found %v of its voters on the list of 2", matched)
+		}
+	}
+
+	// Values outside the group must be refused
+	for _, s := range []string{"0", "1", "xyz", strings.Repeat("f", 600)} {
+		_, err := tallyserver.DecodePSI([]string{s})
+		check(err != nil, "the value %s was taken as a blinded voter", s)
+	}
+	return passed
+
+}
+
+func RunTest56() bool {
+
+	// Log test
+	fmt.Println("--- Running test 56 ---")
+	fmt.Println("--- Failing runs exit with the code of the failure ---")
+	fmt.Println()
+
+	// Each run fails on its own (no servers are started), and must exit with 10 + the code of why
+	runs := []struct {
+		args []string
+		code protocol.ErrorCode
+	}{
+		{[]string{"-mode", "client", "-name", "C1", "-port", "19991"}, protocol.ERR_SERVER_CONFIG},
+		{[]string{"-mode", "client", "-name", "C1", "-scheme", "shamir", "-port", "19991,19992"}, protocol.ERR_SERVER_CONFIG},
+		{[]string{"-mode", "client", "-name", "C1", "-scheme", "shamir", "-port", "19991,19992,19993"}, protocol.ERR_SERVER_UNREACHABLE},
+		{[]string{"-mode", "client", "-name", "C1", "-scheme", "additive", "-port", "19991,19992,19993"}, protocol.ERR_SERVER_CONFIG},
+		{[]string{"-mode", "client", "-name", "C1", "-scheme", "additive", "-port", "19991,19992"}, protocol.ERR_SERVER_UNREACHABLE},
+		{[]string{"-mode", "result", "-name", "C1", "-scheme", "elgamal", "-port", "19991,19992,19993"}, protocol.ERR_NO_TALLY},
+		{[]string{"-mode", "result", "-name", "C1", "-port", "19991,19992,19993,19994"}, protocol.ERR_TALLY_UNVERIFIED},
+	}
+	passed := true
+	for _, run := range runs {
+		ctx, stop := context.WithTimeout(context.Background(), 60*time.Second)
+		fmt.Printf("[TestUtil] Running vote instance with args: %v\n", run.args)
+		proc := exec.CommandContext(ctx, "./voting", run.args...)
+		proc.Stdout = os.Stdout
+		proc.Stderr = os.Stderr
+		err := proc.Run()
+		stop()
+		exit := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exit = exitErr.ExitCode()
+		} else if err != nil {
+			fmt.Println(err)
+			return false
+		}
+		if exit != 10+int(run.code) || exit != run.code.ExitCode() {
+			fmt.Printf("\033[31m%v exited with %v, not %v (%v)\033[0m\n", run.args, exit, 10+int(run.code), run.code)
+			passed = false
+		}
+	}
+	return passed
+
+}
+
+func RunTest57() bool {
+
+	// Log test
+	fmt.Println("--- Running test 57 ---")
+	fmt.Println("--- A closed TCP connection gives io.EOF at both ends ---")
+	fmt.Println()
+
+	// Connect over a real TCP socket
+	listener, err := protocol.TCPTransport{}.Listen("127.0.0.1", "0")
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	defer listener.Close()
+	ip, port, _ := net.SplitHostPort(listener.Addr())
+	accepted := make(chan protocol.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Println(err)
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+	local, err := protocol.TCPTransport{}.Dial(ip, port)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	remote, ok := <-accepted
+	if !ok {
+		return false
+	}
+	defer remote.Close()
+
+	// Receive on a connection, giving up after a while (a receive must not block once the connection is closed)
+	receive := func(conn protocol.Conn) (protocol.Request, error) {
+		type received struct {
+			req protocol.Request
+			err error
+		}
+		done := make(chan received, 1)
+		go func() {
+			req, err := conn.Receive()
+			done <- received{req, err}
+		}()
+		select {
+		case r := <-done:
+			return r.req, r.err
+		case <-time.After(5 * time.Second):
+			return protocol.Request{}, fmt.Errorf("timed out")
+		}
+	}
+
+	// A request must go through, then closing our end must give io.EOF at our end (every time) and at theirs
+	if err := local.Send(protocol.Request{RequestType: protocol.RESULT}); err != nil {
+		fmt.Println(err)
+		return false
+	}
+	if req, err := receive(remote); err != nil || req.RequestType != protocol.RESULT {
+		fmt.Printf("\033[31mThe request did not go through: %v\033[0m\n", err)
+		return false
+	}
+	pending := make(chan error, 1)
+	go func() {
+		_, err := receive(local)
+		pending <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	local.Close()
+	passed := true
+	if err := <-pending; err != io.EOF {
+		fmt.Printf("\033[31mA receive blocked when we closed the connection gave %v\033[0m\n", err)
+		passed = false
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := receive(local); err != io.EOF {
+			fmt.Printf("\033[31mReceiving on the closed connection gave %v\033[0m\n", err)
+			passed = false
+		}
+	}
+	if _, err := receive(remote); err != io.EOF {
+		fmt.Printf("\033[31mReceiving after the other end closed gave %v\033[0m\n", err)
+		passed = false
+	}
+	return passed
+
+}
+
+func RunTest58() bool {
+
+	// Log test
+	fmt.Println("--- Running test 58 ---")
+	fmt.Println("--- Simulated MAC checked election where a voter sends the two servers different masked votes ---")
+	fmt.Println()
+
+	// C3 sends S2 another masked vote than S1, which would fail the MAC check if the servers tallied it
+	outcome := RunAndReportSimulation(SimElection{
+		Seed:      58,
+		Voters:    8,
+		VoteTime:  5,
+		P:         1997,
+		K:         1,
+		Scheme:    scheme.Additive{},
+		MAC:       true,
+		Links:     "*>*:delay=1ms-40ms",
+		BadVoters: map[int]VoterMode{3: {Mode: voteclient.CLIENT_MODE_INCONSISTENT}},
+	})
+
+	// Both servers must leave C3 out, pass the MAC check and blame nobody
+	if outcome.Hung {
+		return false
+	}
+	for i, r := range outcome.Results {
+		if r.Error || r.Yes != outcome.Expected.Yes || r.No != outcome.Expected.No {
+			fmt.Printf("\033[31mS%v tallied %+v, expected %+v\033[0m\n", i+1, r, outcome.Expected)
+			return false
+		}
+		if len(outcome.Blames[i]) != 0 {
+			fmt.Printf("\033[31mS%v blamed %v for the masked votes of a voter\033[0m\n", i+1, outcome.Blames[i])
+			return false
+		}
+	}
+	return true
+
+}
+
+func RunTest59() bool {
+
+	// Log test
+	fmt.Println("--- Running test 59 ---")
+	fmt.Println("--- Berlekamp-Welch correcting several lying servers, for other degrees and server counts ---")
+	fmt.Println()
+
+	// The R-sums of f(x) = 5 + 3x + 2x^2 (seven servers, k = 2) and of g(x) = 5 + 3x (six servers, k = 1)
+	p := 1997
+	f := sharing.NewPolynomial(p, 5, 3, 2)
+	g := sharing.NewPolynomial(p, 5, 3)
+	sums := func(poly sharing.Polynomial, n int, lies map[int]int) []sharing.Point {
+		points := make([]sharing.Point, n)
+		for i := range points {
+			points[i] = sharing.Point{X: i + 1, Y: poly.Eval(i + 1)}
+			if y, lying := lies[i+1]; lying {
+				points[i].Y = y
+			}
+		}
+		return points
+	}
+
+	// What the decoder must make of the points (the X of the blamed points in order, negated if outside the field)
+	cases := []struct {
+		name   string
+		points []sharing.Point
+		k      int
+		code   protocol.ErrorCode
+		blamed []int
+	}{
+		{"two liars among seven (k=2)", sums(f, 7, map[int]int{2: 100, 5: 7}), 2, protocol.ERR_NONE, []int{2, 5}},
+		{"the point p (outside the field) and a liar among seven (k=2)", sums(f, 7, map[int]int{3: p, 6: 1}), 2, protocol.ERR_NONE, []int{-3, 6}},
+		{"two liars among six (k=1)", sums(g, 6, map[int]int{1: 42, 4: 43}), 1, protocol.ERR_NONE, []int{1, 4}},
+		{"three liars among seven (k=2)", sums(f, 7, map[int]int{1: 11, 2: 20, 3: 30}), 2, protocol.ERR_CORRECTION_FAILED, nil},
+		{"three points outside the field among seven (k=2)", sums(f, 7, map[int]int{1: -1, 2: 2000, 3: 3000}), 2, protocol.ERR_POINTS_OUTSIDE_FIELD, nil},
+	}
+
+	// Decode every case
+	strategy, _ := tally.ByName(tally.BERLEKAMP_WELCH)
+	passed := true
+	for _, c := range cases {
+		result, diag := strategy.Tally(c.points, tally.Params{Name: "T59", ServerID: 1, P: p, K: c.k, Servers: len(c.points)})
+		blamed := make([]int, 0)
+		for _, b := range result.Blames {
+			if b.Method == protocol.BLAME_OUTSIDE_FIELD {
+				blamed = append(blamed, -b.Point.X)
+			} else {
+				blamed = append(blamed, b.Point.X)
+			}
+		}
+		ok := result.Code == c.code && (c.code != protocol.ERR_NONE || result.Yes == 5) && fmt.Sprint(blamed) == fmt.Sprint(c.blamed)
+		if !ok {
+			passed = false
+			fmt.Printf("\033[31m%s gave %+v, expected code %v blaming %v\033[0m\n", c.name, result, c.code, c.blamed)
+		}
+		fmt.Printf("\033[33m@@@ TEST 59: %s: %v\033[0m\n", c.name, diag)
+	}
+	return passed
+
+}
+
+func RunTest60() bool {
+
+	// Log test
+	fmt.Println("--- Running test 60 ---")
+	fmt.Println("--- A stopped TCP server lets go of the connections of its voters ---")
+	fmt.Println()
+
+	// Start a server on a real TCP socket (its partners never come)
+	server := tallyserver.New(
+		tallyserver.WithID(1, "S1"),
+		tallyserver.WithListen("127.0.0.1", "0"),
+		tallyserver.WithPartners([]string{"127.0.0.1"}, []string{"0"}),
+		tallyserver.WithVoteTime(30),
+		tallyserver.WithPhaseTimeout(30*time.Second),
+	)
+	if err := server.Start(); err != nil {
+		fmt.Println(err)
+		return false
+	}
+	ip, port, _ := net.SplitHostPort(server.ClientListener.Addr())
+
+	// Count the goroutines handling a voter connection
+	handlers := func() int {
+		stacks := make([]byte, 1<<20)
+		stacks = stacks[:runtime.Stack(stacks, true)]
+		return strings.Count(string(stacks), "(*Server).HandleVoterConnection")
+	}
+
+	// Join three voters, which the server keeps until it stops
+	conns := make([]protocol.Conn, 0)
+	for i := 1; i <= 3; i++ {
+		conn, _, err := voteclient.ConnectServer(protocol.DefaultTransport, protocol.ClientJoinMessage{ID: fmt.Sprintf("C%v", i)}, ip, port)
+		if err != nil {
+			fmt.Println(err)
+			server.Stop()
+			return false
+		}
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+	time.Sleep(200 * time.Millisecond)
+	joined := handlers()
+
+	// Stopping must end the handlers (a closed connection gives io.EOF, which ends them), and close our ends
+	server.Stop()
+	time.Sleep(time.Second)
+	left := handlers()
+	fmt.Printf("\033[33m@@@ TEST 60: %v voter handler(s) before stopping, %v after\033[0m\n", joined, left)
+	passed := joined == len(conns) && left == 0
+	for _, conn := range conns {
+		if _, err := conn.Receive(); err != io.EOF {
+			fmt.Printf("\033[31mA voter got %v from the stopped server, not io.EOF\033[0m\n", err)
+			passed = false
+		}
+	}
+	return passed
+
+}
@@ -0,0 +1,115 @@
+package voteclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// Create a client voting at the given servers (one IP is used for all servers).
+// Nothing is sent before the ballot is cast.
+func New(id string, servers, ports []string, P, K int) *Client {
+	return &Client{Id: id, P: P, K: K, serverIPs: servers, serverPorts: ports, Transport: protocol.DefaultTransport}
+}
+
+// Cast the ballot (0 or 1) and wait for the tally, verified from the points published by the servers.
+// If the context is done first, every server connection is closed and the error of the context is returned.
+func (client *Client) Cast(ctx context.Context, ballot int) (protocol.Results, error) {
+
+	// Only yes or no can be cast
+	if ballot < 0 || ballot > 1 {
+		return protocol.Results{}, protocol.Errorf(protocol.ERR_VOTE_OUT_OF_RANGE, "ballot %v is not 0 or 1", ballot)
+	}
+
+	// Vote as a copy of the client dialing through a transport we can cut off (so a voter left behind by the
+	// context changes nothing of ours, and the transport is not wrapped again on every cast)
+	voter := *client
+	if voter.Transport == nil {
+		voter.Transport = protocol.DefaultTransport
+	}
+	transport := &ctxTransport{Transport: voter.Transport, ctx: ctx}
+	voter.Transport = transport
+
+	// Vote in the background
+	done := make(chan error, 1)
+	go func() {
+
+		// A crashing client is returned as the error instead
+		defer func() {
+			if e := recover(); e != nil {
+				if err, ok := e.(error); ok {
+					done <- err
+				} else {
+					done <- fmt.Errorf("%v", e)
+				}
+			}
+		}()
+
+		// Connect, vote and wait for the tally
+		if err := voter.Init(voter.Id, voter.serverIPs, voter.serverPorts, voter.P, voter.K, false); err != nil {
+			done <- err
+			return
+		}
+		voter.SendVote(ballot)
+		voter.Shutdown(true)
+		if voter.Verified == nil {
+			done <- protocol.Errorf(voter.Code, "no verified tally")
+			return
+		}
+		done <- nil
+
+	}()
+
+	// Wait for the tally (or give up)
+	select {
+	case err := <-done:
+		if ctx.Err() != nil {
+			return protocol.Results{}, ctx.Err()
+		}
+		// Keep what the voter learned (under our own transport)
+		voter.Transport = transport.Transport
+		*client = voter
+		if err != nil {
+			return protocol.Results{}, err
+		}
+		return *client.Verified, nil
+	case <-ctx.Done():
+		transport.closeAll()
+		return protocol.Results{}, ctx.Err()
+	}
+
+}
+
+// Transport closing every connection it dialed once the context is done
+type ctxTransport struct {
+	protocol.Transport
+	ctx   context.Context
+	mutex sync.Mutex
+	conns []protocol.Conn
+}
+
+func (t *ctxTransport) Dial(ip, port string) (protocol.Conn, error) {
+	conn, err := t.Transport.Dial(ip, port)
+	if err != nil {
+		return nil, err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if err := t.ctx.Err(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	t.conns = append(t.conns, conn)
+	return conn, nil
+}
+
+// Close the connections dialed so far (later dials fail)
+func (t *ctxTransport) closeAll() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, conn := range t.conns {
+		conn.Close()
+	}
+}
@@ -0,0 +1,574 @@
+package voteclient
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+	"cs.au.dk/voting/sharing"
+	"cs.au.dk/voting/spdz"
+	"cs.au.dk/voting/tally"
+)
+
+type Client struct {
+
+	// Client data
+	P  int
+	Id string
+	K  int
+
+	// Server connections
+	Servers []protocol.Conn
+
+	// The secret sharing scheme of the election (decides the amount of servers)
+	Scheme   scheme.Scheme
+	Strategy tally.Strategy
+
+	// Our mask from the dealer if the tally is MAC checked (nil if not)
+	Mask *spdz.VoterKey
+
+	// Our credential from the registration authority, shown to the servers instead of our ID (nil if not)
+	Credential *credential.Token
+
+	// Randomness of the shares (sharing.Secure if nil, a deterministic source only in test mode and the simulator)
+	RNG sharing.RNG
+
+	// How the client reaches the servers
+	Transport protocol.Transport
+
+	// Server addresses (kept for misbehaving clients reconnecting)
+	serverIPs   []string
+	serverPorts []string
+
+	// Misbehaviour of a bad client (CLIENT_MODE_HONEST for an honest client)
+	Mode    string
+	ModeArg int
+
+	// Why the vote failed for the client (ERR_NONE if it did not)
+	Code protocol.ErrorCode
+
+	// Tally verified from the published points (nil until verified), and the ServerIDs of servers caught lying
+	Verified *protocol.Results
+	Liars    []int
+
+	// Index of the server we try first when the ballots are encrypted (we vote at a single server), whether we prove
+	// our ballot encrypts 0 or 1, and the tally if it came before we could vote
+	Entry int
+	Prove bool
+	early *protocol.Results
+
+	// Sequence number of our last ballot (the servers count the last one), the secret our recast keys are made from
+	// (so only we can rejoin the servers under our ID), and the election key once we got it (if encrypted)
+	seq       int
+	recast    []byte
+	recasting bool
+	key       *big.Int
+
+	// ID of the election (protocol.DEFAULT_ELECTION if not set), the receipts the servers gave us for our ballots,
+	// and the ServerIDs of the servers that took our ballot without a receipt, or left it out of the tally
+	Election string
+	Receipts []protocol.Receipt
+	Dropped  []int
+
+	// The published keys the servers sign receipts with (by ServerID - 1), receipts are only held against these
+	ReceiptKeys []ed25519.PublicKey
+
+	// Commitments to the ballots we sent each server since we joined it (by sequence number)
+	sent []map[int]string
+}
+
+// Connect to the servers under the ID (one IP is used for all servers if only one is given). The error says why we
+// could not join enough servers (its code is also left in Code).
+func (client *Client) Init(id string, servers, ports []string, P, K int, bad bool) error {
+
+	// Grab len (and the amount of servers the scheme shares among)
+	if client.Scheme == nil {
+		client.Scheme = scheme.Default()
+	}
+	if client.Strategy == nil {
+		client.Strategy, _ = scheme.StrategyOf(client.Scheme, "")
+	}
+	serverCount := len(servers)
+	expected := client.Scheme.Servers()
+
+	// If serverCount = 1, copy (Assumption is the IP is the same for all servers)
+	if serverCount == 1 {
+		for len(servers) < expected {
+			servers = append(servers, servers[0])
+		}
+		serverCount = len(servers)
+	}
+
+	// If server count is not as expected, give up
+	if serverCount != expected {
+		return client.failInit(id, bad, protocol.Errorf(protocol.ERR_SERVER_CONFIG, "expected %v servers IPs but were given %v", expected, serverCount))
+	}
+
+	// Verify port count (must be separate)
+	if len(ports) != expected {
+		return client.failInit(id, bad, protocol.Errorf(protocol.ERR_SERVER_CONFIG, "expected %v servers ports but were given %v", expected, len(ports)))
+	}
+
+	// Set identifier
+	client.Id = id
+	client.P = P
+	client.K = K
+	client.serverIPs = servers
+	client.serverPorts = ports
+
+	// Make arrays
+	client.Servers = make([]protocol.Conn, serverCount)
+	client.sent = make([]map[int]string, serverCount)
+	if client.Election == "" {
+		client.Election = protocol.DEFAULT_ELECTION
+	}
+	if client.Transport == nil {
+		client.Transport = protocol.DefaultTransport
+	}
+
+	// Encrypted ballots are cast at a single server
+	if scheme.Encrypts(client.Scheme) {
+		return client.initEncrypted(bad)
+	}
+
+	// Connect to the servers (going ahead without the ones that are offline)
+	roles := make([]int, 0)
+	cons := make([]protocol.Conn, 0)
+	for k := range servers {
+		conn, role, err := ConnectServer(client.Transport, client.joinMessage(servers[k], ports[k]), servers[k], ports[k])
+		if err != nil {
+			fmt.Printf("[%s] \033[33mServer at %s:%s is offline: %v\033[0m\n", id, servers[k], ports[k], err)
+			continue
+		}
+		roles = append(roles, role)
+		cons = append(cons, conn)
+	}
+
+	// Cannot complete protocol when too few parties are available
+	if len(cons) < client.Scheme.MinServers() {
+		return client.failInit(id, bad, protocol.Errorf(protocol.ERR_SERVER_UNREACHABLE, "only %v of %v servers are online", len(cons), serverCount))
+	}
+
+	// Assign
+	allServers := true
+	for role := range client.Servers {
+		allServers = client.AssignServerRole(role, roles, cons) && allServers
+	}
+
+	// Log
+	fmt.Printf("[%s] All servers connected: %v\n", client.Id, allServers)
+
+	// Enough servers must have reported their role
+	if client.OnlineServers() < sharing.MIN_SERVERS {
+		fmt.Printf("[%s] Only %v server(s) reported the expected roles.\n", client.Id, client.OnlineServers())
+		client.Code = protocol.ERR_SERVER_ROLES
+		return protocol.Errorf(protocol.ERR_SERVER_ROLES, "only %v server(s) reported the expected roles", client.OnlineServers())
+	}
+	return nil
+
+}
+
+// Log why we could not join the servers, keeping its code
+func (client *Client) failInit(id string, bad bool, err *protocol.VoteError) error {
+	client.Code = err.Code
+	if bad {
+		fmt.Printf("[%s] Bad client failed connection (%v) and silently shutting off...", id, err)
+	} else {
+		fmt.Printf("[%s] \033[31mCould not join the servers: %v\033[0m\n", id, err)
+	}
+	return err
+}
+
+func (client *Client) AssignServerRole(role int, roles []int, connections []protocol.Conn) bool {
+
+	for k, v := range roles {
+		if v == role+1 {
+			client.Servers[role] = connections[k]
+			return true
+		}
+	}
+
+	// Log failure and return false
+	fmt.Printf("[%s] \033[33mServer %v is offline - Reported roles were: %v.\033[0m\n", client.Id, role+1, roles)
+	return false
+
+}
+
+// Count the servers we are connected to
+func (client *Client) OnlineServers() int {
+	online := 0
+	for _, s := range client.Servers {
+		if s != nil {
+			online++
+		}
+	}
+	return online
+}
+
+// The join message we send the server at the address (showing our credential instead of our ID, if we have one)
+func (client *Client) joinMessage(ip, port string) protocol.ClientJoinMessage {
+	join := protocol.ClientJoinMessage{ID: client.voterKey(), Recast: client.recastKey(ip, port)}
+	if client.Credential != nil {
+		join.Credential = client.Credential.Encode()
+	}
+	return join
+}
+
+// The key we show the server at the address to rejoin it (every server gets its own, so no server can rejoin
+// another server as us)
+func (client *Client) recastKey(ip, port string) string {
+	if client.recast == nil {
+		client.recast = make([]byte, 16)
+		if _, e := rand.Read(client.recast); e != nil {
+			panic(e)
+		}
+	}
+	h := sha256.New()
+	h.Write(client.recast)
+	h.Write([]byte(ip + ":" + port))
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+func ConnectServer(transport protocol.Transport, join protocol.ClientJoinMessage, ip, port string) (protocol.Conn, int, error) {
+
+	// Connect using the transport, over specified address on specified port
+	id := join.ID
+	conn, err := transport.Dial(ip, port)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Send client join
+	e := conn.Send(join.ToRequest())
+	if e != nil {
+		fmt.Printf("[%s] Error when sending join message: %e", id, e)
+	}
+
+	responseRequest, e := conn.Receive()
+	if e != nil {
+		fmt.Printf("[%s] Error when receiving join response: %e", id, e)
+	}
+
+	if responseRequest.RequestType == protocol.REJECT {
+		reject := responseRequest.ToRejectMsg()
+		conn.Close()
+		return nil, 0, protocol.Errorf(reject.Code, "%s:%s refused us: %s", ip, port, reject.Reason)
+	}
+
+	if responseRequest.RequestType != protocol.ID {
+		fmt.Printf("[%s] Failure when receiving join response - invalid response type.", id)
+		return nil, 0, protocol.Errorf(protocol.ERR_SERVER_UNREACHABLE, "%s:%s sent an invalid join response", ip, port)
+	}
+
+	// Return base case -> nil, nil
+	return conn, responseRequest.Val1, nil
+
+}
+
+func (client *Client) SendVote(vote int) {
+
+	// Cast other ballots first, and recast the vote after them (if bad)
+	client.CastBefore(vote)
+	client.seq++
+
+	// Encrypt the vote instead, if the scheme says so
+	if scheme.Encrypts(client.Scheme) {
+		client.sendBallot(vote)
+		return
+	}
+
+	// Misbehave before voting (if bad)
+	client.BeforeVote()
+
+	// Get shares
+	shares := client.MakeShares(vote)
+
+	// Log
+	fmt.Printf("[%s] My secret is %v, with shares R1..R%v = %v\n", client.Id, vote, len(shares), shares)
+
+	// Loop over
+	for k, v := range shares {
+
+		// Skip servers we withhold our share from (if bad)
+		if !client.SendsShareTo(k) {
+			continue
+		}
+
+		// The share of an offline server is lost (the others can still tally without it)
+		if client.Servers[k] == nil {
+			fmt.Printf("[%s] \033[33mServer %v is offline, not sending its share.\033[0m\n", client.Id, k+1)
+			continue
+		}
+
+		// Send r1 to S1 (salting the commitment in its receipt)
+		salt := newSalt()
+		e := client.Servers[k].Send(protocol.RMessage{Vote: v, Seq: client.seq, Salt: salt}.ToRequest())
+		if e != nil {
+			fmt.Printf("[%s] Error when sending R%v: %e\n", client.Id, k, e)
+		}
+		client.expectReceipt(k, protocol.CommitShare(v, client.seq, salt))
+
+	}
+
+	// Misbehave after voting (if bad)
+	client.AfterVote(vote)
+
+}
+
+func AwaitResponse(id string, server protocol.Conn, ch chan protocol.Results, receipts func(protocol.Receipt)) {
+
+	// Read (logging the blame reports sent ahead of the tally, handing over the receipts of our ballots, and skipping
+	// the election key sent again on a rejoin)
+	res, e := server.Receive()
+	for e == nil && (res.RequestType == protocol.BLAME || res.RequestType == protocol.PUBLICKEY || res.RequestType == protocol.RECEIPT) {
+		if res.RequestType == protocol.BLAME {
+			fmt.Printf("[%s] \033[31mServer reports misbehaviour: %s.\033[0m\n", id, res.ToBlameMsg())
+		}
+		if res.RequestType == protocol.RECEIPT && receipts != nil {
+			receipts(res.ToReceiptMsg())
+		}
+		res, e = server.Receive()
+	}
+
+	// Our ballot was not taken, so the tally is not ours
+	if e == nil && res.RequestType == protocol.REJECT {
+		reject := res.ToRejectMsg()
+		fmt.Printf("[%s] \033[31mThe server rejected our ballot: %s.\033[0m\n", id, reject.Reason)
+		ch <- protocol.Results{Error: true, Code: reject.Code}
+		return
+	}
+	if e != nil {
+		// A lost connection means no tally, so the client never waits forever
+		ch <- protocol.Results{Error: true, Code: protocol.ERR_NO_TALLY}
+		return
+	}
+
+	// Make sure it's a tally
+	if res.RequestType != protocol.TALLY {
+		fmt.Printf("[%s] \033[31mFailed to get the tally, found %v request.\033[0m\n", id, res.RequestType)
+		ch <- protocol.Results{Error: true, Code: protocol.ERR_NO_TALLY}
+		return
+	}
+
+	// Write to channel
+	ch <- res.ToTallyMsg()
+
+}
+
+func (client *Client) Shutdown(waitForResults bool) {
+
+	// If wait - we wait for the online servers to return something (only the one we voted at if encrypted)
+	if waitForResults && scheme.Encrypts(client.Scheme) {
+		client.awaitDecryptedTally()
+	} else if waitForResults {
+
+		// Go wait (one channel per server, so we know who sent what)
+		countChans := make([]chan protocol.Results, len(client.Servers))
+		got := make([][]protocol.Receipt, len(client.Servers))
+		for k := range client.Servers {
+			if client.Servers[k] != nil {
+				k := k
+				countChans[k] = make(chan protocol.Results, 1)
+				go AwaitResponse(client.Id, client.Servers[k], countChans[k], func(r protocol.Receipt) { got[k] = append(got[k], r) })
+			}
+		}
+
+		// Wait for all to come in (offline servers have no tally)
+		counts := make([]protocol.Results, len(client.Servers))
+		for k := range counts {
+			if countChans[k] == nil {
+				fmt.Printf("[%s] \033[33mNo tally from server %v, it is offline.\033[0m\n", client.Id, k+1)
+				counts[k] = protocol.Results{Error: true, Code: protocol.ERR_NO_TALLY}
+				continue
+			}
+			counts[k] = <-countChans[k]
+			if counts[k].Error && counts[k].Code == protocol.ERR_NO_TALLY {
+				fmt.Printf("[%s] \033[33mNo tally from server %v, it went offline.\033[0m\n", client.Id, k+1)
+			}
+		}
+
+		// Verify the tally ourselves from the published points (correcting a lying server)
+		client.verifyCounts(counts)
+
+		// Check the servers tallied the ballots they gave us receipts of
+		client.checkReceipts(client.voterKey(), counts, got)
+
+	}
+
+	// Or hang up once we have our receipts (the results are fetched later)
+	if !waitForResults {
+		client.hangUp()
+	}
+
+	// Shutdown
+	for _, s := range client.Servers {
+		if s != nil {
+			s.Close()
+		}
+	}
+
+}
+
+// Verify the tally from the points published by the servers (ServerID k+1 sent counts[k]), leaving it in Verified
+// and the servers caught lying in Liars
+func (client *Client) verifyCounts(counts []protocol.Results) {
+
+	// Report if any server detected an error (and why)
+	for k, count := range counts {
+		if count.Error && count.Code != protocol.ERR_NO_TALLY && count.Code != protocol.ERR_TALLY_PENDING {
+			fmt.Printf("[%s] Server %v reported an error while computing tally: %v.\n", client.Id, k+1, count.Code)
+		}
+	}
+
+	// Verify the tally ourselves from the published points (correcting a lying server)
+	verified, liars, err := VerifyTally(client.Id, counts, client.Scheme, client.Strategy, client.P, client.K)
+	if err != nil {
+		fmt.Printf("[%s] \033[31mCould not verify the tally: %v\033[0m\n", client.Id, err)
+		fmt.Printf("[%s] Received results:\n", client.Id)
+		for k, count := range counts {
+			fmt.Printf("\tServer %v = %+v\n", k+1, count)
+		}
+		client.Code = protocol.ERR_TALLY_UNVERIFIED
+		for _, count := range counts {
+			if count.Error && count.Code != protocol.ERR_NO_TALLY {
+				client.Code = count.Code
+				break
+			}
+		}
+	} else {
+		client.Verified = &verified
+		client.Liars = liars
+		client.Code = verified.Code
+		fmt.Printf("[%s] Yes Votes: %v, No Votes: %v (Total %v, verified).\n", client.Id, verified.Yes, verified.No, verified.Yes+verified.No)
+		for _, liar := range liars {
+			fmt.Printf("[%s] \033[31mServer %v lied about the tally.\033[0m\n", client.Id, liar)
+		}
+	}
+
+}
+
+// Reconnect to the servers under our ID, showing them our recast keys (to recast our vote until the deadline)
+func (client *Client) Rejoin() error {
+	for _, s := range client.Servers {
+		if s != nil {
+			s.Close()
+		}
+	}
+	return client.Init(client.Id, client.serverIPs, client.serverPorts, client.P, client.K, client.Mode != CLIENT_MODE_HONEST)
+}
+
+// Verifies the tally from the R-sum points published by the servers (ServerID k+1 sent results[k], ERR_NO_TALLY if nothing).
+// The points are reconstructed with the tally strategy of the election (which may correct a lying server), and the
+// ServerIDs of lying servers are returned. The name is who verifies (for the log).
+func VerifyTally(name string, results []protocol.Results, sch scheme.Scheme, strategy tally.Strategy, p, k int) (protocol.Results, []int, error) {
+
+	// Count the claims on the point of every server
+	claims := map[int]map[int]int{}
+	reports := 0
+	for _, r := range results {
+		if len(r.Points) > 0 {
+			reports++
+		}
+		for _, pt := range r.Points {
+			if claims[pt.X] == nil {
+				claims[pt.X] = map[int]int{}
+			}
+			claims[pt.X][pt.Y]++
+		}
+	}
+	if reports == 0 {
+		return protocol.Results{}, nil, fmt.Errorf("no server published its points")
+	}
+
+	// Settle on the point of each server (a majority of the servers must agree on it)
+	lied := map[int]bool{}
+	xs := make([]int, 0)
+	for x := range claims {
+		xs = append(xs, x)
+	}
+	sort.Ints(xs)
+	points := make([]sharing.Point, 0)
+	for _, x := range xs {
+		y, count := MajorityOf(claims[x])
+		if 2*count <= reports || y < 0 || y >= p {
+			// The server sent different R-sums to its partners (or one outside the field)
+			lied[x] = true
+			continue
+		}
+		points = append(points, sharing.Point{X: x, Y: y})
+	}
+
+	// Need a point more than the polynomium degree to check the points against each other (all of them if fewer servers)
+	need := k + 2
+	if need > sch.Servers() {
+		need = sch.Servers()
+	}
+	if len(points) < need {
+		return protocol.Results{}, nil, fmt.Errorf("only %v usable point(s) were published", len(points))
+	}
+
+	// Reconstruct the tally the way the servers do (correcting a lying server if the strategy can)
+	sum, diag := strategy.Tally(points, tally.Params{Name: name, P: p, K: k, Servers: sch.Servers()})
+	for _, b := range sum.Blames {
+		lied[b.Point.X] = true
+	}
+	if sum.Code != protocol.ERR_NONE {
+		return protocol.Results{}, nil, protocol.Errorf(sum.Code, "the points %v do not give a tally (%v)", points, diag)
+	}
+	yes := sum.Yes
+
+	// Agree on the amount of voters (among the servers that sent a tally)
+	totals := map[int]int{}
+	answered := 0
+	for _, r := range results {
+		if !r.Error {
+			totals[r.Yes+r.No]++
+		}
+		if !r.Error || r.Code != protocol.ERR_NO_TALLY {
+			answered++
+		}
+	}
+	total, count := MajorityOf(totals)
+	if 2*count <= answered {
+		return protocol.Results{}, nil, fmt.Errorf("servers do not agree on the amount of voters")
+	}
+	verified := protocol.Results{Yes: yes, No: total - yes, Points: points}
+	if verified.No < 0 {
+		verified.Error = true
+		verified.Code = protocol.ERR_VOTE_OUT_OF_RANGE
+	}
+
+	// Blame servers reporting another tally than the verified
+	for k, r := range results {
+		if !r.Error && (r.Yes != verified.Yes || r.No != verified.No) {
+			lied[k+1] = true
+		}
+	}
+
+	// Return the verified tally and the liars (ordered)
+	liars := make([]int, 0)
+	for id := range lied {
+		liars = append(liars, id)
+	}
+	sort.Ints(liars)
+	return verified, liars, nil
+
+}
+
+// Find the value with the most counts (the smallest value wins a tie)
+func MajorityOf(counts map[int]int) (int, int) {
+	best, bestCount := 0, -1
+	for v, c := range counts {
+		if c > bestCount || (c == bestCount && v < best) {
+			best, bestCount = v, c
+		}
+	}
+	return best, bestCount
+}
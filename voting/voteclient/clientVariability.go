@@ -0,0 +1,181 @@
+package voteclient
+
+import (
+	"fmt"
+	"time"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/sharing"
+)
+
+// Code for malicious client behaviour
+const (
+	CLIENT_MODE_HONEST       = ""
+	CLIENT_MODE_OUT_OF_RANGE = "out-of-range" // Shares a vote outside {0, 1} (arg = the vote, default P/2)
+	CLIENT_MODE_INCONSISTENT = "inconsistent" // Shares not on one polynomium (arg = server with the bad share, default last)
+	CLIENT_MODE_PARTIAL      = "partial"      // Sends shares to only some servers (arg = amount of servers, default 1)
+	CLIENT_MODE_DOUBLE_VOTE  = "double-vote"  // Votes again under another ID (arg = amount of extra IDs, default 1)
+	CLIENT_MODE_LATE         = "late"         // Votes after the deadline (arg = delay in seconds, default 20)
+	CLIENT_MODE_FLOOD        = "flood"        // Floods servers with registrations (arg = amount, default 100)
+	CLIENT_MODE_RECAST       = "recast"       // Casts the other vote first, then rejoins and recasts (arg = ballots before, default 1)
+	CLIENT_MODE_PART_RECAST  = "part-recast"  // Recasts the other vote at only some servers (arg = amount of servers, default 1)
+	CLIENT_MODE_IMPOSTOR     = "impostor"     // Rejoins under its own ID without the recast keys, and recasts the other vote
+)
+
+// Lists the known bad client modes
+var ClientModeNames = []string{CLIENT_MODE_OUT_OF_RANGE, CLIENT_MODE_INCONSISTENT, CLIENT_MODE_PARTIAL, CLIENT_MODE_DOUBLE_VOTE, CLIENT_MODE_LATE, CLIENT_MODE_FLOOD, CLIENT_MODE_RECAST, CLIENT_MODE_PART_RECAST, CLIENT_MODE_IMPOSTOR}
+
+// Check if mode is a known client mode (or honest)
+func IsClientMode(mode string) bool {
+	if mode == CLIENT_MODE_HONEST {
+		return true
+	}
+	for _, m := range ClientModeNames {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the mode argument, or the default if none was given
+func (client *Client) modeArg(def int) int {
+	if client.ModeArg > 0 {
+		return client.ModeArg
+	}
+	return def
+}
+
+// Create the shares of the vote (corrupted if the mode says so)
+func (client *Client) MakeShares(vote int) []int {
+
+	// Share a vote outside {0, 1}
+	if client.Mode == CLIENT_MODE_OUT_OF_RANGE {
+		vote = client.modeArg(client.P / 2)
+		fmt.Printf("[%s] \033[31mSharing out-of-range vote %v.\033[0m\n", client.Id, vote)
+	}
+
+	// Get shares (with MACs every server gets the masked vote, and the servers hold the shares of the mask)
+	var shares []int
+	if client.Mask != nil {
+		shares = make([]int, client.Scheme.Servers())
+		for i := range shares {
+			shares[i] = client.Mask.Mask(vote)
+		}
+	} else {
+		shares = client.Scheme.Share(sharing.OrSecure(client.RNG), vote, client.P, client.K)
+	}
+
+	// Move one share off the polynomium (with MACs, send one server another masked vote)
+	if client.Mode == CLIENT_MODE_INCONSISTENT {
+		i := (client.modeArg(len(shares)) - 1) % len(shares)
+		shares[i] = sharing.Pmod(shares[i]+1+sharing.OrSecure(client.RNG).Intn(client.P-1), client.P)
+		fmt.Printf("[%s] \033[31mMoved share of S%v off the polynomium.\033[0m\n", client.Id, i+1)
+	}
+
+	return shares
+
+}
+
+// Check if the share of server k (0-indexed) should be sent
+func (client *Client) SendsShareTo(k int) bool {
+	partial := client.Mode == CLIENT_MODE_PARTIAL || (client.Mode == CLIENT_MODE_PART_RECAST && client.seq > 1)
+	if partial && k >= client.modeArg(1) {
+		fmt.Printf("[%s] \033[31mWithholding share from S%v.\033[0m\n", client.Id, k+1)
+		return false
+	}
+	return true
+}
+
+// Misbehave before sending the vote
+func (client *Client) BeforeVote() {
+	switch client.Mode {
+	case CLIENT_MODE_LATE:
+		delay := time.Duration(client.modeArg(20)) * time.Second
+		fmt.Printf("[%s] \033[31mWaiting %v before voting.\033[0m\n", client.Id, delay)
+		time.Sleep(delay)
+	case CLIENT_MODE_FLOOD:
+		client.FloodRegistrations(client.modeArg(100))
+	}
+}
+
+// Cast other ballots before the vote, rejoining the servers to recast it (the servers must count the last ballot)
+func (client *Client) CastBefore(vote int) {
+	if client.Mode != CLIENT_MODE_RECAST || client.recasting {
+		return
+	}
+	client.recasting = true
+	for i := 0; i < client.modeArg(1); i++ {
+		fmt.Printf("[%s] \033[33mCasting %v before recasting %v.\033[0m\n", client.Id, 1-vote, vote)
+		client.SendVote(1 - vote)
+	}
+	fmt.Printf("[%s] \033[33mRejoining the servers to recast.\033[0m\n", client.Id)
+	client.Rejoin()
+	client.recasting = false
+}
+
+// Misbehave after sending the vote
+func (client *Client) AfterVote(vote int) {
+
+	// Recast the other vote at only some servers (they must count the ballot every server holds)
+	if client.Mode == CLIENT_MODE_PART_RECAST && client.seq == 1 {
+		fmt.Printf("[%s] \033[31mRecasting %v at only %v server(s).\033[0m\n", client.Id, 1-vote, client.modeArg(1))
+		client.SendVote(1 - vote)
+		return
+	}
+
+	// Rejoin as ourselves without the recast keys (as anyone knowing our ID could), which the servers must refuse
+	if client.Mode == CLIENT_MODE_IMPOSTOR {
+		impostor := new(Client)
+		impostor.Transport = client.Transport
+		impostor.Scheme = client.Scheme
+		impostor.Strategy = client.Strategy
+		impostor.RNG = client.RNG
+		impostor.Credential = client.Credential
+		fmt.Printf("[%s] \033[31mRejoining without the recast keys to recast %v.\033[0m\n", client.Id, 1-vote)
+		if impostor.Init(client.Id, client.serverIPs, client.serverPorts, client.P, client.K, true) == nil {
+			impostor.SendVote(1 - vote)
+			impostor.Shutdown(false)
+		}
+		return
+	}
+
+	if client.Mode != CLIENT_MODE_DOUBLE_VOTE {
+		return
+	}
+	for i := 1; i <= client.modeArg(1); i++ {
+
+		// Vote again under a new ID
+		alias := new(Client)
+		alias.Transport = client.Transport
+		alias.Scheme = client.Scheme
+		alias.Strategy = client.Strategy
+		alias.RNG = client.RNG
+		aliasID := fmt.Sprintf("%s-%v", client.Id, i)
+		fmt.Printf("[%s] \033[31mVoting again as %s.\033[0m\n", client.Id, aliasID)
+		if alias.Init(aliasID, client.serverIPs, client.serverPorts, client.P, client.K, true) == nil {
+			alias.SendVote(vote)
+			alias.Shutdown(false)
+		}
+
+	}
+}
+
+// Register a lot of voters that never vote
+func (client *Client) FloodRegistrations(count int) {
+
+	// Log
+	fmt.Printf("[%s] \033[31mFlooding servers with %v registrations.\033[0m\n", client.Id, count)
+
+	// Register at all servers
+	for i := 0; i < count; i++ {
+		for k := range client.serverPorts {
+			join := protocol.ClientJoinMessage{ID: fmt.Sprintf("%s-flood%v", client.Id, i)}
+			conn, _, err := ConnectServer(client.Transport, join, client.serverIPs[k], client.serverPorts[k])
+			if err == nil {
+				conn.Close()
+			}
+		}
+	}
+
+}
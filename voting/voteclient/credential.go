@@ -0,0 +1,45 @@
+package voteclient
+
+import (
+	"errors"
+	"fmt"
+
+	"cs.au.dk/voting/credential"
+	"cs.au.dk/voting/protocol"
+)
+
+// Get our credential from the registration authority at the address (before joining the servers). The authority
+// signs a blinded serial, so it cannot tell which token it issued to us, and the servers never learn our ID.
+func (client *Client) Register(id, ip, port string, authority *credential.PublicKey, bad bool) bool {
+
+	// Ask the authority (as we would the servers)
+	client.Id = id
+	if client.Transport == nil {
+		client.Transport = protocol.DefaultTransport
+	}
+	token, err := credential.Obtain(client.Transport, ip, port, id, authority, nil)
+	if err == nil {
+		client.Credential = token
+		fmt.Printf("[%s] Got a credential, joining the servers as %s.\n", id, token.Key())
+		return true
+	}
+
+	// No credential, no vote
+	voteErr := protocol.Errorf(protocol.ERR_NOT_ELIGIBLE, "%v", err)
+	errors.As(err, &voteErr)
+	if !bad {
+		panic(voteErr)
+	}
+	client.Code = voteErr.Code
+	fmt.Printf("[%s] \033[31mGot no credential (%v).\033[0m\n", id, err)
+	return false
+
+}
+
+// The ID the servers know us by (the hash of our credential if we have one, else our ID)
+func (client *Client) voterKey() string {
+	if client.Credential != nil {
+		return client.Credential.Key()
+	}
+	return client.Id
+}
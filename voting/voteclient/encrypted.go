@@ -0,0 +1,152 @@
+package voteclient
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"cs.au.dk/voting/elgamal"
+	"cs.au.dk/voting/protocol"
+)
+
+// Connect to a single server when the ballots are encrypted (the servers decrypt the tally together, so any of them
+// can take our ballot). The servers are tried in turn from the Entry server, going on to the next if one is offline.
+func (client *Client) initEncrypted(bad bool) error {
+
+	// Try the servers from our entry point
+	n := len(client.serverIPs)
+	for i := 0; i < n; i++ {
+		k := (client.Entry + i) % n
+		conn, role, err := ConnectServer(client.Transport, client.joinMessage(client.serverIPs[k], client.serverPorts[k]), client.serverIPs[k], client.serverPorts[k])
+		if err != nil {
+			fmt.Printf("[%s] \033[33mServer at %s:%s is offline: %v\033[0m\n", client.Id, client.serverIPs[k], client.serverPorts[k], err)
+			continue
+		}
+		if role < 1 || role > n || role != k+1 {
+			fmt.Printf("[%s] \033[33mServer at %s:%s reported role %v, expected %v.\033[0m\n", client.Id, client.serverIPs[k], client.serverPorts[k], role, k+1)
+			conn.Close()
+			continue
+		}
+		client.Servers[k] = conn
+		fmt.Printf("[%s] Connected to server %v.\n", client.Id, role)
+		return nil
+	}
+
+	// No server took us
+	return client.failInit(client.Id, bad, protocol.Errorf(protocol.ERR_SERVER_UNREACHABLE, "none of the %v servers are online", n))
+
+}
+
+// The server we cast our encrypted ballot at, and its index (nil if not connected)
+func (client *Client) entryServer() (protocol.Conn, int) {
+	for k, s := range client.Servers {
+		if s != nil {
+			return s, k
+		}
+	}
+	return nil, -1
+}
+
+// Wait for the public key of the election, and send the vote encrypted under it
+func (client *Client) sendBallot(vote int) {
+
+	server, k := client.entryServer()
+	if server == nil {
+		return
+	}
+
+	// The key comes once the servers generated it (the tally instead if the key generation failed), we already have it
+	// if we are recasting
+	key := client.key
+	for key == nil {
+		req, e := server.Receive()
+		if e != nil {
+			fmt.Printf("[%s] \033[31mLost the server before getting the election key: %v\033[0m\n", client.Id, e)
+			client.early = &protocol.Results{Error: true, Code: protocol.ERR_NO_TALLY}
+			return
+		}
+		switch req.RequestType {
+		case protocol.BLAME:
+			fmt.Printf("[%s] \033[31mServer reports misbehaviour: %s.\033[0m\n", client.Id, req.ToBlameMsg())
+		case protocol.TALLY:
+			results := req.ToTallyMsg()
+			client.early = &results
+			fmt.Printf("[%s] \033[31mGot the tally before the election key, not voting.\033[0m\n", client.Id)
+			return
+		case protocol.PUBLICKEY:
+			h, err := elgamal.Decode(req.ToKeyMsg().Key)
+			if err != nil {
+				fmt.Printf("[%s] \033[31mIgnoring invalid election key: %v\033[0m\n", client.Id, err)
+				continue
+			}
+			key = h
+		}
+	}
+	client.key = key
+
+	// Encrypt a vote outside {0, 1} (if bad), which no proof can be made for
+	if client.Mode == CLIENT_MODE_OUT_OF_RANGE {
+		vote = client.modeArg(client.P / 2)
+		fmt.Printf("[%s] \033[31mEncrypting out-of-range vote %v.\033[0m\n", client.Id, vote)
+	}
+
+	// Encrypt (keeping the exponent to prove what we encrypted)
+	r, err := elgamal.RandomExponent(rand.Reader)
+	if err != nil {
+		fmt.Printf("[%s] \033[31mCould not encrypt the ballot: %v\033[0m\n", client.Id, err)
+		return
+	}
+	ballot := elgamal.EncryptWith(key, vote, r)
+	msg := protocol.BallotMessage{Ciphertext: ballot.Encode(), Seq: client.seq}
+
+	// Prove the ballot encrypts 0 or 1 (if asked to)
+	if client.Prove {
+		proof, err := elgamal.ProveBit(rand.Reader, elgamal.Context{Election: client.Election, Key: key}, ballot, vote, r, client.voterKey())
+		if err != nil {
+			fmt.Printf("[%s] \033[31mCould not prove the ballot: %v\033[0m\n", client.Id, err)
+			return
+		}
+		msg.Proof = proof.Encode()
+	}
+	fmt.Printf("[%s] My secret is %v, sent encrypted (proven: %v).\n", client.Id, vote, client.Prove)
+	if e := server.Send(msg.ToRequest()); e != nil {
+		fmt.Printf("[%s] Error when sending ballot: %e\n", client.Id, e)
+	}
+	client.expectReceipt(k, protocol.CommitBallot(msg.Ciphertext, client.seq))
+
+}
+
+// Wait for the tally of the server we voted at. No points are published, as the servers checked the decryption
+// against each other (in groups of K+1), so the tally is taken as it is.
+func (client *Client) awaitDecryptedTally() {
+
+	results := client.early
+	if results == nil {
+		server, k := client.entryServer()
+		if server == nil {
+			return
+		}
+		ch := make(chan protocol.Results, 1)
+		got := make([][]protocol.Receipt, len(client.Servers))
+		AwaitResponse(client.Id, server, ch, func(r protocol.Receipt) { got[k] = append(got[k], r) })
+		r := <-ch
+		results = &r
+
+		// Check the server tallied the ballot it gave us a receipt of (the other servers have none of ours)
+		counts := make([]protocol.Results, len(client.Servers))
+		for i := range counts {
+			counts[i] = protocol.Results{Error: true, Code: protocol.ERR_NO_TALLY}
+		}
+		counts[k] = r
+		client.checkReceipts(client.voterKey(), counts, got)
+	}
+	client.Code = results.Code
+	if results.Error {
+		if results.Code != protocol.ERR_BALLOT_REJECTED {
+			fmt.Printf("[%s] The server reported an error while computing tally: %v.\n", client.Id, results.Code)
+		}
+		return
+	}
+	client.Verified = results
+	fmt.Printf("[%s] Yes Votes: %v, No Votes: %v (Total %v, decrypted).\n", client.Id, results.Yes, results.No, results.Yes+results.No)
+
+}
@@ -0,0 +1,154 @@
+package voteclient
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"cs.au.dk/voting/protocol"
+)
+
+// A random salt for the commitment to a share
+func newSalt() string {
+	salt := make([]byte, 16)
+	if _, e := rand.Read(salt); e != nil {
+		panic(e)
+	}
+	return hex.EncodeToString(salt)
+}
+
+// Remember the commitment to the ballot we sent server k (0-indexed), to check its receipt against
+func (client *Client) expectReceipt(k int, commitment string) {
+	if client.sent[k] == nil {
+		client.sent[k] = map[int]string{}
+	}
+	client.sent[k][client.seq] = commitment
+}
+
+// Mark the server as having dropped our ballot
+func (client *Client) drop(serverID int) {
+	for _, id := range client.Dropped {
+		if id == serverID {
+			return
+		}
+	}
+	client.Dropped = append(client.Dropped, serverID)
+	sort.Ints(client.Dropped)
+}
+
+// Check the receipts the servers gave the voter (got, per server) against the ballots we sent, and the tallied sets
+// of the servers (counts). A server that sent a tally but no receipt (or one not matching our ballot) for a ballot we
+// sent it, and a server whose tallied set does not hold the receipt of the last ballot we sent every tallying server,
+// are added to Dropped. The receipts are kept in Receipts, as evidence against the servers.
+func (client *Client) checkReceipts(voter string, counts []protocol.Results, got [][]protocol.Receipt) {
+
+	// Keep the receipts of our ballots (by server and sequence number)
+	held := make([]map[int]protocol.Receipt, len(counts))
+	for k := range counts {
+		held[k] = map[int]protocol.Receipt{}
+		for _, r := range got[k] {
+			commitment, sent := client.sent[k][r.Seq]
+			if !sent || r.Commitment != commitment || r.Voter != voter || r.Election != client.Election || int(r.ServerID) != k+1 || !client.verifyReceipt(k, r) {
+				fmt.Printf("[%s] \033[31mServer %v sent a receipt that does not match ballot %v.\033[0m\n", client.Id, k+1, r.Seq)
+				continue
+			}
+			held[k][r.Seq] = r
+			client.Receipts = append(client.Receipts, r)
+		}
+	}
+
+	// Every server that answered must have given a receipt of every ballot we sent it (one that went offline may
+	// have lost it, and a rejected ballot was not taken)
+	for k, count := range counts {
+		if count.Code == protocol.ERR_NO_TALLY || count.Code == protocol.ERR_BALLOT_REJECTED {
+			continue
+		}
+		for seq := range client.sent[k] {
+			if _, exists := held[k][seq]; !exists {
+				fmt.Printf("[%s] \033[31mServer %v took ballot %v without a receipt.\033[0m\n", client.Id, k+1, seq)
+				client.drop(k + 1)
+			}
+		}
+	}
+
+	// The last ballot we sent every tallying server (or a later one) must be in the tallied set of every server that
+	// gave us receipts (the servers we sent nothing have none of our ballots)
+	tallying := make([]int, 0)
+	for k, count := range counts {
+		if !count.Error && len(client.sent[k]) > 0 {
+			tallying = append(tallying, k)
+		}
+	}
+	if len(tallying) == 0 {
+		return
+	}
+	last := 0
+	for seq := range client.sent[tallying[0]] {
+		common := true
+		for _, k := range tallying {
+			if _, exists := client.sent[k][seq]; !exists {
+				common = false
+			}
+		}
+		if common && seq > last {
+			last = seq
+		}
+	}
+	if last == 0 {
+		return
+	}
+	for _, k := range tallying {
+		if len(held[k]) == 0 {
+			continue
+		}
+		tallied := protocol.CheckmapFromStringSlice(counts[k].Receipts)
+		found := false
+		for seq, r := range held[k] {
+			if _, exists := tallied[r.Hash()]; exists && seq >= last {
+				found = true
+			}
+		}
+		if !found {
+			fmt.Printf("[%s] \033[31mServer %v did not tally ballot %v, though it gave us receipts.\033[0m\n", client.Id, k+1, last)
+			client.drop(k + 1)
+		}
+	}
+
+}
+
+// Check the receipt of server k (0-indexed) is signed with the key pinned for it. Without pinned keys the receipt
+// can only be checked against the key it names, which anyone can forge (so we warn about it).
+func (client *Client) verifyReceipt(k int, r protocol.Receipt) bool {
+	if client.ReceiptKeys == nil {
+		fmt.Printf("[%s] \033[33mNo receipt key is pinned for server %v, checking its receipt against the key it names.\033[0m\n", client.Id, k+1)
+		key, e := hex.DecodeString(r.Key)
+		return e == nil && r.Verify(ed25519.PublicKey(key))
+	}
+	return k < len(client.ReceiptKeys) && r.Verify(client.ReceiptKeys[k])
+}
+
+// Write the receipts the servers gave us to a file (to check them against the tallied sets later, see FetchResults)
+func (client *Client) SaveReceipts(path string) error {
+	data, e := json.MarshalIndent(client.Receipts, "", "    ")
+	if e != nil {
+		return e
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Load the receipts of a voter from a file
+func LoadReceipts(path string) ([]protocol.Receipt, error) {
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return nil, e
+	}
+	receipts := make([]protocol.Receipt, 0)
+	if e := json.Unmarshal(data, &receipts); e != nil {
+		return nil, fmt.Errorf("invalid receipt file '%s': %v", path, e)
+	}
+	return receipts, nil
+}
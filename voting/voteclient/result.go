@@ -0,0 +1,249 @@
+package voteclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"cs.au.dk/voting/protocol"
+	"cs.au.dk/voting/scheme"
+)
+
+// How long a voter hanging up after voting waits for the receipts of its ballots
+const RECEIPT_TIMEOUT = 5 * time.Second
+
+// How long a voter waits for the servers to answer a query for the results
+const RESULT_TIMEOUT = 10 * time.Second
+
+// Wait for the receipts of the ballots we sent (up to RECEIPT_TIMEOUT), so the servers can be held to them once
+// the results are fetched (see FetchResults). A server that did not give a receipt in time is added to Dropped.
+func (client *Client) hangUp() {
+
+	// Read the receipts of every server (a connected server that gives none in time took our ballot without one)
+	var mutex sync.Mutex
+	over := false
+	counts := make([]protocol.Results, len(client.Servers))
+	got := make([][]protocol.Receipt, len(client.Servers))
+	done := make(chan bool, len(client.Servers))
+	for k, s := range client.Servers {
+		counts[k] = protocol.Results{Error: true, Code: protocol.ERR_TALLY_PENDING}
+		if s == nil {
+			counts[k].Code = protocol.ERR_NO_TALLY
+			done <- true
+			continue
+		}
+		k, s := k, s
+		go func() {
+			defer func() { done <- true }()
+			for {
+				mutex.Lock()
+				if over || len(got[k]) >= len(client.sent[k]) {
+					mutex.Unlock()
+					return
+				}
+				mutex.Unlock()
+				req, e := s.Receive()
+				mutex.Lock()
+				if over {
+					mutex.Unlock()
+					return
+				}
+				if e != nil {
+					counts[k].Code = protocol.ERR_NO_TALLY
+				} else if req.RequestType == protocol.RECEIPT {
+					got[k] = append(got[k], req.ToReceiptMsg())
+				} else if req.RequestType == protocol.REJECT {
+					reject := req.ToRejectMsg()
+					fmt.Printf("[%s] \033[31mThe server rejected our ballot: %s.\033[0m\n", client.Id, reject.Reason)
+					counts[k].Code = reject.Code
+					client.Code = reject.Code
+				}
+				mutex.Unlock()
+				if e != nil || req.RequestType == protocol.REJECT {
+					return
+				}
+			}
+		}()
+	}
+
+	// Wait for all to come in (or give up)
+	timeout := time.After(RECEIPT_TIMEOUT)
+	for waiting, left := true, len(client.Servers); waiting && left > 0; {
+		select {
+		case <-done:
+			left--
+		case <-timeout:
+			waiting = false
+		}
+	}
+	mutex.Lock()
+	over = true
+	mutex.Unlock()
+
+	// Check them (no server tallied yet)
+	client.checkReceipts(client.voterKey(), counts, got)
+	fmt.Printf("[%s] Hanging up with %v receipt(s), the results are fetched later.\n", client.Id, len(client.Receipts))
+
+}
+
+// Ask every server for the results of the election (over new connections, long after we voted and hung up), verify
+// them against each other, and check the servers tallied the ballots of the receipts in Receipts (if any). The
+// verified tally is left in Verified, and an error is returned if the servers are misconfigured or a server has not
+// tallied yet (its code is also left in Code, ERR_TALLY_PENDING for the latter).
+func (client *Client) FetchResults() error {
+
+	// Grab the servers (one IP is used for all servers)
+	if client.Scheme == nil {
+		client.Scheme = scheme.Default()
+	}
+	if client.Strategy == nil {
+		client.Strategy, _ = scheme.StrategyOf(client.Scheme, "")
+	}
+	if client.Transport == nil {
+		client.Transport = protocol.DefaultTransport
+	}
+	if client.Election == "" {
+		client.Election = protocol.DEFAULT_ELECTION
+	}
+	servers, ports := client.serverIPs, client.serverPorts
+	if len(servers) == 1 {
+		for len(servers) < len(ports) {
+			servers = append(servers, servers[0])
+		}
+	}
+	if len(servers) != client.Scheme.Servers() || len(ports) != client.Scheme.Servers() {
+		client.Code = protocol.ERR_SERVER_CONFIG
+		fmt.Printf("[%s] \033[31mExpected %v servers but were given %v IPs and %v ports.\033[0m\n", client.Id, client.Scheme.Servers(), len(servers), len(ports))
+		return protocol.Errorf(protocol.ERR_SERVER_CONFIG, "expected %v servers but were given %v IPs and %v ports", client.Scheme.Servers(), len(servers), len(ports))
+	}
+
+	// Ask every server (one channel per server, so we know who sent what)
+	countChans := make([]chan protocol.Results, len(ports))
+	for k := range ports {
+		conn, err := client.Transport.Dial(servers[k], ports[k])
+		if err != nil {
+			fmt.Printf("[%s] \033[33mServer at %s:%s is offline: %v\033[0m\n", client.Id, servers[k], ports[k], err)
+			continue
+		}
+		defer conn.Close()
+		if e := conn.Send(protocol.Request{RequestType: protocol.RESULT}); e != nil {
+			fmt.Printf("[%s] \033[33mCould not ask server %v for the results: %v\033[0m\n", client.Id, k+1, e)
+			continue
+		}
+		countChans[k] = make(chan protocol.Results, 1)
+		go AwaitResponse(client.Id, conn, countChans[k], nil)
+	}
+
+	// Wait for all to come in (a server that does not answer in time has no tally)
+	counts := make([]protocol.Results, len(ports))
+	timeout := time.After(RESULT_TIMEOUT)
+	pending := 0
+	for k := range counts {
+		counts[k] = protocol.Results{Error: true, Code: protocol.ERR_NO_TALLY}
+		if countChans[k] == nil {
+			continue
+		}
+		select {
+		case counts[k] = <-countChans[k]:
+		case <-timeout:
+		}
+		if counts[k].Error && counts[k].Code == protocol.ERR_TALLY_PENDING {
+			fmt.Printf("[%s] \033[33mServer %v has not tallied yet.\033[0m\n", client.Id, k+1)
+			pending++
+		} else if counts[k].Error && counts[k].Code == protocol.ERR_NO_TALLY {
+			fmt.Printf("[%s] \033[33mNo results from server %v.\033[0m\n", client.Id, k+1)
+		}
+	}
+
+	// Every server that is online must have tallied, to cross-check them all
+	if pending > 0 {
+		fmt.Printf("[%s] \033[33m%v server(s) have not tallied yet, ask again later.\033[0m\n", client.Id, pending)
+		client.Code = protocol.ERR_TALLY_PENDING
+		return protocol.Errorf(protocol.ERR_TALLY_PENDING, "%v server(s) have not tallied yet", pending)
+	}
+
+	// Cross-check the servers (the encrypted tallies must agree, the shared ones are verified from their points)
+	if scheme.Encrypts(client.Scheme) {
+		client.agreeOnTally(counts)
+	} else {
+		client.verifyCounts(counts)
+	}
+
+	// Check the servers tallied the ballots of our receipts (a receipt the server did not sign holds it to nothing)
+	if len(client.Receipts) > 0 {
+		voter := client.Receipts[0].Voter
+		client.sent = make([]map[int]string, len(ports))
+		got := make([][]protocol.Receipt, len(ports))
+		for _, r := range client.Receipts {
+			k := int(r.ServerID) - 1
+			if k < 0 || k >= len(ports) || !client.verifyReceipt(k, r) {
+				fmt.Printf("[%s] \033[33mIgnoring receipt %v of server %v, which the server did not sign.\033[0m\n", client.Id, r.Seq, r.ServerID)
+				continue
+			}
+			if client.sent[k] == nil {
+				client.sent[k] = map[int]string{}
+			}
+			client.sent[k][r.Seq] = r.Commitment
+			got[k] = append(got[k], r)
+		}
+		client.Receipts = nil
+		client.checkReceipts(voter, counts, got)
+		fmt.Printf("[%s] Checked %v receipt(s), dropped by %v.\n", client.Id, len(client.Receipts), client.Dropped)
+	}
+	return nil
+
+}
+
+// Settle on the decrypted tally a majority of the servers that tallied agree on (the servers that sent another
+// are caught lying)
+func (client *Client) agreeOnTally(counts []protocol.Results) {
+
+	// Count the servers sending each tally
+	claims := map[[2]int]int{}
+	answered := 0
+	for _, count := range counts {
+		if !count.Error {
+			claims[[2]int{count.Yes, count.No}]++
+			answered++
+		}
+	}
+	var agreed [2]int
+	votes := 0
+	for tally, count := range claims {
+		if count > votes {
+			agreed, votes = tally, count
+		}
+	}
+
+	// Report if any server detected an error (and why)
+	client.Code = protocol.ERR_NO_TALLY
+	for k, count := range counts {
+		if count.Error && count.Code != protocol.ERR_NO_TALLY && count.Code != protocol.ERR_TALLY_PENDING {
+			fmt.Printf("[%s] Server %v reported an error while computing tally: %v.\n", client.Id, k+1, count.Code)
+			client.Code = count.Code
+		}
+	}
+	if answered == 0 {
+		return
+	}
+	if 2*votes <= answered {
+		fmt.Printf("[%s] \033[31m%v\033[0m\n", client.Id, protocol.Errorf(protocol.ERR_TALLY_DISAGREE, "the tallies %v of %v server(s) have no majority", claims, answered))
+		client.Code = protocol.ERR_TALLY_DISAGREE
+		return
+	}
+
+	// Take it
+	client.Verified = &protocol.Results{Yes: agreed[0], No: agreed[1]}
+	client.Liars = nil
+	client.Code = protocol.ERR_NONE
+	for k, count := range counts {
+		if !count.Error && (count.Yes != agreed[0] || count.No != agreed[1]) {
+			client.Liars = append(client.Liars, k+1)
+		}
+	}
+	fmt.Printf("[%s] Yes Votes: %v, No Votes: %v (Total %v, agreed by %v of %v server(s)).\n", client.Id, agreed[0], agreed[1], agreed[0]+agreed[1], votes, answered)
+	for _, liar := range client.Liars {
+		fmt.Printf("[%s] \033[31mServer %v lied about the tally.\033[0m\n", client.Id, liar)
+	}
+
+}